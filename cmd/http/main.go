@@ -1,13 +1,30 @@
 package main
 
 import (
+	"errors"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/ports"
 	"github.com/loukaspe/solar-panel-data-crud/internal/repositories"
+	"github.com/loukaspe/solar-panel-data-crud/internal/repositories/sqlite"
 	"github.com/loukaspe/solar-panel-data-crud/pkg/server"
 	log "github.com/sirupsen/logrus"
+	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	// embedded, so that the export timezones can be loaded on images without
+	// a timezone database
+	_ "time/tzdata"
+)
+
+const (
+	storageDriverMemory = "memory"
+	storageDriverFile   = "file"
+	storageDriverSqlite = "sqlite"
+
+	sqliteDatabaseFileName = "solar-panel-data.db"
 )
 
 func main() {
@@ -20,14 +37,54 @@ func main() {
 		}).Fatal("Error starting service")
 	}
 
+	repository, err := newSolarPanelDataRepository(logger)
+	if err != nil {
+		logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Fatal("Error starting service")
+	}
+
 	router := mux.NewRouter()
-	db := make(repositories.SolarPanelDataDB)
 	httpServer := &http.Server{
 		Addr:    os.Getenv("SERVER_ADDR"),
 		Handler: router,
 	}
 
-	server := server.NewServer(db, router, httpServer, logger)
+	server := server.NewServer(repository, router, httpServer, logger)
 
 	server.Run()
+
+	if closer, ok := repository.(io.Closer); ok {
+		err = closer.Close()
+		if err != nil {
+			logger.WithFields(log.Fields{
+				"errorMessage": err.Error(),
+			}).Error("Error closing solar panel data repository")
+		}
+	}
+}
+
+// newSolarPanelDataRepository builds the repository selected by STORAGE_DRIVER.
+// When it is not set, datasets are kept in memory only.
+func newSolarPanelDataRepository(logger *log.Logger) (ports.SolarPanelDataRepositoryInterface, error) {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", storageDriverMemory:
+		return repositories.NewSolarPanelDataRepository(make(repositories.SolarPanelDataDB)), nil
+	case storageDriverFile:
+		snapshotInterval, err := strconv.Atoi(os.Getenv("STORAGE_SNAPSHOT_INTERVAL"))
+		if err != nil {
+			return nil, errors.New("invalid STORAGE_SNAPSHOT_INTERVAL: " + err.Error())
+		}
+
+		return repositories.NewFileSolarPanelDataRepository(os.Getenv("STORAGE_DATA_DIR"), snapshotInterval, logger)
+	case storageDriverSqlite:
+		err := os.MkdirAll(os.Getenv("STORAGE_DATA_DIR"), 0o750)
+		if err != nil {
+			return nil, err
+		}
+
+		return sqlite.NewSolarPanelDataRepository(filepath.Join(os.Getenv("STORAGE_DATA_DIR"), sqliteDatabaseFileName))
+	default:
+		return nil, errors.New("unknown STORAGE_DRIVER " + driver)
+	}
 }
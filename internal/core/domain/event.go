@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// EventTimestampLayout is the basic ISO-8601 form event timestamps are
+// exchanged in, e.g. 20211231T221500Z.
+const EventTimestampLayout = "20060102T150405Z"
+
+// Event is a single reading of a parameter.
+type Event struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// ParseEvent parses an event from its [timestamp, value] form.
+func ParseEvent(pair []string) (Event, error) {
+	if len(pair) != 2 {
+		return Event{}, errors.New("event must be a [timestamp, value] pair")
+	}
+
+	timestamp, err := time.Parse(EventTimestampLayout, pair[0])
+	if err != nil {
+		return Event{}, errors.New("timestamp " + strconv.Quote(pair[0]) + " is not in the " + EventTimestampLayout + " format")
+	}
+
+	value, err := strconv.ParseFloat(pair[1], 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return Event{}, errors.New("value " + strconv.Quote(pair[1]) + " is not a number")
+	}
+
+	return Event{Timestamp: timestamp, Value: value}, nil
+}
+
+// MalformedEventTimestamp parses the timestamp of an event that could not be
+// parsed as a whole, returning nil when the timestamp is what is malformed.
+func MalformedEventTimestamp(timestamp string) *time.Time {
+	parsed, err := time.Parse(EventTimestampLayout, timestamp)
+	if err != nil {
+		return nil
+	}
+
+	return &parsed
+}
+
+// ParseEventSeries parses the series of a source from their [timestamp, value]
+// form, leaving out the events that cannot be parsed, which are returned in
+// parameter id order instead, as stored events are not rejected outright.
+func ParseEventSeries(
+	source EventSource,
+	pairsPerParameterId map[string][][]string,
+) (map[string][]Event, []MalformedEvent) {
+	if pairsPerParameterId == nil {
+		return nil, nil
+	}
+
+	parameterIds := make([]string, 0, len(pairsPerParameterId))
+	for parameterId := range pairsPerParameterId {
+		parameterIds = append(parameterIds, parameterId)
+	}
+
+	sort.Strings(parameterIds)
+
+	eventsPerParameterId := make(map[string][]Event, len(pairsPerParameterId))
+	var malformedEvents []MalformedEvent
+
+	for _, parameterId := range parameterIds {
+		events := make([]Event, 0, len(pairsPerParameterId[parameterId]))
+		for position, pair := range pairsPerParameterId[parameterId] {
+			event, err := ParseEvent(pair)
+			if err != nil {
+				malformedEvent := MalformedEvent{
+					Source:        source,
+					ParameterId:   parameterId,
+					Position:      position,
+					OriginalError: err,
+				}
+
+				if len(pair) > 0 {
+					malformedEvent.Timestamp = MalformedEventTimestamp(pair[0])
+				}
+
+				malformedEvents = append(malformedEvents, malformedEvent)
+
+				continue
+			}
+
+			events = append(events, event)
+		}
+
+		eventsPerParameterId[parameterId] = events
+	}
+
+	return eventsPerParameterId, malformedEvents
+}
+
+// FormatEventSeries returns the series in the [timestamp, value] form
+// ParseEventSeries accepts.
+func FormatEventSeries(eventsPerParameterId map[string][]Event) map[string][][]string {
+	if eventsPerParameterId == nil {
+		return nil
+	}
+
+	pairsPerParameterId := make(map[string][][]string, len(eventsPerParameterId))
+	for parameterId, events := range eventsPerParameterId {
+		pairs := make([][]string, 0, len(events))
+		for _, event := range events {
+			pairs = append(pairs, event.Pair())
+		}
+
+		pairsPerParameterId[parameterId] = pairs
+	}
+
+	return pairsPerParameterId
+}
+
+// FormatTimestamp returns the timestamp of the event in EventTimestampLayout.
+func (event Event) FormatTimestamp() string {
+	return event.Timestamp.UTC().Format(EventTimestampLayout)
+}
+
+// FormatValue returns the shortest decimal form of the event value.
+func (event Event) FormatValue() string {
+	return strconv.FormatFloat(event.Value, 'f', -1, 64)
+}
+
+// Pair returns the event in the [timestamp, value] form ParseEvent accepts.
+func (event Event) Pair() []string {
+	return []string{event.FormatTimestamp(), event.FormatValue()}
+}
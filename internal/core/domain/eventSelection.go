@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// EventSelection selects the events of a dataset that are exported or
+// analysed: the series of Source, restricted to the ones of Parameters and to
+// the events at or after From and before To, when set.
+type EventSelection struct {
+	Source     EventSource
+	From       *time.Time
+	To         *time.Time
+	Parameters []string
+}
+
+// SelectsMalformedEvent returns whether the malformed event would have been
+// selected, had it been parsed. One that is missing what it would be selected
+// by, a timestamp or, for a whole stored source that is malformed, a parameter
+// id, is selected as long as it may have been.
+func (selection *EventSelection) SelectsMalformedEvent(malformedEvent MalformedEvent) bool {
+	selectedSource := false
+	for _, source := range selection.Source.Sources() {
+		if malformedEvent.Source == source {
+			selectedSource = true
+		}
+	}
+
+	if !selectedSource {
+		return false
+	}
+
+	if len(selection.Parameters) > 0 && malformedEvent.ParameterId != "" {
+		selectedParameter := false
+		for _, parameterId := range selection.Parameters {
+			if malformedEvent.ParameterId == parameterId {
+				selectedParameter = true
+			}
+		}
+
+		if !selectedParameter {
+			return false
+		}
+	}
+
+	if malformedEvent.Timestamp != nil {
+		if selection.From != nil && malformedEvent.Timestamp.Before(*selection.From) {
+			return false
+		}
+
+		if selection.To != nil && !malformedEvent.Timestamp.Before(*selection.To) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestEventSelection_SelectsMalformedEvent(t *testing.T) {
+	from := time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC)
+	inside := time.Date(2022, 1, 1, 1, 30, 0, 0, time.UTC)
+	after := time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		selection      *EventSelection
+		malformedEvent MalformedEvent
+		expected       bool
+	}{
+		{
+			name:           "selected source",
+			selection:      &EventSelection{Source: EventSourceAll},
+			malformedEvent: MalformedEvent{Source: EventSourceWind, ParameterId: "turbine1"},
+			expected:       true,
+		},
+		{
+			name:           "other source",
+			selection:      &EventSelection{Source: EventSourceSolar},
+			malformedEvent: MalformedEvent{Source: EventSourceWind, ParameterId: "turbine1"},
+			expected:       false,
+		},
+		{
+			name:           "selected parameter",
+			selection:      &EventSelection{Source: EventSourceSolar, Parameters: []string{"uuid2", "uuid1"}},
+			malformedEvent: MalformedEvent{Source: EventSourceSolar, ParameterId: "uuid1"},
+			expected:       true,
+		},
+		{
+			name:           "other parameter",
+			selection:      &EventSelection{Source: EventSourceSolar, Parameters: []string{"uuid2"}},
+			malformedEvent: MalformedEvent{Source: EventSourceSolar, ParameterId: "uuid1"},
+			expected:       false,
+		},
+		{
+			name:           "source malformed as a whole",
+			selection:      &EventSelection{Source: EventSourceWind, Parameters: []string{"turbine1"}},
+			malformedEvent: MalformedEvent{Source: EventSourceWind},
+			expected:       true,
+		},
+		{
+			name:           "inside the time window",
+			selection:      &EventSelection{Source: EventSourceSolar, From: &from, To: &to},
+			malformedEvent: MalformedEvent{Source: EventSourceSolar, ParameterId: "uuid1", Timestamp: &inside},
+			expected:       true,
+		},
+		{
+			name:           "outside the time window",
+			selection:      &EventSelection{Source: EventSourceSolar, From: &from, To: &to},
+			malformedEvent: MalformedEvent{Source: EventSourceSolar, ParameterId: "uuid1", Timestamp: &after},
+			expected:       false,
+		},
+		{
+			name:           "malformed timestamp with a time window",
+			selection:      &EventSelection{Source: EventSourceSolar, From: &from, To: &to},
+			malformedEvent: MalformedEvent{Source: EventSourceSolar, ParameterId: "uuid1"},
+			expected:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.selection.SelectsMalformedEvent(tt.malformedEvent))
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		name                 string
+		pair                 []string
+		expected             Event
+		expectError          bool
+		expectedErrorMessage string
+	}{
+		{
+			name:     "valid",
+			pair:     []string{"20211231T221500Z", "81.9354839"},
+			expected: Event{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 81.9354839},
+		},
+		{
+			name:     "valid negative value",
+			pair:     []string{"20220101T060000Z", "-0.5"},
+			expected: Event{Timestamp: time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC), Value: -0.5},
+		},
+		{
+			name:                 "invalid missing value",
+			pair:                 []string{"20211231T221500Z"},
+			expectError:          true,
+			expectedErrorMessage: "event must be a [timestamp, value] pair",
+		},
+		{
+			name:                 "invalid extra element",
+			pair:                 []string{"20211231T221500Z", "0.0", "0.0"},
+			expectError:          true,
+			expectedErrorMessage: "event must be a [timestamp, value] pair",
+		},
+		{
+			name:                 "invalid extended timestamp",
+			pair:                 []string{"2021-12-31T22:15:00Z", "0.0"},
+			expectError:          true,
+			expectedErrorMessage: `timestamp "2021-12-31T22:15:00Z" is not in the 20060102T150405Z format`,
+		},
+		{
+			name:                 "invalid empty value",
+			pair:                 []string{"20211231T221500Z", ""},
+			expectError:          true,
+			expectedErrorMessage: `value "" is not a number`,
+		},
+		{
+			name:                 "invalid not a number value",
+			pair:                 []string{"20211231T221500Z", "NaN"},
+			expectError:          true,
+			expectedErrorMessage: `value "NaN" is not a number`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := ParseEvent(tt.pair)
+			if (err != nil) != tt.expectError {
+				t.Errorf("ParseEvent() error = %v, expectError %v", err, tt.expectError)
+				return
+			}
+
+			assert.Equal(t, tt.expected, actual)
+
+			if tt.expectError {
+				assert.Equal(t, tt.expectedErrorMessage, err.Error())
+				return
+			}
+
+			assert.Equal(t, tt.pair, actual.Pair())
+		})
+	}
+}
+
+func TestParseEventSeries(t *testing.T) {
+	timestamp := time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC)
+
+	actual, malformedEvents := ParseEventSeries(EventSourceSolar, map[string][][]string{
+		"uuid1": {
+			{"20211231T221500Z", "1.5"},
+			{"20211231T221500Z", "x"},
+			{"yesterday", "1"},
+		},
+	})
+
+	assert.Equal(t, map[string][]Event{
+		"uuid1": {
+			{Timestamp: timestamp, Value: 1.5},
+		},
+	}, actual)
+	assert.Equal(t, []MalformedEvent{
+		{
+			Source:        EventSourceSolar,
+			ParameterId:   "uuid1",
+			Position:      1,
+			Timestamp:     &timestamp,
+			OriginalError: errors.New(`value "x" is not a number`),
+		},
+		{
+			Source:        EventSourceSolar,
+			ParameterId:   "uuid1",
+			Position:      2,
+			OriginalError: errors.New(`timestamp "yesterday" is not in the 20060102T150405Z format`),
+		},
+	}, malformedEvents)
+}
@@ -1,6 +1,65 @@
 package domain
 
+import "time"
+
+// SolarPanelData is a dataset of solar panel events, keyed by parameter id, and
+// optionally of wind turbine events, keyed by turbine id. Name, Site and Tags
+// are supplied by the client, while CreatedAt, UpdatedAt and Version are
+// managed by the repositories.
 type SolarPanelData struct {
-	Solar map[string][][]string
-	Wind  interface{}
+	Solar     map[string][]Event
+	Wind      map[string][]Event
+	Name      string
+	Site      string
+	Tags      []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int
+	// MalformedEvents are the stored events that could not be parsed back and
+	// were left out of Solar and Wind, the solar ones first.
+	MalformedEvents []MalformedEvent
+}
+
+// MalformedEvent is a stored event that could not be parsed back, which can
+// only happen for events stored before they were validated on submission.
+type MalformedEvent struct {
+	Source      EventSource
+	ParameterId string
+	// Position is the index of the event within its series, as it was
+	// submitted.
+	Position int
+	// Timestamp is when the event was recorded, when that part of it could
+	// still be parsed.
+	Timestamp     *time.Time
+	OriginalError error
+}
+
+// EventSource selects which event series of a dataset are used.
+type EventSource string
+
+const (
+	EventSourceSolar EventSource = "solar"
+	EventSourceWind  EventSource = "wind"
+	EventSourceAll   EventSource = "all"
+)
+
+// Sources returns the single sources the given source stands for, solar first.
+func (source EventSource) Sources() []EventSource {
+	if source == EventSourceAll {
+		return []EventSource{EventSourceSolar, EventSourceWind}
+	}
+
+	return []EventSource{source}
+}
+
+// EventsOf returns the event series of a single source.
+func (solarPanelData *SolarPanelData) EventsOf(source EventSource) map[string][]Event {
+	switch source {
+	case EventSourceSolar:
+		return solarPanelData.Solar
+	case EventSourceWind:
+		return solarPanelData.Wind
+	default:
+		return nil
+	}
 }
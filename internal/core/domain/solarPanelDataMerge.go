@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// MergeConflictPolicy decides what becomes of a merged event whose timestamp
+// its series already has an event at with another value.
+type MergeConflictPolicy string
+
+const (
+	// MergeConflictReject rejects the whole merge, so that nothing stored is
+	// changed by mistake.
+	MergeConflictReject MergeConflictPolicy = "reject"
+	// MergeConflictKeep keeps the stored event and leaves out the merged one.
+	MergeConflictKeep MergeConflictPolicy = "keep"
+	// MergeConflictOverwrite replaces the value of the stored event with the
+	// merged one.
+	MergeConflictOverwrite MergeConflictPolicy = "overwrite"
+)
+
+// MergeConflict is a merged event whose timestamp its series already has an
+// event at with another value.
+type MergeConflict struct {
+	Source      EventSource
+	ParameterId string
+	// Index is the position of the event within its merged series, as it was
+	// submitted.
+	Index       int
+	Timestamp   time.Time
+	StoredValue float64
+	MergedValue float64
+}
+
+// MergeResult counts what a merge did to the events of a dataset.
+type MergeResult struct {
+	AppendedEvents    int
+	OverwrittenEvents int
+	// UnchangedEvents are the merged events the dataset already had, with the
+	// same value, as when a logger uploads the same readings twice.
+	UnchangedEvents int
+	// KeptEvents are the stored events kept over a conflicting merged one.
+	KeptEvents      int
+	AddedParameters int
+	Conflicts       []MergeConflict
+}
+
+// Merge returns the dataset with the events of the merged one added to it,
+// leaving both as they are. Events at timestamps a series has no event at are
+// appended to it, in the order they were submitted in, and series the dataset
+// does not have are added whole. Conflicts are resolved by the policy, and
+// listed in the result whatever it is, so that the caller can reject the merge.
+// Name, Site and Tags are replaced by the merged ones, when set.
+func (solarPanelData *SolarPanelData) Merge(
+	merged *SolarPanelData,
+	policy MergeConflictPolicy,
+) (*SolarPanelData, MergeResult) {
+	result := MergeResult{}
+
+	mergedSolarPanelData := *solarPanelData
+	mergedSolarPanelData.Solar = mergeSeries(
+		EventSourceSolar, solarPanelData.Solar, merged.Solar, policy, &result,
+	)
+	mergedSolarPanelData.Wind = mergeSeries(
+		EventSourceWind, solarPanelData.Wind, merged.Wind, policy, &result,
+	)
+
+	if merged.Name != "" {
+		mergedSolarPanelData.Name = merged.Name
+	}
+
+	if merged.Site != "" {
+		mergedSolarPanelData.Site = merged.Site
+	}
+
+	if merged.Tags != nil {
+		mergedSolarPanelData.Tags = merged.Tags
+	}
+
+	return &mergedSolarPanelData, result
+}
+
+// mergeSeries merges the series of a single source into copies of the stored
+// ones, as the stored ones may be shared with the repository.
+func mergeSeries(
+	source EventSource,
+	stored map[string][]Event,
+	merged map[string][]Event,
+	policy MergeConflictPolicy,
+	result *MergeResult,
+) map[string][]Event {
+	if merged == nil {
+		return stored
+	}
+
+	series := make(map[string][]Event, len(stored)+len(merged))
+	for parameterId, events := range stored {
+		series[parameterId] = append([]Event{}, events...)
+	}
+
+	parameterIds := make([]string, 0, len(merged))
+	for parameterId := range merged {
+		parameterIds = append(parameterIds, parameterId)
+	}
+
+	// sorted so that conflicts are always listed in the same order
+	sort.Strings(parameterIds)
+
+	for _, parameterId := range parameterIds {
+		events, exists := series[parameterId]
+		if !exists {
+			series[parameterId] = append([]Event{}, merged[parameterId]...)
+			result.AddedParameters++
+			result.AppendedEvents += len(merged[parameterId])
+
+			continue
+		}
+
+		// older datasets may have more than one event at a timestamp, which
+		// are all compared and overwritten alike
+		positions := map[time.Time][]int{}
+		for position, event := range events {
+			positions[event.Timestamp] = append(positions[event.Timestamp], position)
+		}
+
+		for index, event := range merged[parameterId] {
+			storedPositions, exists := positions[event.Timestamp]
+			if !exists {
+				events = append(events, event)
+				result.AppendedEvents++
+
+				continue
+			}
+
+			conflictingPosition := -1
+			for _, position := range storedPositions {
+				if events[position].Value != event.Value {
+					conflictingPosition = position
+
+					break
+				}
+			}
+
+			if conflictingPosition == -1 {
+				result.UnchangedEvents++
+
+				continue
+			}
+
+			result.Conflicts = append(result.Conflicts, MergeConflict{
+				Source:      source,
+				ParameterId: parameterId,
+				Index:       index,
+				Timestamp:   event.Timestamp,
+				StoredValue: events[conflictingPosition].Value,
+				MergedValue: event.Value,
+			})
+
+			if policy != MergeConflictOverwrite {
+				result.KeptEvents++
+
+				continue
+			}
+
+			for _, position := range storedPositions {
+				events[position].Value = event.Value
+			}
+
+			result.OverwrittenEvents++
+		}
+
+		series[parameterId] = events
+	}
+
+	return series
+}
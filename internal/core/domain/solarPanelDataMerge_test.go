@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSolarPanelData_Merge(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2022, 1, 1, hour, 0, 0, 0, time.UTC)
+	}
+
+	stored := func() *SolarPanelData {
+		return &SolarPanelData{
+			Solar: map[string][]Event{
+				"panel1": {
+					{Timestamp: at(1), Value: 1},
+					{Timestamp: at(2), Value: 2},
+				},
+			},
+			Name:    "roof",
+			Site:    "athens",
+			Tags:    []string{"east"},
+			Version: 3,
+		}
+	}
+
+	tests := []struct {
+		name           string
+		merged         *SolarPanelData
+		policy         MergeConflictPolicy
+		expected       *SolarPanelData
+		expectedResult MergeResult
+	}{
+		{
+			name: "appends new timestamps in submitted order and adds new parameters",
+			merged: &SolarPanelData{
+				Solar: map[string][]Event{
+					"panel1": {
+						{Timestamp: at(4), Value: 4},
+						{Timestamp: at(3), Value: 3},
+					},
+				},
+				Wind: map[string][]Event{
+					"turbine1": {
+						{Timestamp: at(1), Value: 10},
+					},
+				},
+			},
+			policy: MergeConflictReject,
+			expected: &SolarPanelData{
+				Solar: map[string][]Event{
+					"panel1": {
+						{Timestamp: at(1), Value: 1},
+						{Timestamp: at(2), Value: 2},
+						{Timestamp: at(4), Value: 4},
+						{Timestamp: at(3), Value: 3},
+					},
+				},
+				Wind: map[string][]Event{
+					"turbine1": {
+						{Timestamp: at(1), Value: 10},
+					},
+				},
+				Name:    "roof",
+				Site:    "athens",
+				Tags:    []string{"east"},
+				Version: 3,
+			},
+			expectedResult: MergeResult{AppendedEvents: 3, AddedParameters: 1},
+		},
+		{
+			name: "same values are unchanged",
+			merged: &SolarPanelData{
+				Solar: map[string][]Event{
+					"panel1": {
+						{Timestamp: at(2), Value: 2},
+					},
+				},
+			},
+			policy:         MergeConflictReject,
+			expected:       stored(),
+			expectedResult: MergeResult{UnchangedEvents: 1},
+		},
+		{
+			name: "conflict keeps the stored event",
+			merged: &SolarPanelData{
+				Solar: map[string][]Event{
+					"panel1": {
+						{Timestamp: at(3), Value: 3},
+						{Timestamp: at(2), Value: 20},
+					},
+				},
+			},
+			policy: MergeConflictKeep,
+			expected: &SolarPanelData{
+				Solar: map[string][]Event{
+					"panel1": {
+						{Timestamp: at(1), Value: 1},
+						{Timestamp: at(2), Value: 2},
+						{Timestamp: at(3), Value: 3},
+					},
+				},
+				Name:    "roof",
+				Site:    "athens",
+				Tags:    []string{"east"},
+				Version: 3,
+			},
+			expectedResult: MergeResult{
+				AppendedEvents: 1,
+				KeptEvents:     1,
+				Conflicts: []MergeConflict{
+					{
+						Source:      EventSourceSolar,
+						ParameterId: "panel1",
+						Index:       1,
+						Timestamp:   at(2),
+						StoredValue: 2,
+						MergedValue: 20,
+					},
+				},
+			},
+		},
+		{
+			name: "conflict overwrites the stored event",
+			merged: &SolarPanelData{
+				Solar: map[string][]Event{
+					"panel1": {
+						{Timestamp: at(1), Value: 10},
+					},
+				},
+			},
+			policy: MergeConflictOverwrite,
+			expected: &SolarPanelData{
+				Solar: map[string][]Event{
+					"panel1": {
+						{Timestamp: at(1), Value: 10},
+						{Timestamp: at(2), Value: 2},
+					},
+				},
+				Name:    "roof",
+				Site:    "athens",
+				Tags:    []string{"east"},
+				Version: 3,
+			},
+			expectedResult: MergeResult{
+				OverwrittenEvents: 1,
+				Conflicts: []MergeConflict{
+					{
+						Source:      EventSourceSolar,
+						ParameterId: "panel1",
+						Timestamp:   at(1),
+						StoredValue: 1,
+						MergedValue: 10,
+					},
+				},
+			},
+		},
+		{
+			name: "replaces name, site and tags when set",
+			merged: &SolarPanelData{
+				Name: "garage",
+				Tags: []string{},
+			},
+			policy: MergeConflictReject,
+			expected: &SolarPanelData{
+				Solar: map[string][]Event{
+					"panel1": {
+						{Timestamp: at(1), Value: 1},
+						{Timestamp: at(2), Value: 2},
+					},
+				},
+				Name:    "garage",
+				Site:    "athens",
+				Tags:    []string{},
+				Version: 3,
+			},
+			expectedResult: MergeResult{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solarPanelData := stored()
+
+			actual, actualResult := solarPanelData.Merge(tt.merged, tt.policy)
+
+			assert.Equal(t, tt.expected, actual)
+			assert.Equal(t, tt.expectedResult, actualResult)
+			assert.Equal(t, stored(), solarPanelData)
+		})
+	}
+}
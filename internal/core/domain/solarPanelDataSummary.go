@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// SolarPanelDataSummary describes a stored dataset without its events.
+type SolarPanelDataSummary struct {
+	Id             string
+	Name           string
+	Site           string
+	Tags           []string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Version        int
+	ParameterCount int
+	EventCount     int
+}
+
+// SolarPanelDataListCursor is the position of a dataset in the listing order.
+type SolarPanelDataListCursor struct {
+	CreatedAt time.Time
+	Id        string
+}
+
+// SolarPanelDataListOptions selects a page of datasets ordered by creation
+// time, with the id breaking ties. When After is set, only datasets ordered
+// after that position are returned. Empty filters match every dataset, and a
+// dataset matches Tags only if it carries all of them.
+type SolarPanelDataListOptions struct {
+	After      *SolarPanelDataListCursor
+	Limit      int
+	Descending bool
+	Name       string
+	Site       string
+	Tags       []string
+}
+
+type SolarPanelDataPage struct {
+	Items   []SolarPanelDataSummary
+	HasMore bool
+}
@@ -2,9 +2,20 @@ package ports
 
 import "github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
 
+// SolarPanelDataRepositoryInterface is implemented by the dataset storages.
+// CreateSolarPanelData, UpdateSolarPanelData and UpdateSolarPanelDataIfVersion
+// set the server managed CreatedAt, UpdatedAt and Version fields of the given
+// dataset to the stored values. UpdateSolarPanelDataIfVersion only updates a
+// dataset still at the given version, returning an
+// apierrors.VersionConflictError otherwise, so that a dataset read, changed and
+// written back does not lose the changes of another request in between.
+// Versions start at 1, so an expected version of 0 updates the dataset whatever
+// its version, which is what UpdateSolarPanelData does.
 type SolarPanelDataRepositoryInterface interface {
 	GetSolarPanelData(uuid string) (*domain.SolarPanelData, error)
+	ListSolarPanelData(*domain.SolarPanelDataListOptions) (*domain.SolarPanelDataPage, error)
 	CreateSolarPanelData(*domain.SolarPanelData) (string, error)
 	UpdateSolarPanelData(string, *domain.SolarPanelData) error
+	UpdateSolarPanelDataIfVersion(string, *domain.SolarPanelData, int) error
 	DeleteSolarPanelData(string) error
 }
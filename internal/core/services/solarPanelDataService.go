@@ -3,19 +3,28 @@ package services
 import (
 	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
 	"github.com/loukaspe/solar-panel-data-crud/internal/core/ports"
-	"github.com/loukaspe/solar-panel-data-crud/internal/repositories"
 	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 type SolarPanelDataServiceInterface interface {
-	GetSolarPanelData(string) (*domain.SolarPanelData, error)
+	GetSolarPanelData(string, *domain.EventSelection) (*domain.SolarPanelData, error)
+	GetSolarPanelDataSkippingMalformedEvents(string, *domain.EventSelection) (*domain.SolarPanelData, error)
+	ListSolarPanelData(*domain.SolarPanelDataListOptions) (*domain.SolarPanelDataPage, error)
 	CreateSolarPanelData(*domain.SolarPanelData) (string, error)
 	UpdateSolarPanelData(string, *domain.SolarPanelData) error
+	MergeSolarPanelData(string, *domain.SolarPanelData, domain.MergeConflictPolicy) (*domain.MergeResult, error)
 	DeleteSolarPanelData(string) error
 }
 
-func NewSolarPanelDataService(repository *repositories.SolarPanelDataRepository) *SolarPanelDataService {
+// mergeAttempts is how many times a merge is read and written again when the
+// dataset is changed by another request in between, as loggers of the same site
+// upload at the same time.
+const mergeAttempts = 3
+
+func NewSolarPanelDataService(repository ports.SolarPanelDataRepositoryInterface) *SolarPanelDataService {
 	return &SolarPanelDataService{repository: repository}
 }
 
@@ -23,8 +32,73 @@ type SolarPanelDataService struct {
 	repository ports.SolarPanelDataRepositoryInterface
 }
 
-func (service SolarPanelDataService) GetSolarPanelData(uuid string) (*domain.SolarPanelData, error) {
-	return service.repository.GetSolarPanelData(uuid)
+// GetSolarPanelData returns a dataset only if every stored event of it the
+// selection asks for could be parsed back, as a dataset missing events cannot
+// be guaranteed to be valid. Malformed events outside of the selection are not
+// used, so they do not fail it.
+func (service SolarPanelDataService) GetSolarPanelData(
+	uuid string,
+	selection *domain.EventSelection,
+) (*domain.SolarPanelData, error) {
+	solarPanelData, err := service.GetSolarPanelDataSkippingMalformedEvents(uuid, selection)
+	if err != nil {
+		return solarPanelData, err
+	}
+
+	if len(solarPanelData.MalformedEvents) > 0 {
+		malformedEvent := firstMalformedEvent(solarPanelData.MalformedEvents)
+
+		return &domain.SolarPanelData{}, apierrors.MalformedEventDataError{
+			ReturnedStatusCode:   http.StatusInternalServerError,
+			MalformedSource:      string(malformedEvent.Source),
+			MalformedParameterId: malformedEvent.ParameterId,
+			OriginalError:        malformedEvent.OriginalError,
+		}
+	}
+
+	return solarPanelData, nil
+}
+
+// firstMalformedEvent returns the malformed event to report, preferring a solar
+// one, as a dataset is about its solar events.
+func firstMalformedEvent(malformedEvents []domain.MalformedEvent) domain.MalformedEvent {
+	for _, malformedEvent := range malformedEvents {
+		if malformedEvent.Source == domain.EventSourceSolar {
+			return malformedEvent
+		}
+	}
+
+	return malformedEvents[0]
+}
+
+// GetSolarPanelDataSkippingMalformedEvents returns a dataset without the stored
+// events that could not be parsed back. The ones the selection asks for are
+// listed in its MalformedEvents for the caller to report.
+func (service SolarPanelDataService) GetSolarPanelDataSkippingMalformedEvents(
+	uuid string,
+	selection *domain.EventSelection,
+) (*domain.SolarPanelData, error) {
+	solarPanelData, err := service.repository.GetSolarPanelData(uuid)
+	if err != nil {
+		return solarPanelData, err
+	}
+
+	var selectedMalformedEvents []domain.MalformedEvent
+	for _, malformedEvent := range solarPanelData.MalformedEvents {
+		if selection.SelectsMalformedEvent(malformedEvent) {
+			selectedMalformedEvents = append(selectedMalformedEvents, malformedEvent)
+		}
+	}
+
+	solarPanelData.MalformedEvents = selectedMalformedEvents
+
+	return solarPanelData, nil
+}
+
+func (service SolarPanelDataService) ListSolarPanelData(
+	options *domain.SolarPanelDataListOptions,
+) (*domain.SolarPanelDataPage, error) {
+	return service.repository.ListSolarPanelData(options)
 }
 
 func (service SolarPanelDataService) CreateSolarPanelData(solarPanelData *domain.SolarPanelData) (string, error) {
@@ -34,6 +108,8 @@ func (service SolarPanelDataService) CreateSolarPanelData(solarPanelData *domain
 		}
 	}
 
+	solarPanelData.Tags = normalizeTags(solarPanelData.Tags)
+
 	return service.repository.CreateSolarPanelData(solarPanelData)
 }
 
@@ -44,9 +120,117 @@ func (service SolarPanelDataService) UpdateSolarPanelData(uuid string, solarPane
 		}
 	}
 
+	solarPanelData.Tags = normalizeTags(solarPanelData.Tags)
+
 	return service.repository.UpdateSolarPanelData(uuid, solarPanelData)
 }
 
+// MergeSolarPanelData merges the events of the given dataset into the stored
+// one, resolving conflicts with stored events by the policy, and sets the
+// server managed fields of the given dataset to the stored values. A dataset
+// with malformed events is not merged into, as writing it back would drop them.
+func (service SolarPanelDataService) MergeSolarPanelData(
+	uuid string,
+	solarPanelData *domain.SolarPanelData,
+	policy domain.MergeConflictPolicy,
+) (*domain.MergeResult, error) {
+	var err error
+
+	for attempt := 0; attempt < mergeAttempts; attempt++ {
+		var storedSolarPanelData *domain.SolarPanelData
+
+		// every event is written back, so every one of them must be readable
+		storedSolarPanelData, err = service.GetSolarPanelData(
+			uuid,
+			&domain.EventSelection{Source: domain.EventSourceAll},
+		)
+
+		// reads report a missing dataset as no content, but there is nothing
+		// to merge into, as with updating it
+		if dataNotFoundErrorWrapper, ok := err.(*apierrors.DataNotFoundErrorWrapper); ok {
+			return nil, &apierrors.DataNotFoundErrorWrapper{
+				ReturnedStatusCode: http.StatusNotFound,
+				OriginalError:      dataNotFoundErrorWrapper.OriginalError,
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		mergedSolarPanelData, result := storedSolarPanelData.Merge(solarPanelData, policy)
+
+		if policy == domain.MergeConflictReject && len(result.Conflicts) > 0 {
+			return nil, apierrors.MergeConflictError{
+				ReturnedStatusCode: http.StatusConflict,
+				Violations:         mergeConflictViolations(result.Conflicts),
+			}
+		}
+
+		mergedSolarPanelData.Tags = normalizeTags(mergedSolarPanelData.Tags)
+
+		err = service.repository.UpdateSolarPanelDataIfVersion(
+			uuid,
+			mergedSolarPanelData,
+			storedSolarPanelData.Version,
+		)
+		if _, ok := err.(apierrors.VersionConflictError); ok {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		solarPanelData.CreatedAt = mergedSolarPanelData.CreatedAt
+		solarPanelData.UpdatedAt = mergedSolarPanelData.UpdatedAt
+		solarPanelData.Version = mergedSolarPanelData.Version
+
+		return &result, nil
+	}
+
+	return nil, err
+}
+
 func (service SolarPanelDataService) DeleteSolarPanelData(uuid string) error {
 	return service.repository.DeleteSolarPanelData(uuid)
 }
+
+// normalizeTags trims the given tags and drops empty and repeated ones, keeping
+// the order in which they were first given.
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	normalizedTags := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		normalizedTags = append(normalizedTags, tag)
+	}
+
+	return normalizedTags
+}
+
+// mergeConflictViolations locates each conflicting event by its path in the
+// submitted document.
+func mergeConflictViolations(conflicts []domain.MergeConflict) []apierrors.Violation {
+	violations := make([]apierrors.Violation, 0, len(conflicts))
+
+	for _, conflict := range conflicts {
+		storedEvent := domain.Event{Value: conflict.StoredValue}
+
+		violations = append(violations, apierrors.Violation{
+			Path: "$." + string(conflict.Source) + "[" + strconv.Quote(conflict.ParameterId) + "][" +
+				strconv.Itoa(conflict.Index) + "][1]",
+			Message: "conflicts with the stored value " + storedEvent.FormatValue(),
+		})
+	}
+
+	return violations
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestSolarPanelDataService_CreateSolarPanelData(t *testing.T) {
@@ -27,6 +28,7 @@ func TestSolarPanelDataService_CreateSolarPanelData(t *testing.T) {
 		shouldMockRepositoryRun   bool
 		mockRepositoryReturnError error
 		expectedUuid              string
+		expectedTags              []string
 		expectedErrorMessage      string
 		expectError               bool
 	}{
@@ -34,9 +36,9 @@ func TestSolarPanelDataService_CreateSolarPanelData(t *testing.T) {
 			name: "creation ok",
 			args: args{
 				solarPanelData: &domain.SolarPanelData{
-					Solar: map[string][][]string{
-						"uuid1": [][]string{
-							{"timestamp1", "event1"},
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 						},
 					},
 					Wind: nil,
@@ -48,6 +50,26 @@ func TestSolarPanelDataService_CreateSolarPanelData(t *testing.T) {
 			expectedErrorMessage:      "",
 			expectError:               false,
 		},
+		{
+			name: "creation ok with repeated tags",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+						},
+					},
+					Wind: nil,
+					Tags: []string{"south", " south ", "", "inverter-a"},
+				},
+			},
+			expectedUuid:              "newUuid",
+			expectedTags:              []string{"south", "inverter-a"},
+			shouldMockRepositoryRun:   true,
+			mockRepositoryReturnError: nil,
+			expectedErrorMessage:      "",
+			expectError:               false,
+		},
 		{
 			name: "empty solar data",
 			args: args{
@@ -65,9 +87,9 @@ func TestSolarPanelDataService_CreateSolarPanelData(t *testing.T) {
 			name: "repo returns error",
 			args: args{
 				solarPanelData: &domain.SolarPanelData{
-					Solar: map[string][][]string{
-						"uuid1": [][]string{
-							{"timestamp1", "event1"},
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 						},
 					},
 					Wind: nil,
@@ -98,6 +120,7 @@ func TestSolarPanelDataService_CreateSolarPanelData(t *testing.T) {
 			}
 
 			assert.Equal(t, insertedUuid, tt.expectedUuid)
+			assert.Equal(t, tt.expectedTags, tt.args.solarPanelData.Tags)
 
 			if tt.expectError {
 				assert.Equal(t, tt.expectedErrorMessage, actualError.Error())
@@ -108,7 +131,8 @@ func TestSolarPanelDataService_CreateSolarPanelData(t *testing.T) {
 
 func TestSolarPanelDataService_GetSolarPanelData(t *testing.T) {
 	type args struct {
-		uuid string
+		uuid      string
+		selection *domain.EventSelection
 	}
 
 	mockCtrl := gomock.NewController(t)
@@ -116,6 +140,9 @@ func TestSolarPanelDataService_GetSolarPanelData(t *testing.T) {
 
 	mockRepository := mock_ports.NewMockSolarPanelDataRepositoryInterface(mockCtrl)
 
+	from := time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC)
+	before := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
 	tests := []struct {
 		name                      string
 		args                      args
@@ -128,20 +155,21 @@ func TestSolarPanelDataService_GetSolarPanelData(t *testing.T) {
 		{
 			name: "get ok",
 			args: args{
-				uuid: "uuid",
+				uuid:      "uuid",
+				selection: &domain.EventSelection{Source: domain.EventSourceSolar},
 			},
 			mockRepositoryReturnData: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"uuid1": [][]string{
-						{"timestamp1", "event1"},
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 					},
 				},
 				Wind: nil,
 			},
 			expected: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"uuid1": [][]string{
-						{"timestamp1", "event1"},
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 					},
 				},
 				Wind: nil,
@@ -151,7 +179,8 @@ func TestSolarPanelDataService_GetSolarPanelData(t *testing.T) {
 		{
 			name: "repo random error",
 			args: args{
-				uuid: "uuid",
+				uuid:      "uuid",
+				selection: &domain.EventSelection{Source: domain.EventSourceSolar},
 			},
 			mockRepositoryReturnData:  &domain.SolarPanelData{},
 			mockRepositoryReturnError: errors.New("random error"),
@@ -159,6 +188,123 @@ func TestSolarPanelDataService_GetSolarPanelData(t *testing.T) {
 			expectedErrorMessage:      "random error",
 			expectError:               true,
 		},
+		{
+			name: "malformed events",
+			args: args{
+				uuid:      "uuid",
+				selection: &domain.EventSelection{Source: domain.EventSourceSolar},
+			},
+			mockRepositoryReturnData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{},
+				},
+				MalformedEvents: []domain.MalformedEvent{
+					{
+						Source:        domain.EventSourceSolar,
+						ParameterId:   "uuid1",
+						Position:      0,
+						OriginalError: errors.New("event with no timestamp or value"),
+					},
+				},
+			},
+			expected:             &domain.SolarPanelData{},
+			expectedErrorMessage: "malformed solar panel data, check parameter uuid1",
+			expectError:          true,
+		},
+		{
+			name: "malformed events reports a solar one first",
+			args: args{
+				uuid:      "uuid",
+				selection: &domain.EventSelection{Source: domain.EventSourceAll},
+			},
+			mockRepositoryReturnData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{},
+				},
+				Wind: map[string][]domain.Event{
+					"turbine1": []domain.Event{},
+				},
+				MalformedEvents: []domain.MalformedEvent{
+					{
+						Source:        domain.EventSourceWind,
+						ParameterId:   "turbine1",
+						Position:      0,
+						OriginalError: errors.New("event with no timestamp or value"),
+					},
+					{
+						Source:        domain.EventSourceSolar,
+						ParameterId:   "uuid1",
+						Position:      0,
+						OriginalError: errors.New("event with no timestamp or value"),
+					},
+				},
+			},
+			expected:             &domain.SolarPanelData{},
+			expectedErrorMessage: "malformed solar panel data, check parameter uuid1",
+			expectError:          true,
+		},
+		{
+			name: "malformed events outside of the selection",
+			args: args{
+				uuid: "uuid",
+				selection: &domain.EventSelection{
+					Source:     domain.EventSourceSolar,
+					From:       &from,
+					Parameters: []string{"uuid1"},
+				},
+			},
+			mockRepositoryReturnData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{},
+					"uuid2": []domain.Event{},
+				},
+				MalformedEvents: []domain.MalformedEvent{
+					{
+						Source:        domain.EventSourceSolar,
+						ParameterId:   "uuid1",
+						Position:      0,
+						Timestamp:     &before,
+						OriginalError: errors.New("value \"x\" is not a number"),
+					},
+					{
+						Source:        domain.EventSourceSolar,
+						ParameterId:   "uuid2",
+						Position:      0,
+						OriginalError: errors.New("event with no timestamp or value"),
+					},
+					{
+						Source:        domain.EventSourceWind,
+						OriginalError: errors.New("invalid character 'x' looking for beginning of value"),
+					},
+				},
+			},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{},
+					"uuid2": []domain.Event{},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "malformed wind as a whole",
+			args: args{
+				uuid:      "uuid",
+				selection: &domain.EventSelection{Source: domain.EventSourceWind, Parameters: []string{"turbine1"}},
+			},
+			mockRepositoryReturnData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{},
+				MalformedEvents: []domain.MalformedEvent{
+					{
+						Source:        domain.EventSourceWind,
+						OriginalError: errors.New("invalid character 'x' looking for beginning of value"),
+					},
+				},
+			},
+			expected:             &domain.SolarPanelData{},
+			expectedErrorMessage: "malformed solar panel data, check the wind events",
+			expectError:          true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -170,7 +316,7 @@ func TestSolarPanelDataService_GetSolarPanelData(t *testing.T) {
 				GetSolarPanelData(tt.args.uuid).
 				Return(tt.mockRepositoryReturnData, tt.mockRepositoryReturnError)
 
-			actual, actualError := service.GetSolarPanelData(tt.args.uuid)
+			actual, actualError := service.GetSolarPanelData(tt.args.uuid, tt.args.selection)
 			if (actualError != nil) != tt.expectError {
 				t.Errorf("GetSolarPanelData() error = %v, expectError %v", actualError, tt.expectError)
 				return
@@ -185,6 +331,120 @@ func TestSolarPanelDataService_GetSolarPanelData(t *testing.T) {
 	}
 }
 
+func TestSolarPanelDataService_GetSolarPanelDataSkippingMalformedEvents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepository := mock_ports.NewMockSolarPanelDataRepositoryInterface(mockCtrl)
+
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+			},
+		},
+		MalformedEvents: []domain.MalformedEvent{
+			{
+				Source:        domain.EventSourceSolar,
+				ParameterId:   "uuid1",
+				Position:      1,
+				OriginalError: errors.New("event with no timestamp or value"),
+			},
+		},
+	}
+
+	mockRepository.EXPECT().
+		GetSolarPanelData("uuid").
+		Return(solarPanelData, nil)
+
+	service := SolarPanelDataService{
+		repository: mockRepository,
+	}
+
+	actual, err := service.GetSolarPanelDataSkippingMalformedEvents(
+		"uuid",
+		&domain.EventSelection{Source: domain.EventSourceAll},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, solarPanelData, actual)
+}
+
+func TestSolarPanelDataService_ListSolarPanelData(t *testing.T) {
+	type args struct {
+		options *domain.SolarPanelDataListOptions
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepository := mock_ports.NewMockSolarPanelDataRepositoryInterface(mockCtrl)
+
+	tests := []struct {
+		name                      string
+		args                      args
+		mockRepositoryReturnData  *domain.SolarPanelDataPage
+		mockRepositoryReturnError error
+		expected                  *domain.SolarPanelDataPage
+		expectedErrorMessage      string
+		expectError               bool
+	}{
+		{
+			name: "list ok",
+			args: args{
+				options: &domain.SolarPanelDataListOptions{Limit: 20},
+			},
+			mockRepositoryReturnData: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{
+					{Id: "uuid1", ParameterCount: 1, EventCount: 1},
+				},
+				HasMore: true,
+			},
+			expected: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{
+					{Id: "uuid1", ParameterCount: 1, EventCount: 1},
+				},
+				HasMore: true,
+			},
+			expectError: false,
+		},
+		{
+			name: "repo random error",
+			args: args{
+				options: &domain.SolarPanelDataListOptions{Limit: 20},
+			},
+			mockRepositoryReturnData:  nil,
+			mockRepositoryReturnError: errors.New("random error"),
+			expected:                  nil,
+			expectedErrorMessage:      "random error",
+			expectError:               true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := SolarPanelDataService{
+				repository: mockRepository,
+			}
+
+			mockRepository.EXPECT().
+				ListSolarPanelData(tt.args.options).
+				Return(tt.mockRepositoryReturnData, tt.mockRepositoryReturnError)
+
+			actual, actualError := service.ListSolarPanelData(tt.args.options)
+			if (actualError != nil) != tt.expectError {
+				t.Errorf("ListSolarPanelData() error = %v, expectError %v", actualError, tt.expectError)
+				return
+			}
+
+			assert.Equal(t, tt.expected, actual)
+
+			if tt.expectError {
+				assert.Equal(t, tt.expectedErrorMessage, actualError.Error())
+			}
+		})
+	}
+}
+
 func TestSolarPanelDataService_UpdateSolarPanelData(t *testing.T) {
 	type args struct {
 		uuid           string
@@ -207,9 +467,9 @@ func TestSolarPanelDataService_UpdateSolarPanelData(t *testing.T) {
 			name: "update ok",
 			args: args{
 				solarPanelData: &domain.SolarPanelData{
-					Solar: map[string][][]string{
-						"uuid1": [][]string{
-							{"timestamp1", "event1"},
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 						},
 					},
 					Wind: nil,
@@ -235,9 +495,9 @@ func TestSolarPanelDataService_UpdateSolarPanelData(t *testing.T) {
 			name: "repo returns error",
 			args: args{
 				solarPanelData: &domain.SolarPanelData{
-					Solar: map[string][][]string{
-						"uuid1": [][]string{
-							{"timestamp1", "event1"},
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 						},
 					},
 					Wind: nil,
@@ -267,6 +527,181 @@ func TestSolarPanelDataService_UpdateSolarPanelData(t *testing.T) {
 	}
 }
 
+func TestSolarPanelDataService_MergeSolarPanelData(t *testing.T) {
+	type args struct {
+		uuid           string
+		solarPanelData *domain.SolarPanelData
+		policy         domain.MergeConflictPolicy
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepository := mock_ports.NewMockSolarPanelDataRepositoryInterface(mockCtrl)
+
+	storedSolarPanelData := func() *domain.SolarPanelData {
+		return &domain.SolarPanelData{
+			Solar: map[string][]domain.Event{
+				"uuid1": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+				},
+			},
+			Version: 3,
+		}
+	}
+
+	tests := []struct {
+		name                       string
+		args                       args
+		mockRepositoryReturnData   *domain.SolarPanelData
+		mockRepositoryGetError     error
+		mockRepositoryUpdateErrors []error
+		expected                   *domain.MergeResult
+		expectedError              error
+	}{
+		{
+			name: "merge ok",
+			args: args{
+				uuid: "uuid",
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+						},
+					},
+				},
+				policy: domain.MergeConflictReject,
+			},
+			mockRepositoryReturnData:   storedSolarPanelData(),
+			mockRepositoryUpdateErrors: []error{nil},
+			expected:                   &domain.MergeResult{AppendedEvents: 1},
+		},
+		{
+			name: "conflict rejected",
+			args: args{
+				uuid: "uuid",
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1.5},
+						},
+					},
+				},
+				policy: domain.MergeConflictReject,
+			},
+			mockRepositoryReturnData: storedSolarPanelData(),
+			expectedError: apierrors.MergeConflictError{
+				ReturnedStatusCode: http.StatusConflict,
+				Violations: []apierrors.Violation{
+					{Path: `$.solar["uuid1"][0][1]`, Message: "conflicts with the stored value 1"},
+				},
+			},
+		},
+		{
+			name: "retried after a version conflict",
+			args: args{
+				uuid: "uuid",
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1.5},
+						},
+					},
+				},
+				policy: domain.MergeConflictOverwrite,
+			},
+			mockRepositoryReturnData: storedSolarPanelData(),
+			mockRepositoryUpdateErrors: []error{
+				apierrors.VersionConflictError{ReturnedStatusCode: http.StatusConflict, ExpectedVersion: 3},
+				nil,
+			},
+			expected: &domain.MergeResult{
+				OverwrittenEvents: 1,
+				Conflicts: []domain.MergeConflict{
+					{
+						Source:      domain.EventSourceSolar,
+						ParameterId: "uuid1",
+						Timestamp:   time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC),
+						StoredValue: 1,
+						MergedValue: 1.5,
+					},
+				},
+			},
+		},
+		{
+			name: "version conflict on every attempt",
+			args: args{
+				uuid:           "uuid",
+				solarPanelData: &domain.SolarPanelData{},
+				policy:         domain.MergeConflictReject,
+			},
+			mockRepositoryReturnData: storedSolarPanelData(),
+			mockRepositoryUpdateErrors: []error{
+				apierrors.VersionConflictError{ReturnedStatusCode: http.StatusConflict, ExpectedVersion: 3},
+				apierrors.VersionConflictError{ReturnedStatusCode: http.StatusConflict, ExpectedVersion: 3},
+				apierrors.VersionConflictError{ReturnedStatusCode: http.StatusConflict, ExpectedVersion: 3},
+			},
+			expectedError: apierrors.VersionConflictError{ReturnedStatusCode: http.StatusConflict, ExpectedVersion: 3},
+		},
+		{
+			name: "not found",
+			args: args{
+				uuid:           "uuid",
+				solarPanelData: &domain.SolarPanelData{},
+				policy:         domain.MergeConflictReject,
+			},
+			mockRepositoryReturnData: &domain.SolarPanelData{},
+			mockRepositoryGetError: &apierrors.DataNotFoundErrorWrapper{
+				ReturnedStatusCode: http.StatusNoContent,
+				OriginalError:      errors.New("uuid uuid not found"),
+			},
+			expectedError: &apierrors.DataNotFoundErrorWrapper{
+				ReturnedStatusCode: http.StatusNotFound,
+				OriginalError:      errors.New("uuid uuid not found"),
+			},
+		},
+		{
+			name: "repo update returns error",
+			args: args{
+				uuid:           "uuid",
+				solarPanelData: &domain.SolarPanelData{},
+				policy:         domain.MergeConflictReject,
+			},
+			mockRepositoryReturnData:   storedSolarPanelData(),
+			mockRepositoryUpdateErrors: []error{errors.New("random error")},
+			expectedError:              errors.New("random error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := SolarPanelDataService{
+				repository: mockRepository,
+			}
+
+			attempts := len(tt.mockRepositoryUpdateErrors)
+			if attempts == 0 {
+				attempts = 1
+			}
+
+			mockRepository.EXPECT().
+				GetSolarPanelData(tt.args.uuid).
+				Return(tt.mockRepositoryReturnData, tt.mockRepositoryGetError).
+				Times(attempts)
+
+			for _, updateError := range tt.mockRepositoryUpdateErrors {
+				mockRepository.EXPECT().
+					UpdateSolarPanelDataIfVersion(tt.args.uuid, gomock.Any(), 3).
+					Return(updateError)
+			}
+
+			actual, actualError := service.MergeSolarPanelData(tt.args.uuid, tt.args.solarPanelData, tt.args.policy)
+
+			assert.Equal(t, tt.expected, actual)
+			assert.Equal(t, tt.expectedError, actualError)
+		})
+	}
+}
+
 func TestSolarPanelDataService_DeleteSolarPanelData(t *testing.T) {
 	type args struct {
 		uuid string
@@ -0,0 +1,97 @@
+package solarPanelData
+
+import (
+	"encoding/json"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/services"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// analysisResponder reads the dataset of the endpoints analysing one, and
+// writes their json responses, so that the statistics, quality, energy and
+// anomalies endpoints answer the same failures the same way.
+type analysisResponder struct {
+	service services.SolarPanelDataServiceInterface
+	logger  *log.Logger
+	// logMessage is what the errors of the endpoint are logged with.
+	logMessage string
+}
+
+// getSolarPanelData returns the dataset to analyse, or writes the error response
+// and returns false when it cannot be read.
+func (responder analysisResponder) getSolarPanelData(
+	w http.ResponseWriter,
+	dataUuid string,
+	selection *domain.EventSelection,
+) (*domain.SolarPanelData, bool) {
+	solarPanelData, err := responder.service.GetSolarPanelData(dataUuid, selection)
+	if dataNotFoundErrorWrapper, ok := err.(*apierrors.DataNotFoundErrorWrapper); ok {
+		responder.logger.WithFields(log.Fields{
+			"errorMessage": dataNotFoundErrorWrapper.Unwrap().Error(),
+		}).Debug(responder.logMessage)
+
+		w.WriteHeader(dataNotFoundErrorWrapper.ReturnedStatusCode)
+
+		return nil, false
+	}
+
+	if malformedEventDataError, ok := err.(apierrors.MalformedEventDataError); ok {
+		responder.logger.WithFields(log.Fields{
+			"errorMessage": malformedEventDataError.Unwrap().Error(),
+		}).Debug(responder.logMessage)
+
+		responder.writeError(w, malformedEventDataError.ReturnedStatusCode, malformedEventDataError.Error())
+
+		return nil, false
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		responder.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error(responder.logMessage)
+
+		return nil, false
+	}
+
+	return solarPanelData, true
+}
+
+// writeAnalysisError writes the error the analysis of the dataset failed with.
+func (responder analysisResponder) writeAnalysisError(w http.ResponseWriter, err error) {
+	if unknownParametersError, ok := err.(apierrors.UnknownParametersError); ok {
+		responder.writeError(w, unknownParametersError.ReturnedStatusCode, unknownParametersError.Error())
+
+		return
+	}
+
+	w.WriteHeader(http.StatusInternalServerError)
+
+	responder.logger.WithFields(log.Fields{
+		"errorMessage": err.Error(),
+	}).Error(responder.logMessage)
+}
+
+func (responder analysisResponder) writeResponse(w http.ResponseWriter, response interface{}) {
+	w.WriteHeader(http.StatusOK)
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		responder.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error(responder.logMessage)
+	}
+}
+
+func (responder analysisResponder) writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.WriteHeader(statusCode)
+
+	err := json.NewEncoder(w).Encode(&ErrorResponse{ErrorMessage: message})
+	if err != nil {
+		responder.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error(responder.logMessage)
+	}
+}
@@ -0,0 +1,76 @@
+package solarPanelData
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/services"
+	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// GetSolarPanelDataAnomaliesHandler reports the intervals each series of a
+// dataset read far below the others, which for panels of the same site usually
+// means soiling or a fault.
+type GetSolarPanelDataAnomaliesHandler struct {
+	SolarPanelDataService         services.SolarPanelDataServiceInterface
+	SolarPanelDataAnomalyDetector helper.SolarPanelDataAnomalyDetectorInterface
+	logger                        *log.Logger
+}
+
+func NewGetSolarPanelDataAnomaliesHandler(
+	service *services.SolarPanelDataService,
+	detector *helper.SolarPanelDataAnomalyDetector,
+	logger *log.Logger,
+) *GetSolarPanelDataAnomaliesHandler {
+	return &GetSolarPanelDataAnomaliesHandler{
+		SolarPanelDataService:         service,
+		SolarPanelDataAnomalyDetector: detector,
+		logger:                        logger,
+	}
+}
+
+func (handler *GetSolarPanelDataAnomaliesHandler) GetSolarPanelDataAnomaliesController(w http.ResponseWriter, r *http.Request) {
+	responder := analysisResponder{
+		service:    handler.SolarPanelDataService,
+		logger:     handler.logger,
+		logMessage: "Error in getting solar panel data anomalies",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	dataUuid := mux.Vars(r)["id"]
+	if dataUuid == "" {
+		responder.writeError(w, http.StatusBadRequest, "missing solarPanelData id")
+
+		return
+	}
+
+	anomalyOptions, err := helper.ParseAnomalyOptions(r.URL.Query())
+	if err != nil {
+		responder.writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	solarPanelData, ok := responder.getSolarPanelData(w, dataUuid, &anomalyOptions.EventSelection)
+	if !ok {
+		return
+	}
+
+	anomalies, err := handler.SolarPanelDataAnomalyDetector.DetectAnomalies(solarPanelData, anomalyOptions)
+	if err != nil {
+		responder.writeAnalysisError(w, err)
+
+		return
+	}
+
+	response := &GetSolarPanelDataAnomaliesResponse{
+		Parameters: make([]ParameterAnomaliesDto, 0, len(anomalies)),
+	}
+
+	for _, parameterAnomalies := range anomalies {
+		response.Parameters = append(response.Parameters, toParameterAnomaliesDto(parameterAnomalies))
+	}
+
+	responder.writeResponse(w, response)
+}
@@ -0,0 +1,239 @@
+package solarPanelData
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	mock_services "github.com/loukaspe/solar-panel-data-crud/mocks/mock_internal/core/services"
+	mock_helper "github.com/loukaspe/solar-panel-data-crud/mocks/mock_pkg/helper"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetSolarPanelDataAnomaliesHandler_GetSolarPanelDataAnomaliesController(t *testing.T) {
+	logger := logrus.New()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockService := mock_services.NewMockSolarPanelDataServiceInterface(mockCtrl)
+	mockDetector := mock_helper.NewMockSolarPanelDataAnomalyDetectorInterface(mockCtrl)
+
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 40},
+			},
+			"5bfd3a37-a3a4-4a43-bd4d-ec4a7a1e8f5d": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 100},
+			},
+			"c3a5b1b4-3f0e-4b48-9b0c-8bd1b2f1a6f4": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 100},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                      string
+		requestedUuid             string
+		requestQuery              string
+		shouldMockServiceRun      bool
+		mockServiceResponseData   *domain.SolarPanelData
+		mockServiceResponseError  error
+		shouldMockDetectorRun     bool
+		expectedAnomalyOptions    *helper.AnomalyOptions
+		mockDetectorResponseData  []helper.ParameterAnomalies
+		mockDetectorResponseError error
+		expected                  string
+		expectedStatusCode        int
+	}{
+		{
+			name:                     "valid",
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:             "?interval=1h&threshold=0.5",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  solarPanelData,
+			mockServiceResponseError: nil,
+			shouldMockDetectorRun:    true,
+			expectedAnomalyOptions: &helper.AnomalyOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Interval:  time.Hour,
+				Threshold: 0.5,
+			},
+			mockDetectorResponseData: []helper.ParameterAnomalies{
+				{
+					Source:           domain.EventSourceSolar,
+					ParameterId:      "38d503e5-dc1c-4549-8172-09d9c29070f7",
+					ComparedReadings: 1,
+					Anomalies: []helper.Anomaly{
+						{
+							From:     time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC),
+							To:       time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC),
+							Readings: 1,
+							Score:    0.6,
+						},
+					},
+				},
+				{
+					Source:           domain.EventSourceSolar,
+					ParameterId:      "5bfd3a37-a3a4-4a43-bd4d-ec4a7a1e8f5d",
+					ComparedReadings: 1,
+					Anomalies:        []helper.Anomaly{},
+				},
+			},
+			mockDetectorResponseError: nil,
+			expected: `{"parameters":[{"source":"solar","parameterId":"38d503e5-dc1c-4549-8172-09d9c29070f7",` +
+				`"comparedReadings":1,"anomalies":[{"from":"2022-01-01T10:00:00Z","to":"2022-01-01T10:00:00Z",` +
+				`"readings":1,"score":0.6}]},{"source":"solar","parameterId":"5bfd3a37-a3a4-4a43-bd4d-ec4a7a1e8f5d",` +
+				`"comparedReadings":1,"anomalies":[]}]}` + "\n",
+			expectedStatusCode: 200,
+		},
+		{
+			name:                     "unknown parameters",
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:             "?parameters=uuid2",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  solarPanelData,
+			mockServiceResponseError: nil,
+			shouldMockDetectorRun:    true,
+			expectedAnomalyOptions: &helper.AnomalyOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceSolar,
+					Parameters: []string{"uuid2"},
+				},
+				Threshold: 0.3,
+			},
+			mockDetectorResponseData: nil,
+			mockDetectorResponseError: apierrors.UnknownParametersError{
+				ReturnedStatusCode: http.StatusNotFound,
+				ParameterIds:       []string{"uuid2"},
+			},
+			expected:           `{"errorMessage":"unknown parameters uuid2"}` + "\n",
+			expectedStatusCode: 404,
+		},
+		{
+			name:                  "all sources",
+			requestedUuid:         "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:          "?source=all",
+			shouldMockServiceRun:  false,
+			shouldMockDetectorRun: false,
+			expected:              `{"errorMessage":"source must be one of solar, wind"}` + "\n",
+			expectedStatusCode:    400,
+		},
+		{
+			name:                  "overflowing interval",
+			requestedUuid:         "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:          "?interval=2251799813685248h",
+			shouldMockServiceRun:  false,
+			shouldMockDetectorRun: false,
+			expected: `{"errorMessage":"interval must be a number of minutes or hours that divides a day, ` +
+				`e.g. 15m or 1h, or 1d"}` + "\n",
+			expectedStatusCode: 400,
+		},
+		{
+			name:                  "missing id",
+			requestedUuid:         "",
+			shouldMockServiceRun:  false,
+			shouldMockDetectorRun: false,
+			expected:              `{"errorMessage":"missing solarPanelData id"}` + "\n",
+			expectedStatusCode:    400,
+		},
+		{
+			name:                    "not found",
+			requestedUuid:           "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:    true,
+			mockServiceResponseData: nil,
+			mockServiceResponseError: &apierrors.DataNotFoundErrorWrapper{
+				ReturnedStatusCode: http.StatusNoContent,
+				OriginalError:      errors.New("solarPanelData not found"),
+			},
+			shouldMockDetectorRun: false,
+			expected:              "",
+			expectedStatusCode:    204,
+		},
+		{
+			name:                    "malformed events",
+			requestedUuid:           "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:    true,
+			mockServiceResponseData: nil,
+			mockServiceResponseError: apierrors.MalformedEventDataError{
+				ReturnedStatusCode:   http.StatusInternalServerError,
+				OriginalError:        errors.New("event with no timestamp or value"),
+				MalformedParameterId: "uuid1",
+			},
+			shouldMockDetectorRun: false,
+			expected:              `{"errorMessage":"malformed solar panel data, check parameter uuid1"}` + "\n",
+			expectedStatusCode:    500,
+		},
+		{
+			name:                     "random service error",
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  nil,
+			mockServiceResponseError: errors.New("random error"),
+			shouldMockDetectorRun:    false,
+			expected:                 "",
+			expectedStatusCode:       500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRequest := httptest.NewRequest(
+				"GET",
+				"/solarPanelData/anomalies"+tt.requestQuery,
+				nil,
+			)
+			vars := map[string]string{
+				"id": tt.requestedUuid,
+			}
+			mockRequest = mux.SetURLVars(mockRequest, vars)
+			mockResponseRecorder := httptest.NewRecorder()
+
+			// the service is asked for the events the query selects, which
+			// its malformed events are scoped to
+			requestedOptions, _ := helper.ParseAnomalyOptions(mockRequest.URL.Query())
+
+			if tt.shouldMockServiceRun {
+				mockService.EXPECT().
+					GetSolarPanelData(tt.requestedUuid, &requestedOptions.EventSelection).
+					Return(tt.mockServiceResponseData, tt.mockServiceResponseError)
+			}
+
+			if tt.shouldMockDetectorRun {
+				mockDetector.EXPECT().
+					DetectAnomalies(tt.mockServiceResponseData, tt.expectedAnomalyOptions).
+					Return(tt.mockDetectorResponseData, tt.mockDetectorResponseError)
+			}
+
+			handler := &GetSolarPanelDataAnomaliesHandler{
+				SolarPanelDataService:         mockService,
+				SolarPanelDataAnomalyDetector: mockDetector,
+				logger:                        logger,
+			}
+			sut := handler.GetSolarPanelDataAnomaliesController
+
+			sut(mockResponseRecorder, mockRequest)
+
+			mockResponse := mockResponseRecorder.Result()
+			actual, err := io.ReadAll(mockResponse.Body)
+			if err != nil {
+				t.Errorf("error with response reading: %v", err)
+				return
+			}
+			actualStatusCode := mockResponse.StatusCode
+
+			assert.Equal(t, tt.expected, string(actual))
+			assert.Equal(t, tt.expectedStatusCode, actualStatusCode)
+		})
+	}
+}
@@ -2,25 +2,28 @@ package solarPanelData
 
 import (
 	"encoding/json"
-	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
 	"github.com/loukaspe/solar-panel-data-crud/internal/core/services"
 	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
 	log "github.com/sirupsen/logrus"
 	"net/http"
 )
 
 type CreateSolarPanelDataHandler struct {
-	SolarPanelDataService services.SolarPanelDataServiceInterface
-	logger                *log.Logger
+	SolarPanelDataService   services.SolarPanelDataServiceInterface
+	SolarPanelDataValidator helper.SolarPanelDataValidatorInterface
+	logger                  *log.Logger
 }
 
 func NewCreateSolarPanelDataHandler(
 	service *services.SolarPanelDataService,
+	validator *helper.SolarPanelDataValidator,
 	logger *log.Logger,
 ) *CreateSolarPanelDataHandler {
 	return &CreateSolarPanelDataHandler{
-		SolarPanelDataService: service,
-		logger:                logger,
+		SolarPanelDataService:   service,
+		SolarPanelDataValidator: validator,
+		logger:                  logger,
 	}
 }
 
@@ -52,9 +55,62 @@ func (handler *CreateSolarPanelDataHandler) CreateSolarPanelDataController(w htt
 		return
 	}
 
-	domainSolarPanelData := &domain.SolarPanelData{
-		Solar: solarPanelDataRequest.Solar,
-		Wind:  solarPanelDataRequest.Wind,
+	err = handler.SolarPanelDataValidator.ValidateSolarPanelData(
+		solarPanelDataRequest.Solar,
+		solarPanelDataRequest.Wind,
+	)
+	if validationError, ok := err.(apierrors.ValidationError); ok {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": validationError.Error(),
+		}).Debug("Error in creating solar panel data")
+
+		w.WriteHeader(validationError.ReturnedStatusCode)
+		response.ErrorMessage = validationError.Error()
+		response.Violations = newViolationDtos(validationError.Violations)
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			handler.logger.WithFields(log.Fields{
+				"errorMessage": err.Error(),
+			}).Error("Error in creating solar panel data")
+
+			return
+		}
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error("Error in creating solar panel data")
+
+		return
+	}
+
+	domainSolarPanelData, err := solarPanelDataRequest.toDomain()
+	if invalidEventError, ok := err.(apierrors.InvalidEventError); ok {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": invalidEventError.Error(),
+		}).Debug("Error in creating solar panel data")
+
+		w.WriteHeader(invalidEventError.ReturnedStatusCode)
+		response.ErrorMessage = invalidEventError.Error()
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			handler.logger.WithFields(log.Fields{
+				"errorMessage": err.Error(),
+			}).Error("Error in creating solar panel data")
+
+			return
+		}
+
+		return
 	}
 
 	insertedId, err := handler.SolarPanelDataService.CreateSolarPanelData(domainSolarPanelData)
@@ -97,6 +153,9 @@ func (handler *CreateSolarPanelDataHandler) CreateSolarPanelDataController(w htt
 
 	response.DataSubmitted = solarPanelDataRequest
 	response.InsertedId = insertedId
+	response.CreatedAt = &domainSolarPanelData.CreatedAt
+	response.UpdatedAt = &domainSolarPanelData.UpdatedAt
+	response.Version = domainSolarPanelData.Version
 
 	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
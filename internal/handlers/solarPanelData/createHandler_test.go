@@ -7,6 +7,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
 	mock_services "github.com/loukaspe/solar-panel-data-crud/mocks/mock_internal/core/services"
+	mock_helper "github.com/loukaspe/solar-panel-data-crud/mocks/mock_pkg/helper"
 	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -14,6 +15,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestCreateSolarPanelDataHandler_CreateSolarPanelDataController(t *testing.T) {
@@ -22,17 +24,22 @@ func TestCreateSolarPanelDataHandler_CreateSolarPanelDataController(t *testing.T
 	defer mockCtrl.Finish()
 
 	mockService := mock_services.NewMockSolarPanelDataServiceInterface(mockCtrl)
+	mockValidator := mock_helper.NewMockSolarPanelDataValidatorInterface(mockCtrl)
+
+	createdAt := time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name            string
 		requestBody     []byte
 		mockRequestData *domain.SolarPanelData
-		// variable to check if the handler returns error before the mock service runs
-		shouldMockServiceRun     bool
-		mockServiceResponseUuid  string
-		mockServiceResponseError error
-		expected                 []byte
-		expectedStatusCode       int
+		// variables to check if the handler returns error before the mock validator and service run
+		shouldMockValidatorRun     bool
+		mockValidatorResponseError error
+		shouldMockServiceRun       bool
+		mockServiceResponseUuid    string
+		mockServiceResponseError   error
+		expected                   []byte
+		expectedStatusCode         int
 	}{
 		{
 			name: "valid",
@@ -48,20 +55,96 @@ func TestCreateSolarPanelDataHandler_CreateSolarPanelDataController(t *testing.T
   "wind": null
 }`),
 			mockRequestData: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"38d503e5-dc1c-4549-8172-09d9c29070f7": [][]string{
-						{"20211231T221500Z", "0.0"},
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
 					},
 				},
 				Wind: nil,
 			},
+			shouldMockValidatorRun:   true,
 			shouldMockServiceRun:     true,
 			mockServiceResponseUuid:  "newUuid",
 			mockServiceResponseError: nil,
-			expected: json.RawMessage(`{"id":"newUuid","dataSubmitted":{"solar":{"38d503e5-dc1c-4549-8172-09d9c29070f7":[["20211231T221500Z","0.0"]]},"wind":null}}
+			expected: json.RawMessage(`{"id":"newUuid","dataSubmitted":{"solar":{"38d503e5-dc1c-4549-8172-09d9c29070f7":[["20211231T221500Z","0.0"]]},"wind":null},"createdAt":"2022-01-01T06:00:00Z","updatedAt":"2022-01-01T06:00:00Z","version":1}
 `),
 			expectedStatusCode: 201,
 		},
+		{
+			name: "valid with metadata",
+			requestBody: json.RawMessage(`{
+  "solar": {
+    "38d503e5-dc1c-4549-8172-09d9c29070f7": [
+      [
+        "20211231T221500Z",
+        "0.0"
+      ]
+    ]
+  },
+  "wind": null,
+  "name": "roof",
+  "site": "athens",
+  "tags": ["south"]
+}`),
+			mockRequestData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+					},
+				},
+				Wind: nil,
+				Name: "roof",
+				Site: "athens",
+				Tags: []string{"south"},
+			},
+			shouldMockValidatorRun:   true,
+			shouldMockServiceRun:     true,
+			mockServiceResponseUuid:  "newUuid",
+			mockServiceResponseError: nil,
+			expected: json.RawMessage(`{"id":"newUuid","dataSubmitted":{"solar":{"38d503e5-dc1c-4549-8172-09d9c29070f7":[["20211231T221500Z","0.0"]]},"wind":null,"name":"roof","site":"athens","tags":["south"]},"createdAt":"2022-01-01T06:00:00Z","updatedAt":"2022-01-01T06:00:00Z","version":1}
+`),
+			expectedStatusCode: 201,
+		},
+		{
+			name: "valid with wind",
+			requestBody: json.RawMessage(`{
+  "solar": {},
+  "wind": {
+    "c078ff68-04fb-11e9-a615-42010afa015a": [
+      [
+        "20211231T221500Z",
+        "12.5"
+      ]
+    ]
+  }
+}`),
+			mockRequestData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{},
+				Wind: map[string][]domain.Event{
+					"c078ff68-04fb-11e9-a615-42010afa015a": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 12.5},
+					},
+				},
+			},
+			shouldMockValidatorRun:   true,
+			shouldMockServiceRun:     true,
+			mockServiceResponseUuid:  "newUuid",
+			mockServiceResponseError: nil,
+			expected: json.RawMessage(`{"id":"newUuid","dataSubmitted":{"solar":{},"wind":{"c078ff68-04fb-11e9-a615-42010afa015a":[["20211231T221500Z","12.5"]]}},"createdAt":"2022-01-01T06:00:00Z","updatedAt":"2022-01-01T06:00:00Z","version":1}
+`),
+			expectedStatusCode: 201,
+		},
+		{
+			name: "invalid wind not an event series",
+			requestBody: json.RawMessage(`{
+  "solar": {},
+  "wind": {"turbine": "event"}
+}`),
+			shouldMockServiceRun: false,
+			expected: json.RawMessage(`{"errorMessage":"malformed solar panel data request"}
+`),
+			expectedStatusCode: 400,
+		},
 		{
 			name: "invalid bad request",
 			requestBody: json.RawMessage(`{
@@ -77,6 +160,73 @@ func TestCreateSolarPanelDataHandler_CreateSolarPanelDataController(t *testing.T
 }`),
 			shouldMockServiceRun: false,
 			expected: json.RawMessage(`{"errorMessage":"malformed solar panel data request"}
+`),
+			expectedStatusCode: 400,
+		},
+		{
+			name: "invalid event timestamp",
+			requestBody: json.RawMessage(`{
+  "solar": {
+    "38d503e5-dc1c-4549-8172-09d9c29070f7": [
+      [
+        "2021-12-31 22:15:00",
+        "0.0"
+      ]
+    ]
+  },
+  "wind": null
+}`),
+			shouldMockValidatorRun: true,
+			shouldMockServiceRun:   false,
+			expected: json.RawMessage(`{"errorMessage":"invalid event 0 of parameter 38d503e5-dc1c-4549-8172-09d9c29070f7: timestamp \"2021-12-31 22:15:00\" is not in the 20060102T150405Z format"}
+`),
+			expectedStatusCode: 400,
+		},
+		{
+			name: "invalid event value",
+			requestBody: json.RawMessage(`{
+  "solar": {
+    "38d503e5-dc1c-4549-8172-09d9c29070f7": [
+      [
+        "20211231T221500Z",
+        "0.0"
+      ],
+      [
+        "20211231T223000Z",
+        "n/a"
+      ]
+    ]
+  },
+  "wind": null
+}`),
+			shouldMockValidatorRun: true,
+			shouldMockServiceRun:   false,
+			expected: json.RawMessage(`{"errorMessage":"invalid event 1 of parameter 38d503e5-dc1c-4549-8172-09d9c29070f7: value \"n/a\" is not a number"}
+`),
+			expectedStatusCode: 400,
+		},
+		{
+			name: "invalid validation error",
+			requestBody: json.RawMessage(`{
+  "solar": {
+    "not-a-uuid": [
+      [
+        "20211231T221500Z"
+      ]
+    ]
+  },
+  "wind": null
+}`),
+			shouldMockValidatorRun: true,
+			mockValidatorResponseError: apierrors.ValidationError{
+				ReturnedStatusCode: http.StatusBadRequest,
+				Violations: []apierrors.Violation{
+					{Path: `$.solar["not-a-uuid"]`, Message: "is not a UUID"},
+					{Path: `$.solar["not-a-uuid"][0]`, Message: "must be a [timestamp, value] pair"},
+				},
+			},
+			shouldMockServiceRun: false,
+			expected: json.RawMessage(`{"errorMessage":"invalid solar panel data, check violations","violations":[{"path":"$.solar[\"not-a-uuid\"]","message":"is not a UUID"},{"path":"$.solar[\"not-a-uuid\"][0]","message":"must be a [timestamp, value] pair"}]}
 `),
 			expectedStatusCode: 400,
 		},
@@ -96,6 +246,7 @@ func TestCreateSolarPanelDataHandler_CreateSolarPanelDataController(t *testing.T
 			mockRequestData: &domain.SolarPanelData{
 				Wind: nil,
 			},
+			shouldMockValidatorRun:  true,
 			shouldMockServiceRun:    true,
 			mockServiceResponseUuid: "",
 			mockServiceResponseError: apierrors.EmptySolarDataError{
@@ -119,13 +270,14 @@ func TestCreateSolarPanelDataHandler_CreateSolarPanelDataController(t *testing.T
   "wind": null
 }`),
 			mockRequestData: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"38d503e5-dc1c-4549-8172-09d9c29070f7": [][]string{
-						{"20211231T221500Z", "0.0"},
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
 					},
 				},
 				Wind: nil,
 			},
+			shouldMockValidatorRun:   true,
 			shouldMockServiceRun:     true,
 			mockServiceResponseUuid:  "",
 			mockServiceResponseError: errors.New("random error"),
@@ -143,15 +295,31 @@ func TestCreateSolarPanelDataHandler_CreateSolarPanelDataController(t *testing.T
 			mockRequest.Header.Set("Content-Type", "application/json")
 			mockResponseRecorder := httptest.NewRecorder()
 
+			if tt.shouldMockValidatorRun {
+				mockValidator.EXPECT().
+					ValidateSolarPanelData(gomock.Any(), gomock.Any()).
+					Return(tt.mockValidatorResponseError)
+			}
+
 			if tt.shouldMockServiceRun {
 				mockService.EXPECT().
 					CreateSolarPanelData(tt.mockRequestData).
-					Return(tt.mockServiceResponseUuid, tt.mockServiceResponseError)
+					DoAndReturn(func(solarPanelData *domain.SolarPanelData) (string, error) {
+						// the repository sets the server managed fields on success
+						if tt.mockServiceResponseError == nil {
+							solarPanelData.CreatedAt = createdAt
+							solarPanelData.UpdatedAt = createdAt
+							solarPanelData.Version = 1
+						}
+
+						return tt.mockServiceResponseUuid, tt.mockServiceResponseError
+					})
 			}
 
 			handler := &CreateSolarPanelDataHandler{
-				SolarPanelDataService: mockService,
-				logger:                logger,
+				SolarPanelDataService:   mockService,
+				SolarPanelDataValidator: mockValidator,
+				logger:                  logger,
 			}
 			sut := handler.CreateSolarPanelDataController
 
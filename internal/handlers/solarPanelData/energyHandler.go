@@ -0,0 +1,84 @@
+package solarPanelData
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/services"
+	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// GetSolarPanelDataEnergyHandler reports the energy in kWh the power readings
+// of a dataset add up to, per day and per parameter id.
+type GetSolarPanelDataEnergyHandler struct {
+	SolarPanelDataService          services.SolarPanelDataServiceInterface
+	SolarPanelDataEnergyCalculator helper.SolarPanelDataEnergyCalculatorInterface
+	logger                         *log.Logger
+}
+
+func NewGetSolarPanelDataEnergyHandler(
+	service *services.SolarPanelDataService,
+	calculator *helper.SolarPanelDataEnergyCalculator,
+	logger *log.Logger,
+) *GetSolarPanelDataEnergyHandler {
+	return &GetSolarPanelDataEnergyHandler{
+		SolarPanelDataService:          service,
+		SolarPanelDataEnergyCalculator: calculator,
+		logger:                         logger,
+	}
+}
+
+func (handler *GetSolarPanelDataEnergyHandler) GetSolarPanelDataEnergyController(w http.ResponseWriter, r *http.Request) {
+	responder := analysisResponder{
+		service:    handler.SolarPanelDataService,
+		logger:     handler.logger,
+		logMessage: "Error in getting solar panel data energy",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	dataUuid := mux.Vars(r)["id"]
+	if dataUuid == "" {
+		responder.writeError(w, http.StatusBadRequest, "missing solarPanelData id")
+
+		return
+	}
+
+	energyOptions, err := helper.ParseEnergyOptions(r.URL.Query())
+	if err != nil {
+		responder.writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	solarPanelData, ok := responder.getSolarPanelData(w, dataUuid, &energyOptions.EventSelection)
+	if !ok {
+		return
+	}
+
+	energy, err := handler.SolarPanelDataEnergyCalculator.CalculateEnergy(solarPanelData, energyOptions)
+	if err != nil {
+		responder.writeAnalysisError(w, err)
+
+		return
+	}
+
+	response := &GetSolarPanelDataEnergyResponse{
+		Unit:       "kWh",
+		Parameters: make([]ParameterEnergyDto, 0, len(energy.Parameters)),
+		Days:       toDailyEnergyDtos(energy.Days),
+		Total:      energy.Total,
+	}
+
+	for _, parameterEnergy := range energy.Parameters {
+		response.Parameters = append(response.Parameters, ParameterEnergyDto{
+			Source:      parameterEnergy.Source,
+			ParameterId: parameterEnergy.ParameterId,
+			Days:        toDailyEnergyDtos(parameterEnergy.Days),
+			Total:       parameterEnergy.Total,
+			SkippedGaps: parameterEnergy.SkippedGaps,
+		})
+	}
+
+	responder.writeResponse(w, response)
+}
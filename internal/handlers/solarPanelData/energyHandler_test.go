@@ -0,0 +1,219 @@
+package solarPanelData
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	mock_services "github.com/loukaspe/solar-panel-data-crud/mocks/mock_internal/core/services"
+	mock_helper "github.com/loukaspe/solar-panel-data-crud/mocks/mock_pkg/helper"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetSolarPanelDataEnergyHandler_GetSolarPanelDataEnergyController(t *testing.T) {
+	logger := logrus.New()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockService := mock_services.NewMockSolarPanelDataServiceInterface(mockCtrl)
+	mockCalculator := mock_helper.NewMockSolarPanelDataEnergyCalculatorInterface(mockCtrl)
+
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC), Value: 81.9354839},
+				{Timestamp: time.Date(2022, 1, 1, 7, 0, 0, 0, time.UTC), Value: 120},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                        string
+		requestedUuid               string
+		requestQuery                string
+		shouldMockServiceRun        bool
+		mockServiceResponseData     *domain.SolarPanelData
+		mockServiceResponseError    error
+		shouldMockCalculatorRun     bool
+		expectedEnergyOptions       *helper.EnergyOptions
+		mockCalculatorResponseData  *helper.SolarPanelDataEnergy
+		mockCalculatorResponseError error
+		expected                    string
+		expectedStatusCode          int
+	}{
+		{
+			name:                     "valid",
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:             "?unit=kW&gaps=skip&maxGap=30m",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  solarPanelData,
+			mockServiceResponseError: nil,
+			shouldMockCalculatorRun:  true,
+			expectedEnergyOptions: &helper.EnergyOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Unit:     helper.EnergyUnitKilowatt,
+				Gaps:     helper.EnergyGapsSkip,
+				MaxGap:   30 * time.Minute,
+				Location: time.UTC,
+			},
+			mockCalculatorResponseData: &helper.SolarPanelDataEnergy{
+				Parameters: []helper.ParameterEnergy{
+					{
+						Source:      domain.EventSourceSolar,
+						ParameterId: "38d503e5-dc1c-4549-8172-09d9c29070f7",
+						Days:        []helper.DailyEnergy{{Date: "2022-01-01", Energy: 100.5}},
+						Total:       100.5,
+						SkippedGaps: 1,
+					},
+				},
+				Days:  []helper.DailyEnergy{{Date: "2022-01-01", Energy: 100.5}},
+				Total: 100.5,
+			},
+			mockCalculatorResponseError: nil,
+			expected: `{"unit":"kWh","parameters":[{"source":"solar","parameterId":"38d503e5-dc1c-4549-8172-09d9c29070f7",` +
+				`"days":[{"date":"2022-01-01","energy":100.5}],"total":100.5,"skippedGaps":1}],` +
+				`"days":[{"date":"2022-01-01","energy":100.5}],"total":100.5}` + "\n",
+			expectedStatusCode: 200,
+		},
+		{
+			name:                     "unknown parameters",
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:             "?parameter=uuid2",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  solarPanelData,
+			mockServiceResponseError: nil,
+			shouldMockCalculatorRun:  true,
+			expectedEnergyOptions: &helper.EnergyOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceSolar,
+					Parameters: []string{"uuid2"},
+				},
+				Unit:     helper.EnergyUnitWatt,
+				Gaps:     helper.EnergyGapsInterpolate,
+				Location: time.UTC,
+			},
+			mockCalculatorResponseData: nil,
+			mockCalculatorResponseError: apierrors.UnknownParametersError{
+				ReturnedStatusCode: http.StatusNotFound,
+				ParameterIds:       []string{"uuid2"},
+			},
+			expected:           `{"errorMessage":"unknown parameters uuid2"}` + "\n",
+			expectedStatusCode: 404,
+		},
+		{
+			name:                    "invalid unit",
+			requestedUuid:           "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:            "?unit=kWh",
+			shouldMockServiceRun:    false,
+			shouldMockCalculatorRun: false,
+			expected:                `{"errorMessage":"unit must be one of W, kW, MW"}` + "\n",
+			expectedStatusCode:      400,
+		},
+		{
+			name:                    "missing id",
+			requestedUuid:           "",
+			shouldMockServiceRun:    false,
+			shouldMockCalculatorRun: false,
+			expected:                `{"errorMessage":"missing solarPanelData id"}` + "\n",
+			expectedStatusCode:      400,
+		},
+		{
+			name:                    "not found",
+			requestedUuid:           "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:    true,
+			mockServiceResponseData: nil,
+			mockServiceResponseError: &apierrors.DataNotFoundErrorWrapper{
+				ReturnedStatusCode: http.StatusNoContent,
+				OriginalError:      errors.New("solarPanelData not found"),
+			},
+			shouldMockCalculatorRun: false,
+			expected:                "",
+			expectedStatusCode:      204,
+		},
+		{
+			name:                    "malformed events",
+			requestedUuid:           "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:    true,
+			mockServiceResponseData: nil,
+			mockServiceResponseError: apierrors.MalformedEventDataError{
+				ReturnedStatusCode:   http.StatusInternalServerError,
+				OriginalError:        errors.New("event with no timestamp or value"),
+				MalformedParameterId: "uuid1",
+			},
+			shouldMockCalculatorRun: false,
+			expected:                `{"errorMessage":"malformed solar panel data, check parameter uuid1"}` + "\n",
+			expectedStatusCode:      500,
+		},
+		{
+			name:                     "random service error",
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  nil,
+			mockServiceResponseError: errors.New("random error"),
+			shouldMockCalculatorRun:  false,
+			expected:                 "",
+			expectedStatusCode:       500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRequest := httptest.NewRequest(
+				"GET",
+				"/solarPanelData/energy"+tt.requestQuery,
+				nil,
+			)
+			vars := map[string]string{
+				"id": tt.requestedUuid,
+			}
+			mockRequest = mux.SetURLVars(mockRequest, vars)
+			mockResponseRecorder := httptest.NewRecorder()
+
+			// the service is asked for the events the query selects, which
+			// its malformed events are scoped to
+			requestedOptions, _ := helper.ParseEnergyOptions(mockRequest.URL.Query())
+
+			if tt.shouldMockServiceRun {
+				mockService.EXPECT().
+					GetSolarPanelData(tt.requestedUuid, &requestedOptions.EventSelection).
+					Return(tt.mockServiceResponseData, tt.mockServiceResponseError)
+			}
+
+			if tt.shouldMockCalculatorRun {
+				mockCalculator.EXPECT().
+					CalculateEnergy(tt.mockServiceResponseData, tt.expectedEnergyOptions).
+					Return(tt.mockCalculatorResponseData, tt.mockCalculatorResponseError)
+			}
+
+			handler := &GetSolarPanelDataEnergyHandler{
+				SolarPanelDataService:          mockService,
+				SolarPanelDataEnergyCalculator: mockCalculator,
+				logger:                         logger,
+			}
+			sut := handler.GetSolarPanelDataEnergyController
+
+			sut(mockResponseRecorder, mockRequest)
+
+			mockResponse := mockResponseRecorder.Result()
+			actual, err := io.ReadAll(mockResponse.Body)
+			if err != nil {
+				t.Errorf("error with response reading: %v", err)
+				return
+			}
+			actualStatusCode := mockResponse.StatusCode
+
+			assert.Equal(t, tt.expected, string(actual))
+			assert.Equal(t, tt.expectedStatusCode, actualStatusCode)
+		})
+	}
+}
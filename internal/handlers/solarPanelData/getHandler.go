@@ -1,58 +1,89 @@
 package solarPanelData
 
 import (
-	"encoding/csv"
+	"bytes"
+	"errors"
 	"github.com/gorilla/mux"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
 	"github.com/loukaspe/solar-panel-data-crud/internal/core/services"
 	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
 	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
 	log "github.com/sirupsen/logrus"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type GetSolarPanelDataHandler struct {
-	SolarPanelDataService        services.SolarPanelDataServiceInterface
-	SolarPanelDataEventExtractor helper.SolarPanelDataEventExtractorInterface
-	logger                       *log.Logger
+	SolarPanelDataService  services.SolarPanelDataServiceInterface
+	SolarPanelDataEncoders *helper.SolarPanelDataEncoders
+	logger                 *log.Logger
 }
 
 func NewGetSolarPanelDataHandler(
 	service *services.SolarPanelDataService,
-	extractor *helper.SolarPanelDataEventExtractor,
+	encoders *helper.SolarPanelDataEncoders,
 	logger *log.Logger,
 ) *GetSolarPanelDataHandler {
 	return &GetSolarPanelDataHandler{
-		SolarPanelDataService:        service,
-		SolarPanelDataEventExtractor: extractor,
-		logger:                       logger,
+		SolarPanelDataService:  service,
+		SolarPanelDataEncoders: encoders,
+		logger:                 logger,
 	}
 }
 
 func (handler *GetSolarPanelDataHandler) GetSolarPanelDataController(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Vary", "Accept")
 
-	csvWriter := csv.NewWriter(w)
-
-	response := &GetSolarPanelDataResponse{}
+	mediaType, encoder, err := handler.SolarPanelDataEncoders.Negotiate(
+		r.Header.Get("Accept"),
+		r.URL.Query().Get("format"),
+	)
+	if notAcceptableError, ok := err.(apierrors.NotAcceptableError); ok {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(notAcceptableError.ReturnedStatusCode)
 
-	dataUuid := mux.Vars(r)["id"]
-	if dataUuid == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		err := csvWriter.WriteAll([][]string{{"missing solarPanelData id"}})
+		_, err = w.Write([]byte(notAcceptableError.Error()))
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-
 			handler.logger.WithFields(log.Fields{
 				"errorMessage": err.Error(),
 			}).Error("Error in getting solar panel data")
-
-			return
 		}
 
 		return
 	}
 
-	solarPanelData, err := handler.SolarPanelDataService.GetSolarPanelData(dataUuid)
+	w.Header().Set("Content-Type", mediaType)
+
+	dataUuid := mux.Vars(r)["id"]
+	if dataUuid == "" {
+		handler.writeError(w, encoder, http.StatusBadRequest, "missing solarPanelData id")
+
+		return
+	}
+
+	exportOptions, err := helper.ParseExportOptions(r.URL.Query())
+	if err != nil {
+		handler.writeError(w, encoder, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	strict, err := parseStrict(r.URL.Query())
+	if err != nil {
+		handler.writeError(w, encoder, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	getSolarPanelData := handler.SolarPanelDataService.GetSolarPanelData
+	if !strict {
+		getSolarPanelData = handler.SolarPanelDataService.GetSolarPanelDataSkippingMalformedEvents
+	}
+
+	solarPanelData, err := getSolarPanelData(dataUuid, &exportOptions.EventSelection)
 	if dataNotFoundErrorWrapper, ok := err.(*apierrors.DataNotFoundErrorWrapper); ok {
 		handler.logger.WithFields(log.Fields{
 			"errorMessage": dataNotFoundErrorWrapper.Unwrap().Error(),
@@ -63,6 +94,16 @@ func (handler *GetSolarPanelDataHandler) GetSolarPanelDataController(w http.Resp
 		return
 	}
 
+	if malformedEventDataError, ok := err.(apierrors.MalformedEventDataError); ok {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": malformedEventDataError.Unwrap().Error(),
+		}).Debug("Error in getting solar panel data")
+
+		handler.writeError(w, encoder, malformedEventDataError.ReturnedStatusCode, malformedEventDataError.Error())
+
+		return
+	}
+
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 
@@ -73,27 +114,13 @@ func (handler *GetSolarPanelDataHandler) GetSolarPanelDataController(w http.Resp
 		return
 	}
 
-	response.SolarPanelDataEvents, err = handler.SolarPanelDataEventExtractor.ExtractEventsPerParameterIdToCsvForm(
-		solarPanelData,
-	)
-
-	if malformedEventDataError, ok := err.(apierrors.MalformedEventDataError); ok {
-		handler.logger.WithFields(log.Fields{
-			"errorMessage": malformedEventDataError.Unwrap().Error(),
-		}).Debug("Error in getting solar panel data")
-
-		w.WriteHeader(malformedEventDataError.ReturnedStatusCode)
-
-		err = csvWriter.WriteAll([][]string{{malformedEventDataError.Error()}})
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-
-			handler.logger.WithFields(log.Fields{
-				"errorMessage": err.Error(),
-			}).Error("Error in getting solar panel data")
+	// encoded in full before anything is written, so that a failing encoder can
+	// still change the status code
+	body := &bytes.Buffer{}
 
-			return
-		}
+	err = encoder.EncodeSolarPanelData(body, solarPanelData, exportOptions)
+	if unknownParametersError, ok := err.(apierrors.UnknownParametersError); ok {
+		handler.writeError(w, encoder, unknownParametersError.ReturnedStatusCode, unknownParametersError.Error())
 
 		return
 	}
@@ -108,10 +135,10 @@ func (handler *GetSolarPanelDataHandler) GetSolarPanelDataController(w http.Resp
 		return
 	}
 
-	err = csvWriter.WriteAll(response.SolarPanelDataEvents)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+	setMetadataHeaders(w, solarPanelData)
 
+	_, err = body.WriteTo(w)
+	if err != nil {
 		handler.logger.WithFields(log.Fields{
 			"errorMessage": err.Error(),
 		}).Error("Error in getting solar panel data")
@@ -119,3 +146,71 @@ func (handler *GetSolarPanelDataHandler) GetSolarPanelDataController(w http.Resp
 		return
 	}
 }
+
+func (handler *GetSolarPanelDataHandler) writeError(
+	w http.ResponseWriter,
+	encoder helper.SolarPanelDataEncoderInterface,
+	statusCode int,
+	message string,
+) {
+	w.WriteHeader(statusCode)
+
+	err := encoder.EncodeError(w, message)
+	if err != nil {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error("Error in getting solar panel data")
+	}
+}
+
+// parseStrict reads whether a dataset with malformed events is rejected, as it
+// is by default, or exported without them.
+func parseStrict(query url.Values) (bool, error) {
+	switch query.Get("strict") {
+	case "", "true":
+		return true, nil
+	case "false":
+		return false, nil
+	default:
+		return false, errors.New("strict must be one of true, false")
+	}
+}
+
+// setMetadataHeaders exposes the dataset metadata next to the csv body, which
+// has no room for it, along with the malformed events left out of it. The
+// service only returns the malformed events of the exported selection, and the
+// ones of a source malformed as a whole have no parameter id to list.
+func setMetadataHeaders(w http.ResponseWriter, solarPanelData *domain.SolarPanelData) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(solarPanelData.Version)+`"`)
+
+	if !solarPanelData.UpdatedAt.IsZero() {
+		w.Header().Set("Last-Modified", solarPanelData.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
+
+	if !solarPanelData.CreatedAt.IsZero() {
+		w.Header().Set("X-Solar-Panel-Data-Created-At", solarPanelData.CreatedAt.UTC().Format(time.RFC3339))
+	}
+
+	if len(solarPanelData.MalformedEvents) > 0 {
+		var skippedParameters []string
+		seen := map[string]bool{}
+
+		for _, malformedEvent := range solarPanelData.MalformedEvents {
+			if malformedEvent.ParameterId == "" {
+				continue
+			}
+
+			skippedParameter := string(malformedEvent.Source) + ":" + malformedEvent.ParameterId
+			if !seen[skippedParameter] {
+				seen[skippedParameter] = true
+				skippedParameters = append(skippedParameters, skippedParameter)
+			}
+		}
+
+		w.Header().Set("X-Solar-Panel-Data-Skipped-Events", strconv.Itoa(len(solarPanelData.MalformedEvents)))
+
+		if len(skippedParameters) > 0 {
+			w.Header().Set("X-Solar-Panel-Data-Skipped-Parameters", strings.Join(skippedParameters, ","))
+		}
+	}
+}
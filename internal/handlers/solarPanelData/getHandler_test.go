@@ -8,12 +8,14 @@ import (
 	mock_services "github.com/loukaspe/solar-panel-data-crud/mocks/mock_internal/core/services"
 	mock_helper "github.com/loukaspe/solar-panel-data-crud/mocks/mock_pkg/helper"
 	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestGetSolarPanelDataHandler_GetSolarPanelDataController(t *testing.T) {
@@ -27,29 +29,41 @@ func TestGetSolarPanelDataHandler_GetSolarPanelDataController(t *testing.T) {
 	tests := []struct {
 		name                            string
 		requestedUuid                   string
+		requestQuery                    string
+		requestAccept                   string
 		shouldMockServiceRun            bool
+		shouldMockLenientServiceRun     bool
 		mockServiceResponseData         *domain.SolarPanelData
 		mockServiceResponseError        error
 		shouldMockEventExtractorRun     bool
+		expectedExportOptions           *helper.ExportOptions
 		mockEventExtractorResponseData  [][]string
 		mockEventExtractorResponseError error
 		expected                        string
 		expectedStatusCode              int
+		expectedHeaders                 map[string]string
 	}{
 		{
 			name:                 "valid",
 			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
 			shouldMockServiceRun: true,
 			mockServiceResponseData: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"38d503e5-dc1c-4549-8172-09d9c29070f7": [][]string{
-						{"20211231T221500Z", "0.0"},
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
 					},
 				},
 				Wind: nil,
 			},
 			mockServiceResponseError:    nil,
 			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
 			mockEventExtractorResponseData: [][]string{
 				{"Events"},
 				{"0.0"},
@@ -57,9 +71,534 @@ func TestGetSolarPanelDataHandler_GetSolarPanelDataController(t *testing.T) {
 			mockEventExtractorResponseError: nil,
 			expected: `Events
 0.0
+`,
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"Content-Type": "text/csv",
+				"Vary":         "Accept",
+			},
+		},
+		{
+			name:                 "valid with metadata",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+					},
+				},
+				Wind:      nil,
+				Name:      "roof",
+				Site:      "athens",
+				Tags:      []string{"south", "inverter-a"},
+				CreatedAt: time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2022, 1, 2, 6, 0, 0, 0, time.UTC),
+				Version:   3,
+			},
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEventExtractorResponseData: [][]string{
+				{"Events"},
+				{"0.0"},
+			},
+			mockEventExtractorResponseError: nil,
+			expected: `Events
+0.0
+`,
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"ETag":                          `"3"`,
+				"Last-Modified":                 "Sun, 02 Jan 2022 06:00:00 GMT",
+				"X-Solar-Panel-Data-Created-At": "2022-01-01T06:00:00Z",
+				// free-form text is left out, as it could break the headers
+				"X-Solar-Panel-Data-Name": "",
+				"X-Solar-Panel-Data-Site": "",
+				"X-Solar-Panel-Data-Tags": "",
+			},
+		},
+		{
+			name:                 "valid wind source",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:         "?source=wind",
+			shouldMockServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{},
+				Wind: map[string][]domain.Event{
+					"c078ff68-04fb-11e9-a615-42010afa015a": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 12.5},
+					},
+				},
+			},
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceWind,
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEventExtractorResponseData: [][]string{
+				{"Events"},
+				{"12.5"},
+			},
+			mockEventExtractorResponseError: nil,
+			expected: `Events
+12.5
+`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:                 "valid json",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestAccept:        "application/json",
+			shouldMockServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+					},
+				},
+				Wind: nil,
+				Name: "roof",
+			},
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: false,
+			expected: `{"solar":{"38d503e5-dc1c-4549-8172-09d9c29070f7":[["20211231T221500Z","0"]]},"name":"roof"}
+`,
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"Content-Type": "application/json",
+			},
+		},
+		{
+			name:                 "valid ndjson by format over accept",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:         "?format=ndjson&source=all",
+			requestAccept:        "text/csv",
+			shouldMockServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+					},
+				},
+				Wind: map[string][]domain.Event{
+					"c078ff68-04fb-11e9-a615-42010afa015a": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 12.5},
+					},
+				},
+			},
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: false,
+			expected: `{"source":"solar","parameterId":"38d503e5-dc1c-4549-8172-09d9c29070f7","timestamp":"20211231T221500Z","value":0}
+{"source":"wind","parameterId":"c078ff68-04fb-11e9-a615-42010afa015a","timestamp":"20211231T221500Z","value":12.5}
+`,
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"Content-Type": "application/x-ndjson",
+			},
+		},
+		{
+			name:                 "valid accept wildcard",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestAccept:        "application/xml, */*;q=0.1",
+			shouldMockServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{},
+			},
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEventExtractorResponseData: [][]string{
+				{"Events"},
+			},
+			mockEventExtractorResponseError: nil,
+			expected: `Events
+`,
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"Content-Type": "text/csv",
+			},
+		},
+		{
+			name:                        "invalid accept",
+			requestedUuid:               "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestAccept:               "application/xml",
+			shouldMockServiceRun:        false,
+			shouldMockEventExtractorRun: false,
+			expected:                    "none of the requested media types is supported, use one of text/csv, application/json, application/x-ndjson",
+			expectedStatusCode:          406,
+		},
+		{
+			name:                        "invalid format",
+			requestedUuid:               "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:                "?format=xml",
+			shouldMockServiceRun:        false,
+			shouldMockEventExtractorRun: false,
+			expected:                    "none of the requested media types is supported, use one of text/csv, application/json, application/x-ndjson",
+			expectedStatusCode:          406,
+		},
+		{
+			name:                        "invalid source as json",
+			requestedUuid:               "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:                "?source=tidal&format=json",
+			shouldMockServiceRun:        false,
+			shouldMockEventExtractorRun: false,
+			expected: `{"errorMessage":"source must be one of solar, wind, all"}
+`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:                        "invalid source",
+			requestedUuid:               "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:                "?source=tidal",
+			shouldMockServiceRun:        false,
+			shouldMockEventExtractorRun: false,
+			expected: `"source must be one of solar, wind, all"
+`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:                 "valid long layout",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:         "?layout=long&columns=timestamp,value",
+			shouldMockServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+					},
+				},
+			},
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout:  helper.ExportLayoutLong,
+				Columns: []string{helper.ExportColumnTimestamp, helper.ExportColumnValue},
+				Sort:    helper.ExportSortParameterId,
+			},
+			mockEventExtractorResponseData: [][]string{
+				{"timestamp", "value"},
+				{"20211231T221500Z", "0"},
+			},
+			mockEventExtractorResponseError: nil,
+			expected: `timestamp,value
+20211231T221500Z,0
+`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:                 "valid wide layout",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:         "?layout=wide",
+			shouldMockServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+					},
+				},
+			},
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout: helper.ExportLayoutWide,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEventExtractorResponseData: [][]string{
+				{"timestamp", "38d503e5-dc1c-4549-8172-09d9c29070f7"},
+				{"20211231T221500Z", "0"},
+			},
+			mockEventExtractorResponseError: nil,
+			expected: `timestamp,38d503e5-dc1c-4549-8172-09d9c29070f7
+20211231T221500Z,0
+`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:                 "valid time window",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:         "?from=20211231T220000Z&to=2022-01-01T00:00:00Z",
+			shouldMockServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+					},
+				},
+			},
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+					From:   timePointer(time.Date(2021, 12, 31, 22, 0, 0, 0, time.UTC)),
+					To:     timePointer(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)),
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEventExtractorResponseData: [][]string{
+				{"Events"},
+				{"0"},
+			},
+			mockEventExtractorResponseError: nil,
+			expected: `Events
+0
+`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:                        "invalid time window",
+			requestedUuid:               "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:                "?from=20220101T000000Z&to=20211231T220000Z",
+			shouldMockServiceRun:        false,
+			shouldMockEventExtractorRun: false,
+			expected: `from must be before to
+`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:                 "valid parameters",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:         "?parameter=38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+					},
+				},
+			},
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceSolar,
+					Parameters: []string{"38d503e5-dc1c-4549-8172-09d9c29070f7"},
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEventExtractorResponseData: [][]string{
+				{"Events"},
+				{"0"},
+			},
+			mockEventExtractorResponseError: nil,
+			expected: `Events
+0
+`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:                 "invalid unknown parameters",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:         "?parameters=51df2e4c-2002-11ea-95a5-525400b2701a,c078ff68-04fb-11e9-a615-42010afa015a",
+			shouldMockServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+					},
+				},
+			},
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceSolar,
+					Parameters: []string{"51df2e4c-2002-11ea-95a5-525400b2701a", "c078ff68-04fb-11e9-a615-42010afa015a"},
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEventExtractorResponseData: nil,
+			mockEventExtractorResponseError: apierrors.UnknownParametersError{
+				ReturnedStatusCode: http.StatusNotFound,
+				ParameterIds:       []string{"51df2e4c-2002-11ea-95a5-525400b2701a", "c078ff68-04fb-11e9-a615-42010afa015a"},
+			},
+			expected: `"unknown parameters 51df2e4c-2002-11ea-95a5-525400b2701a, c078ff68-04fb-11e9-a615-42010afa015a"
+`,
+			expectedStatusCode: 404,
+		},
+		{
+			name:                 "valid aggregation",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:         "?layout=wide&interval=1h&agg=max",
+			shouldMockServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+					},
+				},
+			},
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout:      helper.ExportLayoutWide,
+				Sort:        helper.ExportSortParameterId,
+				Interval:    time.Hour,
+				Aggregation: helper.ExportAggregationMax,
+				Location:    time.UTC,
+				Fill:        helper.ExportFillNone,
+			},
+			mockEventExtractorResponseData: [][]string{
+				{"timestamp", "38d503e5-dc1c-4549-8172-09d9c29070f7"},
+				{"20211231T220000Z", "0"},
+			},
+			mockEventExtractorResponseError: nil,
+			expected: `timestamp,38d503e5-dc1c-4549-8172-09d9c29070f7
+20211231T220000Z,0
+`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:                 "valid fill",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:         "?layout=long&interval=1h&fill=linear",
+			shouldMockServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+					},
+				},
+			},
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout: helper.ExportLayoutLong,
+				Columns: []string{
+					helper.ExportColumnParameterId,
+					helper.ExportColumnTimestamp,
+					helper.ExportColumnValue,
+					helper.ExportColumnFilled,
+				},
+				Sort:        helper.ExportSortParameterId,
+				Interval:    time.Hour,
+				Aggregation: helper.ExportAggregationMean,
+				Location:    time.UTC,
+				Fill:        helper.ExportFillLinear,
+			},
+			mockEventExtractorResponseData: [][]string{
+				{"parameterId", "timestamp", "value", "filled"},
+				{"38d503e5-dc1c-4549-8172-09d9c29070f7", "20211231T220000Z", "0", "false"},
+			},
+			mockEventExtractorResponseError: nil,
+			expected: `parameterId,timestamp,value,filled
+38d503e5-dc1c-4549-8172-09d9c29070f7,20211231T220000Z,0,false
+`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:                        "invalid fill without interval",
+			requestedUuid:               "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:                "?fill=zero",
+			shouldMockServiceRun:        false,
+			shouldMockEventExtractorRun: false,
+			expected: `fill can only be chosen with interval
+`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:                        "invalid aggregation",
+			requestedUuid:               "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:                "?interval=1h&agg=median",
+			shouldMockServiceRun:        false,
+			shouldMockEventExtractorRun: false,
+			expected: `"agg must be one of sum, mean, min, max, last, count"
+`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:                        "invalid overflowing interval",
+			requestedUuid:               "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:                "?layout=long&interval=2251799813685224h&fill=previous",
+			shouldMockServiceRun:        false,
+			shouldMockEventExtractorRun: false,
+			expected: `"interval must be a number of minutes or hours that divides a day, e.g. 15m or 1h, or 1d"
+`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:                 "valid timestamp sort",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:         "?sort=timestamp",
+			shouldMockServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+					},
+				},
+			},
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortTimestamp,
+			},
+			mockEventExtractorResponseData: [][]string{
+				{"Events"},
+				{"0"},
+			},
+			mockEventExtractorResponseError: nil,
+			expected: `Events
+0
 `,
 			expectedStatusCode: 200,
 		},
+		{
+			name:                        "invalid sort",
+			requestedUuid:               "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:                "?sort=value",
+			shouldMockServiceRun:        false,
+			shouldMockEventExtractorRun: false,
+			expected: `"sort must be one of parameterId, timestamp"
+`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:                        "invalid columns",
+			requestedUuid:               "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:                "?layout=long&columns=timestamp,unit",
+			shouldMockServiceRun:        false,
+			shouldMockEventExtractorRun: false,
+			expected: `"columns must be a comma separated list of source, parameterId, timestamp, value, filled"
+`,
+			expectedStatusCode: 400,
+		},
 		{
 			name:                        "missing id",
 			requestedUuid:               "",
@@ -74,9 +613,9 @@ func TestGetSolarPanelDataHandler_GetSolarPanelDataController(t *testing.T) {
 			requestedUuid:        "aaaaaa",
 			shouldMockServiceRun: true,
 			mockServiceResponseData: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"38d503e5-dc1c-4549-8172-09d9c29070f7": [][]string{
-						{"20211231T221500Z", "0.0"},
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
 					},
 				},
 				Wind: nil,
@@ -94,9 +633,9 @@ func TestGetSolarPanelDataHandler_GetSolarPanelDataController(t *testing.T) {
 			requestedUuid:        "aaaaaa",
 			shouldMockServiceRun: true,
 			mockServiceResponseData: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"38d503e5-dc1c-4549-8172-09d9c29070f7": [][]string{
-						{"20211231T221500Z", "0.0"},
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
 					},
 				},
 				Wind: nil,
@@ -107,42 +646,144 @@ func TestGetSolarPanelDataHandler_GetSolarPanelDataController(t *testing.T) {
 			expectedStatusCode:          500,
 		},
 		{
-			name:                 "invalid malformed event data error",
-			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
-			shouldMockServiceRun: true,
+			name:                    "invalid malformed event data error",
+			requestedUuid:           "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:    true,
+			mockServiceResponseData: &domain.SolarPanelData{},
+			mockServiceResponseError: apierrors.MalformedEventDataError{
+				ReturnedStatusCode:   http.StatusInternalServerError,
+				MalformedParameterId: "38d503e5-dc1c-4549-8172-09d9c29070f7",
+				OriginalError:        errors.New("event with no timestamp or value"),
+			},
+			shouldMockEventExtractorRun: false,
+			expected:                    "\"malformed solar panel data, check parameter 38d503e5-dc1c-4549-8172-09d9c29070f7\"\n",
+			expectedStatusCode:          500,
+		},
+		{
+			name:                        "valid lenient with skipped malformed events",
+			requestedUuid:               "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:                "?strict=false&source=all",
+			shouldMockLenientServiceRun: true,
 			mockServiceResponseData: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"38d503e5-dc1c-4549-8172-09d9c29070f7": [][]string{
-						{"20211231T221500Z"},
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+					},
+				},
+				Wind: map[string][]domain.Event{
+					"c078ff68-04fb-11e9-a615-42010afa015a": []domain.Event{},
+				},
+				MalformedEvents: []domain.MalformedEvent{
+					{
+						Source:        domain.EventSourceSolar,
+						ParameterId:   "38d503e5-dc1c-4549-8172-09d9c29070f7",
+						Position:      1,
+						OriginalError: errors.New("event with no timestamp or value"),
+					},
+					{
+						Source:        domain.EventSourceSolar,
+						ParameterId:   "38d503e5-dc1c-4549-8172-09d9c29070f7",
+						Position:      2,
+						OriginalError: errors.New("event with no timestamp or value"),
+					},
+					{
+						Source:        domain.EventSourceWind,
+						ParameterId:   "c078ff68-04fb-11e9-a615-42010afa015a",
+						Position:      0,
+						OriginalError: errors.New("must be a [timestamp, value] pair"),
 					},
 				},
-				Wind: nil,
 			},
-			mockServiceResponseError:       nil,
-			shouldMockEventExtractorRun:    true,
-			mockEventExtractorResponseData: [][]string{},
-			mockEventExtractorResponseError: apierrors.MalformedEventDataError{
-				ReturnedStatusCode:   http.StatusInternalServerError,
-				MalformedParameterId: "38d503e5-dc1c-4549-8172-09d9c29070f7",
-				OriginalError:        errors.New("parameterId 38d503e5-dc1c-4549-8172-09d9c29070f7 contains events with no values"),
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceAll,
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEventExtractorResponseData: [][]string{
+				{"Events"},
+				{"0.0"},
+			},
+			mockEventExtractorResponseError: nil,
+			expected: `Events
+0.0
+`,
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"X-Solar-Panel-Data-Skipped-Events": "3",
+				"X-Solar-Panel-Data-Skipped-Parameters": "solar:38d503e5-dc1c-4549-8172-09d9c29070f7," +
+					"wind:c078ff68-04fb-11e9-a615-42010afa015a",
+			},
+		},
+		{
+			name:                        "valid lenient with wind malformed as a whole",
+			requestedUuid:               "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:                "?strict=false&source=wind",
+			shouldMockLenientServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{},
+				MalformedEvents: []domain.MalformedEvent{
+					{
+						Source:        domain.EventSourceWind,
+						OriginalError: errors.New("invalid character 'x' looking for beginning of value"),
+					},
+				},
+			},
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceWind,
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEventExtractorResponseData: [][]string{
+				{"Events"},
+			},
+			mockEventExtractorResponseError: nil,
+			expected: `Events
+`,
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"X-Solar-Panel-Data-Skipped-Events":     "1",
+				"X-Solar-Panel-Data-Skipped-Parameters": "",
 			},
-			expected:           "\"malformed solar panel data, check parameter 38d503e5-dc1c-4549-8172-09d9c29070f7\"\n",
-			expectedStatusCode: 500,
+		},
+		{
+			name:                        "invalid strict",
+			requestedUuid:               "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:                "?strict=no",
+			shouldMockServiceRun:        false,
+			shouldMockEventExtractorRun: false,
+			expected: `"strict must be one of true, false"
+`,
+			expectedStatusCode: 400,
 		},
 		{
 			name:                 "invalid extractor random error",
 			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
 			shouldMockServiceRun: true,
 			mockServiceResponseData: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"38d503e5-dc1c-4549-8172-09d9c29070f7": [][]string{
-						{"20211231T221500Z"},
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
 					},
 				},
 				Wind: nil,
 			},
-			mockServiceResponseError:        nil,
-			shouldMockEventExtractorRun:     true,
+			mockServiceResponseError:    nil,
+			shouldMockEventExtractorRun: true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
 			mockEventExtractorResponseData:  [][]string{},
 			mockEventExtractorResponseError: errors.New("random error"),
 			expected:                        ``,
@@ -154,31 +795,49 @@ func TestGetSolarPanelDataHandler_GetSolarPanelDataController(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRequest := httptest.NewRequest(
 				"GET",
-				"/solarPanelData",
+				"/solarPanelData"+tt.requestQuery,
 				nil,
 			)
 			vars := map[string]string{
 				"id": tt.requestedUuid,
 			}
 			mockRequest = mux.SetURLVars(mockRequest, vars)
+			if tt.requestAccept != "" {
+				mockRequest.Header.Set("Accept", tt.requestAccept)
+			}
 			mockResponseRecorder := httptest.NewRecorder()
 
+			// the service is asked for the events the query selects, which
+			// its malformed events are scoped to
+			requestedOptions, _ := helper.ParseExportOptions(mockRequest.URL.Query())
+
 			if tt.shouldMockServiceRun {
 				mockService.EXPECT().
-					GetSolarPanelData(tt.requestedUuid).
+					GetSolarPanelData(tt.requestedUuid, &requestedOptions.EventSelection).
+					Return(tt.mockServiceResponseData, tt.mockServiceResponseError)
+			}
+
+			if tt.shouldMockLenientServiceRun {
+				mockService.EXPECT().
+					GetSolarPanelDataSkippingMalformedEvents(tt.requestedUuid, &requestedOptions.EventSelection).
 					Return(tt.mockServiceResponseData, tt.mockServiceResponseError)
 			}
 
 			if tt.shouldMockEventExtractorRun {
 				mockEventExtractor.EXPECT().
-					ExtractEventsPerParameterIdToCsvForm(tt.mockServiceResponseData).
+					ExtractEventsPerParameterIdToCsvForm(tt.mockServiceResponseData, tt.expectedExportOptions).
 					Return(tt.mockEventExtractorResponseData, tt.mockEventExtractorResponseError)
 			}
 
+			encoders := helper.NewSolarPanelDataEncoders()
+			encoders.Register("csv", helper.MediaTypeCsv, helper.NewSolarPanelDataCsvEncoder(mockEventExtractor))
+			encoders.Register("json", helper.MediaTypeJson, helper.NewSolarPanelDataJsonEncoder())
+			encoders.Register("ndjson", helper.MediaTypeNdjson, helper.NewSolarPanelDataNdjsonEncoder())
+
 			handler := &GetSolarPanelDataHandler{
-				SolarPanelDataService:        mockService,
-				SolarPanelDataEventExtractor: mockEventExtractor,
-				logger:                       logger,
+				SolarPanelDataService:  mockService,
+				SolarPanelDataEncoders: encoders,
+				logger:                 logger,
 			}
 			sut := handler.GetSolarPanelDataController
 
@@ -194,6 +853,14 @@ func TestGetSolarPanelDataHandler_GetSolarPanelDataController(t *testing.T) {
 
 			assert.Equal(t, tt.expected, string(actual))
 			assert.Equal(t, tt.expectedStatusCode, actualStatusCode)
+
+			for header, expectedValue := range tt.expectedHeaders {
+				assert.Equal(t, expectedValue, mockResponse.Header.Get(header))
+			}
 		})
 	}
 }
+
+func timePointer(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,178 @@
+package solarPanelData
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/services"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+
+	sortByCreatedAtAscending  = "createdAt"
+	sortByCreatedAtDescending = "-createdAt"
+)
+
+type ListSolarPanelDataHandler struct {
+	SolarPanelDataService services.SolarPanelDataServiceInterface
+	logger                *log.Logger
+}
+
+func NewListSolarPanelDataHandler(
+	service *services.SolarPanelDataService,
+	logger *log.Logger,
+) *ListSolarPanelDataHandler {
+	return &ListSolarPanelDataHandler{
+		SolarPanelDataService: service,
+		logger:                logger,
+	}
+}
+
+func (handler *ListSolarPanelDataHandler) ListSolarPanelDataController(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	options, err := parseListOptions(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		err = json.NewEncoder(w).Encode(&ErrorResponse{ErrorMessage: err.Error()})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			handler.logger.WithFields(log.Fields{
+				"errorMessage": err.Error(),
+			}).Error("Error in listing solar panel data")
+
+			return
+		}
+
+		return
+	}
+
+	page, err := handler.SolarPanelDataService.ListSolarPanelData(options)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error("Error in listing solar panel data")
+
+		return
+	}
+
+	response := &ListSolarPanelDataResponse{
+		Items: make([]SolarPanelDataSummaryDto, 0, len(page.Items)),
+	}
+
+	for _, summary := range page.Items {
+		response.Items = append(response.Items, SolarPanelDataSummaryDto{
+			Id:             summary.Id,
+			Name:           summary.Name,
+			Site:           summary.Site,
+			Tags:           summary.Tags,
+			CreatedAt:      summary.CreatedAt,
+			UpdatedAt:      summary.UpdatedAt,
+			Version:        summary.Version,
+			ParameterCount: summary.ParameterCount,
+			EventCount:     summary.EventCount,
+		})
+	}
+
+	if page.HasMore && len(page.Items) > 0 {
+		lastSummary := page.Items[len(page.Items)-1]
+		response.NextCursor = encodeListCursor(&domain.SolarPanelDataListCursor{
+			CreatedAt: lastSummary.CreatedAt,
+			Id:        lastSummary.Id,
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error("Error in listing solar panel data")
+
+		return
+	}
+}
+
+func parseListOptions(r *http.Request) (*domain.SolarPanelDataListOptions, error) {
+	query := r.URL.Query()
+
+	options := &domain.SolarPanelDataListOptions{
+		Limit: defaultListLimit,
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		parsedLimit, err := strconv.Atoi(limit)
+		if err != nil || parsedLimit < 1 || parsedLimit > maxListLimit {
+			return nil, errors.New("limit must be a number between 1 and " + strconv.Itoa(maxListLimit))
+		}
+
+		options.Limit = parsedLimit
+	}
+
+	switch query.Get("sort") {
+	case "", sortByCreatedAtAscending:
+		options.Descending = false
+	case sortByCreatedAtDescending:
+		options.Descending = true
+	default:
+		return nil, errors.New("sort must be one of " + sortByCreatedAtAscending + ", " + sortByCreatedAtDescending)
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		after, err := decodeListCursor(cursor)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+
+		options.After = after
+	}
+
+	options.Name = query.Get("name")
+	options.Site = query.Get("site")
+	options.Tags = query["tag"]
+
+	return options, nil
+}
+
+// encodeListCursor turns the position of the last dataset of a page into an
+// opaque token the client sends back to get the next page.
+func encodeListCursor(cursor *domain.SolarPanelDataListCursor) string {
+	return base64.RawURLEncoding.EncodeToString(
+		[]byte(strconv.FormatInt(cursor.CreatedAt.UnixNano(), 10) + ":" + cursor.Id),
+	)
+}
+
+func decodeListCursor(encodedCursor string) (*domain.SolarPanelDataListCursor, error) {
+	decodedCursor, err := base64.RawURLEncoding.DecodeString(encodedCursor)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt, id, found := strings.Cut(string(decodedCursor), ":")
+	if !found {
+		return nil, errors.New("malformed cursor " + encodedCursor)
+	}
+
+	createdAtNanoseconds, err := strconv.ParseInt(createdAt, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SolarPanelDataListCursor{
+		CreatedAt: time.Unix(0, createdAtNanoseconds).UTC(),
+		Id:        id,
+	}, nil
+}
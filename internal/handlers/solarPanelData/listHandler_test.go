@@ -0,0 +1,187 @@
+package solarPanelData
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	mock_services "github.com/loukaspe/solar-panel-data-crud/mocks/mock_internal/core/services"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListSolarPanelDataHandler_ListSolarPanelDataController(t *testing.T) {
+	logger := logrus.New()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockService := mock_services.NewMockSolarPanelDataServiceInterface(mockCtrl)
+
+	createdAt := time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC)
+	cursor := encodeListCursor(&domain.SolarPanelDataListCursor{CreatedAt: createdAt, Id: "uuid1"})
+
+	tests := []struct {
+		name                     string
+		requestUrl               string
+		shouldMockServiceRun     bool
+		expectedListOptions      *domain.SolarPanelDataListOptions
+		mockServiceResponseData  *domain.SolarPanelDataPage
+		mockServiceResponseError error
+		expected                 []byte
+		expectedStatusCode       int
+	}{
+		{
+			name:                 "valid default options",
+			requestUrl:           "/solar-panel-data",
+			shouldMockServiceRun: true,
+			expectedListOptions:  &domain.SolarPanelDataListOptions{Limit: 20},
+			mockServiceResponseData: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{
+					{
+						Id:             "uuid1",
+						CreatedAt:      createdAt,
+						UpdatedAt:      createdAt,
+						Version:        1,
+						ParameterCount: 1,
+						EventCount:     2,
+					},
+				},
+			},
+			expected: json.RawMessage(`{"items":[{"id":"uuid1","createdAt":"2022-01-01T06:00:00Z","updatedAt":"2022-01-01T06:00:00Z","version":1,"parameterCount":1,"eventCount":2}]}
+`),
+			expectedStatusCode: 200,
+		},
+		{
+			name:                 "valid with next page",
+			requestUrl:           "/solar-panel-data?limit=1&sort=-createdAt",
+			shouldMockServiceRun: true,
+			expectedListOptions:  &domain.SolarPanelDataListOptions{Limit: 1, Descending: true},
+			mockServiceResponseData: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{
+					{
+						Id:        "uuid1",
+						CreatedAt: createdAt,
+						UpdatedAt: createdAt,
+						Version:   1,
+					},
+				},
+				HasMore: true,
+			},
+			expected: json.RawMessage(`{"items":[{"id":"uuid1","createdAt":"2022-01-01T06:00:00Z","updatedAt":"2022-01-01T06:00:00Z","version":1,"parameterCount":0,"eventCount":0}],"nextCursor":"` + cursor + `"}
+`),
+			expectedStatusCode: 200,
+		},
+		{
+			name:                 "valid with cursor and empty page",
+			requestUrl:           "/solar-panel-data?cursor=" + cursor,
+			shouldMockServiceRun: true,
+			expectedListOptions: &domain.SolarPanelDataListOptions{
+				After: &domain.SolarPanelDataListCursor{CreatedAt: createdAt, Id: "uuid1"},
+				Limit: 20,
+			},
+			mockServiceResponseData: &domain.SolarPanelDataPage{},
+			expected: json.RawMessage(`{"items":[]}
+`),
+			expectedStatusCode: 200,
+		},
+		{
+			name:                 "valid with metadata filters",
+			requestUrl:           "/solar-panel-data?name=roof&site=athens&tag=south&tag=inverter-a",
+			shouldMockServiceRun: true,
+			expectedListOptions: &domain.SolarPanelDataListOptions{
+				Limit: 20,
+				Name:  "roof",
+				Site:  "athens",
+				Tags:  []string{"south", "inverter-a"},
+			},
+			mockServiceResponseData: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{
+					{
+						Id:        "uuid1",
+						Name:      "roof",
+						Site:      "athens",
+						Tags:      []string{"south", "inverter-a"},
+						CreatedAt: createdAt,
+						UpdatedAt: createdAt,
+						Version:   2,
+					},
+				},
+			},
+			expected: json.RawMessage(`{"items":[{"id":"uuid1","name":"roof","site":"athens","tags":["south","inverter-a"],"createdAt":"2022-01-01T06:00:00Z","updatedAt":"2022-01-01T06:00:00Z","version":2,"parameterCount":0,"eventCount":0}]}
+`),
+			expectedStatusCode: 200,
+		},
+		{
+			name:                 "invalid limit",
+			requestUrl:           "/solar-panel-data?limit=1000",
+			shouldMockServiceRun: false,
+			expected: json.RawMessage(`{"errorMessage":"limit must be a number between 1 and 100"}
+`),
+			expectedStatusCode: 400,
+		},
+		{
+			name:                 "invalid sort",
+			requestUrl:           "/solar-panel-data?sort=id",
+			shouldMockServiceRun: false,
+			expected: json.RawMessage(`{"errorMessage":"sort must be one of createdAt, -createdAt"}
+`),
+			expectedStatusCode: 400,
+		},
+		{
+			name:                 "invalid cursor",
+			requestUrl:           "/solar-panel-data?cursor=notacursor",
+			shouldMockServiceRun: false,
+			expected: json.RawMessage(`{"errorMessage":"invalid cursor"}
+`),
+			expectedStatusCode: 400,
+		},
+		{
+			name:                     "invalid service random error",
+			requestUrl:               "/solar-panel-data",
+			shouldMockServiceRun:     true,
+			expectedListOptions:      &domain.SolarPanelDataListOptions{Limit: 20},
+			mockServiceResponseError: errors.New("random error"),
+			expected:                 json.RawMessage(``),
+			expectedStatusCode:       500,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRequest := httptest.NewRequest(
+				"GET",
+				tt.requestUrl,
+				nil,
+			)
+			mockResponseRecorder := httptest.NewRecorder()
+
+			if tt.shouldMockServiceRun {
+				mockService.EXPECT().
+					ListSolarPanelData(tt.expectedListOptions).
+					Return(tt.mockServiceResponseData, tt.mockServiceResponseError)
+			}
+
+			handler := &ListSolarPanelDataHandler{
+				SolarPanelDataService: mockService,
+				logger:                logger,
+			}
+			sut := handler.ListSolarPanelDataController
+
+			sut(mockResponseRecorder, mockRequest)
+
+			mockResponse := mockResponseRecorder.Result()
+			actual, err := io.ReadAll(mockResponse.Body)
+			if err != nil {
+				t.Errorf("error with response reading: %v", err)
+				return
+			}
+			actualStatusCode := mockResponse.StatusCode
+
+			assert.Equal(t, string(tt.expected), string(actual))
+			assert.Equal(t, tt.expectedStatusCode, actualStatusCode)
+		})
+	}
+}
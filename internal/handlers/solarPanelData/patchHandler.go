@@ -0,0 +1,227 @@
+package solarPanelData
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/services"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+)
+
+type PatchSolarPanelDataHandler struct {
+	SolarPanelDataService   services.SolarPanelDataServiceInterface
+	SolarPanelDataValidator helper.SolarPanelDataValidatorInterface
+	logger                  *log.Logger
+}
+
+func NewPatchSolarPanelDataHandler(
+	service *services.SolarPanelDataService,
+	validator *helper.SolarPanelDataValidator,
+	logger *log.Logger,
+) *PatchSolarPanelDataHandler {
+	return &PatchSolarPanelDataHandler{
+		SolarPanelDataService:   service,
+		SolarPanelDataValidator: validator,
+		logger:                  logger,
+	}
+}
+
+// PatchSolarPanelDataController merges the submitted events into the stored
+// dataset, resolving the ones at timestamps it already has an event at with
+// another value by the conflict query parameter.
+func (handler *PatchSolarPanelDataHandler) PatchSolarPanelDataController(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	policy, err := parseMergeConflictPolicy(r.URL.Query())
+	if err != nil {
+		handler.writeError(w, http.StatusBadRequest, &UpdateSolarPanelDataResponse{
+			ErrorMessage: err.Error(),
+		})
+
+		return
+	}
+
+	solarPanelDataRequest := &Dto{}
+
+	err = json.NewDecoder(r.Body).Decode(solarPanelDataRequest)
+	if err != nil {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error("Error in patching solar panel data")
+
+		handler.writeError(w, http.StatusBadRequest, &UpdateSolarPanelDataResponse{
+			ErrorMessage: "malformed solar panel data request",
+		})
+
+		return
+	}
+
+	// unlike a whole dataset, a patch may leave out the solar events
+	solar := solarPanelDataRequest.Solar
+	if solar == nil {
+		solar = map[string][][]string{}
+	}
+
+	err = handler.SolarPanelDataValidator.ValidateSolarPanelData(solar, solarPanelDataRequest.Wind)
+	if validationError, ok := err.(apierrors.ValidationError); ok {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": validationError.Error(),
+		}).Debug("Error in patching solar panel data")
+
+		handler.writeError(w, validationError.ReturnedStatusCode, &UpdateSolarPanelDataResponse{
+			ErrorMessage: validationError.Error(),
+			Violations:   newViolationDtos(validationError.Violations),
+		})
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error("Error in patching solar panel data")
+
+		return
+	}
+
+	domainSolarPanelData, err := solarPanelDataRequest.toDomain()
+	if invalidEventError, ok := err.(apierrors.InvalidEventError); ok {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": invalidEventError.Error(),
+		}).Debug("Error in patching solar panel data")
+
+		handler.writeError(w, invalidEventError.ReturnedStatusCode, &UpdateSolarPanelDataResponse{
+			ErrorMessage: invalidEventError.Error(),
+		})
+
+		return
+	}
+
+	uuid := mux.Vars(r)["id"]
+	if uuid == "" {
+		handler.writeError(w, http.StatusBadRequest, &UpdateSolarPanelDataResponse{
+			ErrorMessage: "missing solarPanelData id",
+		})
+
+		return
+	}
+
+	result, err := handler.SolarPanelDataService.MergeSolarPanelData(uuid, domainSolarPanelData, policy)
+	if mergeConflictError, ok := err.(apierrors.MergeConflictError); ok {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": mergeConflictError.Error(),
+		}).Debug("Error in patching solar panel data")
+
+		handler.writeError(w, mergeConflictError.ReturnedStatusCode, &UpdateSolarPanelDataResponse{
+			ErrorMessage: mergeConflictError.Error(),
+			Violations:   newViolationDtos(mergeConflictError.Violations),
+		})
+
+		return
+	}
+
+	if versionConflictError, ok := err.(apierrors.VersionConflictError); ok {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": versionConflictError.Error(),
+		}).Debug("Error in patching solar panel data")
+
+		handler.writeError(w, versionConflictError.ReturnedStatusCode, &UpdateSolarPanelDataResponse{
+			ErrorMessage: versionConflictError.Error(),
+		})
+
+		return
+	}
+
+	if dataNotFoundErrorWrapper, ok := err.(*apierrors.DataNotFoundErrorWrapper); ok {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": dataNotFoundErrorWrapper.Unwrap().Error(),
+		}).Debug("Error in patching solar panel data")
+
+		w.WriteHeader(dataNotFoundErrorWrapper.ReturnedStatusCode)
+
+		return
+	}
+
+	if malformedEventDataError, ok := err.(apierrors.MalformedEventDataError); ok {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": malformedEventDataError.Unwrap().Error(),
+		}).Debug("Error in patching solar panel data")
+
+		handler.writeError(w, malformedEventDataError.ReturnedStatusCode, &UpdateSolarPanelDataResponse{
+			ErrorMessage: malformedEventDataError.Error(),
+		})
+
+		return
+	}
+
+	if err != nil {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error("Error in patching solar panel data")
+
+		handler.writeError(w, http.StatusInternalServerError, &UpdateSolarPanelDataResponse{
+			ErrorMessage: err.Error(),
+		})
+
+		return
+	}
+
+	response := &PatchSolarPanelDataResponse{
+		Version:           domainSolarPanelData.Version,
+		AppendedEvents:    result.AppendedEvents,
+		OverwrittenEvents: result.OverwrittenEvents,
+		UnchangedEvents:   result.UnchangedEvents,
+		KeptEvents:        result.KeptEvents,
+		AddedParameters:   result.AddedParameters,
+	}
+
+	if !domainSolarPanelData.UpdatedAt.IsZero() {
+		response.UpdatedAt = &domainSolarPanelData.UpdatedAt
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error("Error in patching solar panel data")
+
+		return
+	}
+}
+
+func (handler *PatchSolarPanelDataHandler) writeError(
+	w http.ResponseWriter,
+	statusCode int,
+	response *UpdateSolarPanelDataResponse,
+) {
+	w.WriteHeader(statusCode)
+
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error("Error in patching solar panel data")
+	}
+}
+
+// parseMergeConflictPolicy reads how the events conflicting with stored ones
+// are resolved, rejecting the patch by default so that nothing stored is changed
+// by mistake.
+func parseMergeConflictPolicy(query url.Values) (domain.MergeConflictPolicy, error) {
+	switch policy := domain.MergeConflictPolicy(query.Get("conflict")); policy {
+	case "":
+		return domain.MergeConflictReject, nil
+	case domain.MergeConflictReject, domain.MergeConflictKeep, domain.MergeConflictOverwrite:
+		return policy, nil
+	default:
+		return "", errors.New("conflict must be one of reject, keep, overwrite")
+	}
+}
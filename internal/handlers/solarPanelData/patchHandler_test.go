@@ -0,0 +1,277 @@
+package solarPanelData
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	mock_services "github.com/loukaspe/solar-panel-data-crud/mocks/mock_internal/core/services"
+	mock_helper "github.com/loukaspe/solar-panel-data-crud/mocks/mock_pkg/helper"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPatchSolarPanelDataHandler_PatchSolarPanelDataController(t *testing.T) {
+	logger := logrus.New()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockService := mock_services.NewMockSolarPanelDataServiceInterface(mockCtrl)
+	mockValidator := mock_helper.NewMockSolarPanelDataValidatorInterface(mockCtrl)
+
+	updatedAt := time.Date(2022, 1, 2, 6, 0, 0, 0, time.UTC)
+
+	windRequestBody := json.RawMessage(`{
+  "wind": {
+    "38d503e5-dc1c-4549-8172-09d9c29070f7": [
+      [
+        "20211231T221500Z",
+        "0.0"
+      ]
+    ]
+  }
+}`)
+	windRequestData := &domain.SolarPanelData{
+		Wind: map[string][]domain.Event{
+			"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+				{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		requestBody     []byte
+		requestQuery    string
+		requestedUuid   string
+		mockRequestData *domain.SolarPanelData
+		mockPolicy      domain.MergeConflictPolicy
+		// variables to check if the handler returns error before the mock validator and service run
+		shouldMockValidatorRun     bool
+		mockValidatorResponseError error
+		shouldMockServiceRun       bool
+		mockServiceResponseResult  *domain.MergeResult
+		mockServiceResponseError   error
+		expected                   []byte
+		expectedStatusCode         int
+	}{
+		{
+			name:                   "valid without solar events",
+			requestBody:            windRequestBody,
+			requestedUuid:          "uuid",
+			mockRequestData:        windRequestData,
+			mockPolicy:             domain.MergeConflictReject,
+			shouldMockValidatorRun: true,
+			shouldMockServiceRun:   true,
+			mockServiceResponseResult: &domain.MergeResult{
+				AppendedEvents:  1,
+				AddedParameters: 1,
+			},
+			expected: json.RawMessage(`{"updatedAt":"2022-01-02T06:00:00Z","version":4,"appendedEvents":1,"overwrittenEvents":0,"unchangedEvents":0,"keptEvents":0,"addedParameters":1}
+`),
+			expectedStatusCode: 200,
+		},
+		{
+			name:                   "valid overwrite",
+			requestBody:            windRequestBody,
+			requestQuery:           "?conflict=overwrite",
+			requestedUuid:          "uuid",
+			mockRequestData:        windRequestData,
+			mockPolicy:             domain.MergeConflictOverwrite,
+			shouldMockValidatorRun: true,
+			shouldMockServiceRun:   true,
+			mockServiceResponseResult: &domain.MergeResult{
+				OverwrittenEvents: 1,
+			},
+			expected: json.RawMessage(`{"updatedAt":"2022-01-02T06:00:00Z","version":4,"appendedEvents":0,"overwrittenEvents":1,"unchangedEvents":0,"keptEvents":0,"addedParameters":0}
+`),
+			expectedStatusCode: 200,
+		},
+		{
+			name:         "invalid conflict policy",
+			requestBody:  windRequestBody,
+			requestQuery: "?conflict=merge",
+			expected: json.RawMessage(`{"errorMessage":"conflict must be one of reject, keep, overwrite"}
+`),
+			expectedStatusCode: 400,
+		},
+		{
+			name:          "invalid bad request",
+			requestBody:   json.RawMessage(`{"wind": [`),
+			requestedUuid: "uuid",
+			expected: json.RawMessage(`{"errorMessage":"malformed solar panel data request"}
+`),
+			expectedStatusCode: 400,
+		},
+		{
+			name:                   "validation error",
+			requestBody:            windRequestBody,
+			requestedUuid:          "uuid",
+			shouldMockValidatorRun: true,
+			mockValidatorResponseError: apierrors.ValidationError{
+				ReturnedStatusCode: http.StatusBadRequest,
+				Violations: []apierrors.Violation{
+					{Path: "$.wind", Message: "must have at least one turbine"},
+				},
+			},
+			expected: json.RawMessage(`{"errorMessage":"invalid solar panel data, check violations","violations":[{"path":"$.wind","message":"must have at least one turbine"}]}
+`),
+			expectedStatusCode: 400,
+		},
+		{
+			name:                   "missing id",
+			requestBody:            windRequestBody,
+			shouldMockValidatorRun: true,
+			expected: json.RawMessage(`{"errorMessage":"missing solarPanelData id"}
+`),
+			expectedStatusCode: 400,
+		},
+		{
+			name:                   "merge conflict",
+			requestBody:            windRequestBody,
+			requestedUuid:          "uuid",
+			mockRequestData:        windRequestData,
+			mockPolicy:             domain.MergeConflictReject,
+			shouldMockValidatorRun: true,
+			shouldMockServiceRun:   true,
+			mockServiceResponseError: apierrors.MergeConflictError{
+				ReturnedStatusCode: http.StatusConflict,
+				Violations: []apierrors.Violation{
+					{
+						Path:    `$.wind["38d503e5-dc1c-4549-8172-09d9c29070f7"][0][1]`,
+						Message: "conflicts with the stored value 1.5",
+					},
+				},
+			},
+			expected: json.RawMessage(`{"errorMessage":"solar panel data conflicts with the stored events, check violations","violations":[{"path":"$.wind[\"38d503e5-dc1c-4549-8172-09d9c29070f7\"][0][1]","message":"conflicts with the stored value 1.5"}]}
+`),
+			expectedStatusCode: 409,
+		},
+		{
+			name:                   "version conflict",
+			requestBody:            windRequestBody,
+			requestedUuid:          "uuid",
+			mockRequestData:        windRequestData,
+			mockPolicy:             domain.MergeConflictReject,
+			shouldMockValidatorRun: true,
+			shouldMockServiceRun:   true,
+			mockServiceResponseError: apierrors.VersionConflictError{
+				ReturnedStatusCode: http.StatusConflict,
+				ExpectedVersion:    3,
+			},
+			expected: json.RawMessage(`{"errorMessage":"solar panel data was changed by another request, try again"}
+`),
+			expectedStatusCode: 409,
+		},
+		{
+			name:                   "data not found",
+			requestBody:            windRequestBody,
+			requestedUuid:          "uuid",
+			mockRequestData:        windRequestData,
+			mockPolicy:             domain.MergeConflictReject,
+			shouldMockValidatorRun: true,
+			shouldMockServiceRun:   true,
+			mockServiceResponseError: &apierrors.DataNotFoundErrorWrapper{
+				ReturnedStatusCode: http.StatusNotFound,
+				OriginalError:      errors.New("uuid uuid not found"),
+			},
+			expected:           json.RawMessage(``),
+			expectedStatusCode: 404,
+		},
+		{
+			name:                   "malformed stored events",
+			requestBody:            windRequestBody,
+			requestedUuid:          "uuid",
+			mockRequestData:        windRequestData,
+			mockPolicy:             domain.MergeConflictReject,
+			shouldMockValidatorRun: true,
+			shouldMockServiceRun:   true,
+			mockServiceResponseError: apierrors.MalformedEventDataError{
+				ReturnedStatusCode:   http.StatusInternalServerError,
+				OriginalError:        errors.New("event with no timestamp or value"),
+				MalformedParameterId: "uuid1",
+			},
+			expected: json.RawMessage(`{"errorMessage":"malformed solar panel data, check parameter uuid1"}
+`),
+			expectedStatusCode: 500,
+		},
+		{
+			name:                     "random error",
+			requestBody:              windRequestBody,
+			requestedUuid:            "uuid",
+			mockRequestData:          windRequestData,
+			mockPolicy:               domain.MergeConflictReject,
+			shouldMockValidatorRun:   true,
+			shouldMockServiceRun:     true,
+			mockServiceResponseError: errors.New("random error"),
+			expected: json.RawMessage(`{"errorMessage":"random error"}
+`),
+			expectedStatusCode: 500,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestBodyReader := bytes.NewBuffer(tt.requestBody)
+
+			mockRequest := httptest.NewRequest("PATCH", "/solarPanelData"+tt.requestQuery, requestBodyReader)
+			vars := map[string]string{
+				"id": tt.requestedUuid,
+			}
+			mockRequest = mux.SetURLVars(mockRequest, vars)
+
+			mockRequest.Header.Set("Content-Type", "application/json")
+			mockResponseRecorder := httptest.NewRecorder()
+
+			if tt.shouldMockValidatorRun {
+				mockValidator.EXPECT().
+					ValidateSolarPanelData(map[string][][]string{}, gomock.Any()).
+					Return(tt.mockValidatorResponseError)
+			}
+
+			if tt.shouldMockServiceRun {
+				mockService.EXPECT().
+					MergeSolarPanelData(tt.requestedUuid, tt.mockRequestData, tt.mockPolicy).
+					DoAndReturn(func(
+						uuid string,
+						solarPanelData *domain.SolarPanelData,
+						policy domain.MergeConflictPolicy,
+					) (*domain.MergeResult, error) {
+						if tt.mockServiceResponseError == nil {
+							solarPanelData.UpdatedAt = updatedAt
+							solarPanelData.Version = 4
+						}
+
+						return tt.mockServiceResponseResult, tt.mockServiceResponseError
+					})
+			}
+
+			handler := &PatchSolarPanelDataHandler{
+				SolarPanelDataService:   mockService,
+				SolarPanelDataValidator: mockValidator,
+				logger:                  logger,
+			}
+			sut := handler.PatchSolarPanelDataController
+
+			sut(mockResponseRecorder, mockRequest)
+
+			mockResponse := mockResponseRecorder.Result()
+			actual, err := io.ReadAll(mockResponse.Body)
+			if err != nil {
+				t.Errorf("error with response reading: %v", err)
+				return
+			}
+			actualStatusCode := mockResponse.StatusCode
+
+			assert.Equal(t, string(tt.expected), string(actual))
+			assert.Equal(t, tt.expectedStatusCode, actualStatusCode)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package solarPanelData
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/services"
+	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// GetSolarPanelDataQualityHandler reports the data quality issues of each
+// series of a dataset, along with a score of how many of its readings are fine.
+type GetSolarPanelDataQualityHandler struct {
+	SolarPanelDataService         services.SolarPanelDataServiceInterface
+	SolarPanelDataQualityAnalyzer helper.SolarPanelDataQualityAnalyzerInterface
+	logger                        *log.Logger
+}
+
+func NewGetSolarPanelDataQualityHandler(
+	service *services.SolarPanelDataService,
+	analyzer *helper.SolarPanelDataQualityAnalyzer,
+	logger *log.Logger,
+) *GetSolarPanelDataQualityHandler {
+	return &GetSolarPanelDataQualityHandler{
+		SolarPanelDataService:         service,
+		SolarPanelDataQualityAnalyzer: analyzer,
+		logger:                        logger,
+	}
+}
+
+func (handler *GetSolarPanelDataQualityHandler) GetSolarPanelDataQualityController(w http.ResponseWriter, r *http.Request) {
+	responder := analysisResponder{
+		service:    handler.SolarPanelDataService,
+		logger:     handler.logger,
+		logMessage: "Error in getting solar panel data quality",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	dataUuid := mux.Vars(r)["id"]
+	if dataUuid == "" {
+		responder.writeError(w, http.StatusBadRequest, "missing solarPanelData id")
+
+		return
+	}
+
+	qualityOptions, err := helper.ParseQualityOptions(r.URL.Query())
+	if err != nil {
+		responder.writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	solarPanelData, ok := responder.getSolarPanelData(w, dataUuid, &qualityOptions.EventSelection)
+	if !ok {
+		return
+	}
+
+	quality, err := handler.SolarPanelDataQualityAnalyzer.AnalyzeQuality(solarPanelData, qualityOptions)
+	if err != nil {
+		responder.writeAnalysisError(w, err)
+
+		return
+	}
+
+	response := &GetSolarPanelDataQualityResponse{
+		Parameters: make([]ParameterQualityDto, 0, len(quality)),
+	}
+
+	for _, parameterQuality := range quality {
+		response.Parameters = append(response.Parameters, toParameterQualityDto(parameterQuality))
+	}
+
+	responder.writeResponse(w, response)
+}
@@ -0,0 +1,230 @@
+package solarPanelData
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	mock_services "github.com/loukaspe/solar-panel-data-crud/mocks/mock_internal/core/services"
+	mock_helper "github.com/loukaspe/solar-panel-data-crud/mocks/mock_pkg/helper"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetSolarPanelDataQualityHandler_GetSolarPanelDataQualityController(t *testing.T) {
+	logger := logrus.New()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockService := mock_services.NewMockSolarPanelDataServiceInterface(mockCtrl)
+	mockAnalyzer := mock_helper.NewMockSolarPanelDataQualityAnalyzerInterface(mockCtrl)
+
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC), Value: 0},
+				{Timestamp: time.Date(2022, 1, 1, 7, 0, 0, 0, time.UTC), Value: 4},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                      string
+		requestedUuid             string
+		requestQuery              string
+		shouldMockServiceRun      bool
+		mockServiceResponseData   *domain.SolarPanelData
+		mockServiceResponseError  error
+		shouldMockAnalyzerRun     bool
+		expectedQualityOptions    *helper.QualityOptions
+		mockAnalyzerResponseData  []helper.ParameterQuality
+		mockAnalyzerResponseError error
+		expected                  string
+		expectedStatusCode        int
+	}{
+		{
+			name:                     "valid",
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:             "?flatline=3",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  solarPanelData,
+			mockServiceResponseError: nil,
+			shouldMockAnalyzerRun:    true,
+			expectedQualityOptions: &helper.QualityOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				FlatlineReadings: 3,
+			},
+			mockAnalyzerResponseData: []helper.ParameterQuality{
+				{
+					Source:      domain.EventSourceSolar,
+					ParameterId: "38d503e5-dc1c-4549-8172-09d9c29070f7",
+					Count:       2,
+					Cadence:     15 * time.Minute,
+					MissingIntervals: []helper.MissingInterval{
+						{
+							From:            time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC),
+							To:              time.Date(2022, 1, 1, 7, 0, 0, 0, time.UTC),
+							MissingReadings: 3,
+						},
+					},
+					MissingReadings:     3,
+					DuplicateTimestamps: []time.Time{},
+					Flatlines: []helper.Flatline{
+						{
+							From:     time.Date(2022, 1, 1, 8, 0, 0, 0, time.UTC),
+							To:       time.Date(2022, 1, 1, 8, 30, 0, 0, time.UTC),
+							Value:    4,
+							Readings: 3,
+						},
+					},
+					NegativeReadings: 1,
+					Score:            40,
+				},
+			},
+			mockAnalyzerResponseError: nil,
+			expected: `{"parameters":[{"source":"solar","parameterId":"38d503e5-dc1c-4549-8172-09d9c29070f7","count":2,` +
+				`"cadenceSeconds":900,"missingIntervals":[{"from":"2022-01-01T06:00:00Z","to":"2022-01-01T07:00:00Z",` +
+				`"missingReadings":3}],"missingReadings":3,"duplicateTimestamps":[],"outOfOrderEvents":0,` +
+				`"flatlines":[{"from":"2022-01-01T08:00:00Z","to":"2022-01-01T08:30:00Z","value":4,"readings":3}],` +
+				`"negativeReadings":1,"score":40}]}` + "\n",
+			expectedStatusCode: 200,
+		},
+		{
+			name:                     "unknown parameters",
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:             "?parameters=uuid2",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  solarPanelData,
+			mockServiceResponseError: nil,
+			shouldMockAnalyzerRun:    true,
+			expectedQualityOptions: &helper.QualityOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceSolar,
+					Parameters: []string{"uuid2"},
+				},
+				FlatlineReadings: 4,
+			},
+			mockAnalyzerResponseData: nil,
+			mockAnalyzerResponseError: apierrors.UnknownParametersError{
+				ReturnedStatusCode: http.StatusNotFound,
+				ParameterIds:       []string{"uuid2"},
+			},
+			expected:           `{"errorMessage":"unknown parameters uuid2"}` + "\n",
+			expectedStatusCode: 404,
+		},
+		{
+			name:                  "invalid flatline",
+			requestedUuid:         "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:          "?source=hydro",
+			shouldMockServiceRun:  false,
+			shouldMockAnalyzerRun: false,
+			expected:              `{"errorMessage":"source must be one of solar, wind, all"}` + "\n",
+			expectedStatusCode:    400,
+		},
+		{
+			name:                  "missing id",
+			requestedUuid:         "",
+			shouldMockServiceRun:  false,
+			shouldMockAnalyzerRun: false,
+			expected:              `{"errorMessage":"missing solarPanelData id"}` + "\n",
+			expectedStatusCode:    400,
+		},
+		{
+			name:                    "not found",
+			requestedUuid:           "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:    true,
+			mockServiceResponseData: nil,
+			mockServiceResponseError: &apierrors.DataNotFoundErrorWrapper{
+				ReturnedStatusCode: http.StatusNoContent,
+				OriginalError:      errors.New("solarPanelData not found"),
+			},
+			shouldMockAnalyzerRun: false,
+			expected:              "",
+			expectedStatusCode:    204,
+		},
+		{
+			name:                    "malformed events",
+			requestedUuid:           "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:    true,
+			mockServiceResponseData: nil,
+			mockServiceResponseError: apierrors.MalformedEventDataError{
+				ReturnedStatusCode:   http.StatusInternalServerError,
+				OriginalError:        errors.New("event with no timestamp or value"),
+				MalformedParameterId: "uuid1",
+			},
+			shouldMockAnalyzerRun: false,
+			expected:              `{"errorMessage":"malformed solar panel data, check parameter uuid1"}` + "\n",
+			expectedStatusCode:    500,
+		},
+		{
+			name:                     "random service error",
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  nil,
+			mockServiceResponseError: errors.New("random error"),
+			shouldMockAnalyzerRun:    false,
+			expected:                 "",
+			expectedStatusCode:       500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRequest := httptest.NewRequest(
+				"GET",
+				"/solarPanelData/quality"+tt.requestQuery,
+				nil,
+			)
+			vars := map[string]string{
+				"id": tt.requestedUuid,
+			}
+			mockRequest = mux.SetURLVars(mockRequest, vars)
+			mockResponseRecorder := httptest.NewRecorder()
+
+			// the service is asked for the events the query selects, which
+			// its malformed events are scoped to
+			requestedOptions, _ := helper.ParseQualityOptions(mockRequest.URL.Query())
+
+			if tt.shouldMockServiceRun {
+				mockService.EXPECT().
+					GetSolarPanelData(tt.requestedUuid, &requestedOptions.EventSelection).
+					Return(tt.mockServiceResponseData, tt.mockServiceResponseError)
+			}
+
+			if tt.shouldMockAnalyzerRun {
+				mockAnalyzer.EXPECT().
+					AnalyzeQuality(tt.mockServiceResponseData, tt.expectedQualityOptions).
+					Return(tt.mockAnalyzerResponseData, tt.mockAnalyzerResponseError)
+			}
+
+			handler := &GetSolarPanelDataQualityHandler{
+				SolarPanelDataService:         mockService,
+				SolarPanelDataQualityAnalyzer: mockAnalyzer,
+				logger:                        logger,
+			}
+			sut := handler.GetSolarPanelDataQualityController
+
+			sut(mockResponseRecorder, mockRequest)
+
+			mockResponse := mockResponseRecorder.Result()
+			actual, err := io.ReadAll(mockResponse.Body)
+			if err != nil {
+				t.Errorf("error with response reading: %v", err)
+				return
+			}
+			actualStatusCode := mockResponse.StatusCode
+
+			assert.Equal(t, tt.expected, string(actual))
+			assert.Equal(t, tt.expectedStatusCode, actualStatusCode)
+		})
+	}
+}
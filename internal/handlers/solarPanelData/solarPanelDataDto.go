@@ -1,18 +1,106 @@
 package solarPanelData
 
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+	"net/http"
+	"sort"
+	"time"
+)
+
 type Dto struct {
 	Solar map[string][][]string `json:"solar"`
-	Wind  interface{}           `json:"wind"`
+	Wind  map[string][][]string `json:"wind"`
+	Name  string                `json:"name,omitempty"`
+	Site  string                `json:"site,omitempty"`
+	Tags  []string              `json:"tags,omitempty"`
 }
 
-type CreateSolarPanelDataResponse struct {
-	InsertedId    string `json:"id,omitempty"`
-	DataSubmitted *Dto   `json:"dataSubmitted,omitempty"`
-	ErrorMessage  string `json:"errorMessage,omitempty"`
+// toDomain parses the submitted events, rejecting the first one that is not a
+// valid [timestamp, value] pair.
+func (dto *Dto) toDomain() (*domain.SolarPanelData, error) {
+	solar, err := parseEvents(dto.Solar)
+	if err != nil {
+		return nil, err
+	}
+
+	wind, err := parseEvents(dto.Wind)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SolarPanelData{
+		Solar: solar,
+		Wind:  wind,
+		Name:  dto.Name,
+		Site:  dto.Site,
+		Tags:  dto.Tags,
+	}, nil
+}
+
+func parseEvents(eventsPerParameterId map[string][][]string) (map[string][]domain.Event, error) {
+	if eventsPerParameterId == nil {
+		return nil, nil
+	}
+
+	parameterIds := make([]string, 0, len(eventsPerParameterId))
+	for parameterId := range eventsPerParameterId {
+		parameterIds = append(parameterIds, parameterId)
+	}
+
+	// sorted so that the same request is always rejected for the same event
+	sort.Strings(parameterIds)
+
+	parsedEventsPerParameterId := make(map[string][]domain.Event, len(eventsPerParameterId))
+	for _, parameterId := range parameterIds {
+		events := make([]domain.Event, 0, len(eventsPerParameterId[parameterId]))
+
+		for index, pair := range eventsPerParameterId[parameterId] {
+			event, err := domain.ParseEvent(pair)
+			if err != nil {
+				return nil, apierrors.InvalidEventError{
+					ReturnedStatusCode: http.StatusBadRequest,
+					OriginalError:      err,
+					ParameterId:        parameterId,
+					EventIndex:         index,
+				}
+			}
+
+			events = append(events, event)
+		}
+
+		parsedEventsPerParameterId[parameterId] = events
+	}
+
+	return parsedEventsPerParameterId, nil
+}
+
+type ViolationDto struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func newViolationDtos(violations []apierrors.Violation) []ViolationDto {
+	violationDtos := make([]ViolationDto, 0, len(violations))
+	for _, violation := range violations {
+		violationDtos = append(violationDtos, ViolationDto{
+			Path:    violation.Path,
+			Message: violation.Message,
+		})
+	}
+
+	return violationDtos
 }
 
-type GetSolarPanelDataResponse struct {
-	SolarPanelDataEvents [][]string
+type CreateSolarPanelDataResponse struct {
+	InsertedId    string         `json:"id,omitempty"`
+	DataSubmitted *Dto           `json:"dataSubmitted,omitempty"`
+	CreatedAt     *time.Time     `json:"createdAt,omitempty"`
+	UpdatedAt     *time.Time     `json:"updatedAt,omitempty"`
+	Version       int            `json:"version,omitempty"`
+	ErrorMessage  string         `json:"errorMessage,omitempty"`
+	Violations    []ViolationDto `json:"violations,omitempty"`
 }
 
 type DeleteSolarPanelDataResponse struct {
@@ -20,5 +108,219 @@ type DeleteSolarPanelDataResponse struct {
 }
 
 type UpdateSolarPanelDataResponse struct {
-	ErrorMessage string `json:"errorMessage,omitempty"`
+	ErrorMessage string         `json:"errorMessage,omitempty"`
+	Violations   []ViolationDto `json:"violations,omitempty"`
+}
+
+// PatchSolarPanelDataResponse is the response of a successful merge, failures
+// being reported as an UpdateSolarPanelDataResponse.
+type PatchSolarPanelDataResponse struct {
+	UpdatedAt         *time.Time `json:"updatedAt,omitempty"`
+	Version           int        `json:"version,omitempty"`
+	AppendedEvents    int        `json:"appendedEvents"`
+	OverwrittenEvents int        `json:"overwrittenEvents"`
+	UnchangedEvents   int        `json:"unchangedEvents"`
+	KeptEvents        int        `json:"keptEvents"`
+	AddedParameters   int        `json:"addedParameters"`
+}
+
+type SolarPanelDataSummaryDto struct {
+	Id             string    `json:"id"`
+	Name           string    `json:"name,omitempty"`
+	Site           string    `json:"site,omitempty"`
+	Tags           []string  `json:"tags,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+	Version        int       `json:"version"`
+	ParameterCount int       `json:"parameterCount"`
+	EventCount     int       `json:"eventCount"`
+}
+
+type ListSolarPanelDataResponse struct {
+	Items      []SolarPanelDataSummaryDto `json:"items"`
+	NextCursor string                     `json:"nextCursor,omitempty"`
+}
+
+type ErrorResponse struct {
+	ErrorMessage string `json:"errorMessage"`
+}
+
+type DailyEnergyDto struct {
+	Date   string  `json:"date"`
+	Energy float64 `json:"energy"`
+}
+
+type ParameterEnergyDto struct {
+	Source      domain.EventSource `json:"source"`
+	ParameterId string             `json:"parameterId"`
+	Days        []DailyEnergyDto   `json:"days"`
+	Total       float64            `json:"total"`
+	SkippedGaps int                `json:"skippedGaps"`
+}
+
+type GetSolarPanelDataEnergyResponse struct {
+	Unit       string               `json:"unit"`
+	Parameters []ParameterEnergyDto `json:"parameters"`
+	Days       []DailyEnergyDto     `json:"days"`
+	Total      float64              `json:"total"`
+}
+
+func toDailyEnergyDtos(days []helper.DailyEnergy) []DailyEnergyDto {
+	dtos := make([]DailyEnergyDto, 0, len(days))
+	for _, day := range days {
+		dtos = append(dtos, DailyEnergyDto{Date: day.Date, Energy: day.Energy})
+	}
+
+	return dtos
+}
+
+// ParameterStatisticsDto leaves out everything but the count of a series
+// without events, rather than reporting zeros that look like readings.
+type ParameterStatisticsDto struct {
+	Source            domain.EventSource `json:"source"`
+	ParameterId       string             `json:"parameterId"`
+	Count             int                `json:"count"`
+	First             *time.Time         `json:"first,omitempty"`
+	Last              *time.Time         `json:"last,omitempty"`
+	Min               *float64           `json:"min,omitempty"`
+	Max               *float64           `json:"max,omitempty"`
+	Mean              *float64           `json:"mean,omitempty"`
+	StandardDeviation *float64           `json:"standardDeviation,omitempty"`
+	ZeroPercentage    *float64           `json:"zeroPercentage,omitempty"`
+}
+
+type GetSolarPanelDataStatisticsResponse struct {
+	Parameters []ParameterStatisticsDto `json:"parameters"`
+}
+
+func toParameterStatisticsDto(statistics helper.ParameterStatistics) ParameterStatisticsDto {
+	dto := ParameterStatisticsDto{
+		Source:      statistics.Source,
+		ParameterId: statistics.ParameterId,
+		Count:       statistics.Count,
+	}
+
+	if statistics.Count == 0 {
+		return dto
+	}
+
+	first, last := statistics.First.UTC(), statistics.Last.UTC()
+
+	dto.First = &first
+	dto.Last = &last
+	dto.Min = &statistics.Minimum
+	dto.Max = &statistics.Maximum
+	dto.Mean = &statistics.Mean
+	dto.StandardDeviation = &statistics.StandardDeviation
+	dto.ZeroPercentage = &statistics.ZeroPercentage
+
+	return dto
+}
+
+type MissingIntervalDto struct {
+	From            time.Time `json:"from"`
+	To              time.Time `json:"to"`
+	MissingReadings int       `json:"missingReadings"`
+}
+
+type FlatlineDto struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Value    float64   `json:"value"`
+	Readings int       `json:"readings"`
+}
+
+type ParameterQualityDto struct {
+	Source              domain.EventSource   `json:"source"`
+	ParameterId         string               `json:"parameterId"`
+	Count               int                  `json:"count"`
+	CadenceSeconds      float64              `json:"cadenceSeconds"`
+	MissingIntervals    []MissingIntervalDto `json:"missingIntervals"`
+	MissingReadings     int                  `json:"missingReadings"`
+	DuplicateTimestamps []time.Time          `json:"duplicateTimestamps"`
+	OutOfOrderEvents    int                  `json:"outOfOrderEvents"`
+	Flatlines           []FlatlineDto        `json:"flatlines"`
+	NegativeReadings    int                  `json:"negativeReadings"`
+	Score               float64              `json:"score"`
+}
+
+type GetSolarPanelDataQualityResponse struct {
+	Parameters []ParameterQualityDto `json:"parameters"`
+}
+
+func toParameterQualityDto(quality helper.ParameterQuality) ParameterQualityDto {
+	dto := ParameterQualityDto{
+		Source:              quality.Source,
+		ParameterId:         quality.ParameterId,
+		Count:               quality.Count,
+		CadenceSeconds:      quality.Cadence.Seconds(),
+		MissingIntervals:    make([]MissingIntervalDto, 0, len(quality.MissingIntervals)),
+		MissingReadings:     quality.MissingReadings,
+		DuplicateTimestamps: make([]time.Time, 0, len(quality.DuplicateTimestamps)),
+		OutOfOrderEvents:    quality.OutOfOrderEvents,
+		Flatlines:           make([]FlatlineDto, 0, len(quality.Flatlines)),
+		NegativeReadings:    quality.NegativeReadings,
+		Score:               quality.Score,
+	}
+
+	for _, missingInterval := range quality.MissingIntervals {
+		dto.MissingIntervals = append(dto.MissingIntervals, MissingIntervalDto{
+			From:            missingInterval.From.UTC(),
+			To:              missingInterval.To.UTC(),
+			MissingReadings: missingInterval.MissingReadings,
+		})
+	}
+
+	for _, duplicateTimestamp := range quality.DuplicateTimestamps {
+		dto.DuplicateTimestamps = append(dto.DuplicateTimestamps, duplicateTimestamp.UTC())
+	}
+
+	for _, flatline := range quality.Flatlines {
+		dto.Flatlines = append(dto.Flatlines, FlatlineDto{
+			From:     flatline.From.UTC(),
+			To:       flatline.To.UTC(),
+			Value:    flatline.Value,
+			Readings: flatline.Readings,
+		})
+	}
+
+	return dto
+}
+
+type AnomalyDto struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Readings int       `json:"readings"`
+	Score    float64   `json:"score"`
+}
+
+type ParameterAnomaliesDto struct {
+	Source           domain.EventSource `json:"source"`
+	ParameterId      string             `json:"parameterId"`
+	ComparedReadings int                `json:"comparedReadings"`
+	Anomalies        []AnomalyDto       `json:"anomalies"`
+}
+
+type GetSolarPanelDataAnomaliesResponse struct {
+	Parameters []ParameterAnomaliesDto `json:"parameters"`
+}
+
+func toParameterAnomaliesDto(anomalies helper.ParameterAnomalies) ParameterAnomaliesDto {
+	dto := ParameterAnomaliesDto{
+		Source:           anomalies.Source,
+		ParameterId:      anomalies.ParameterId,
+		ComparedReadings: anomalies.ComparedReadings,
+		Anomalies:        make([]AnomalyDto, 0, len(anomalies.Anomalies)),
+	}
+
+	for _, anomaly := range anomalies.Anomalies {
+		dto.Anomalies = append(dto.Anomalies, AnomalyDto{
+			From:     anomaly.From.UTC(),
+			To:       anomaly.To.UTC(),
+			Readings: anomaly.Readings,
+			Score:    anomaly.Score,
+		})
+	}
+
+	return dto
 }
@@ -0,0 +1,75 @@
+package solarPanelData
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/services"
+	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// GetSolarPanelDataStatisticsHandler summarises the events of each series of a
+// dataset, to sanity check an upload without exporting it.
+type GetSolarPanelDataStatisticsHandler struct {
+	SolarPanelDataService              services.SolarPanelDataServiceInterface
+	SolarPanelDataStatisticsCalculator helper.SolarPanelDataStatisticsCalculatorInterface
+	logger                             *log.Logger
+}
+
+func NewGetSolarPanelDataStatisticsHandler(
+	service *services.SolarPanelDataService,
+	calculator *helper.SolarPanelDataStatisticsCalculator,
+	logger *log.Logger,
+) *GetSolarPanelDataStatisticsHandler {
+	return &GetSolarPanelDataStatisticsHandler{
+		SolarPanelDataService:              service,
+		SolarPanelDataStatisticsCalculator: calculator,
+		logger:                             logger,
+	}
+}
+
+func (handler *GetSolarPanelDataStatisticsHandler) GetSolarPanelDataStatisticsController(w http.ResponseWriter, r *http.Request) {
+	responder := analysisResponder{
+		service:    handler.SolarPanelDataService,
+		logger:     handler.logger,
+		logMessage: "Error in getting solar panel data statistics",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	dataUuid := mux.Vars(r)["id"]
+	if dataUuid == "" {
+		responder.writeError(w, http.StatusBadRequest, "missing solarPanelData id")
+
+		return
+	}
+
+	statisticsOptions, err := helper.ParseStatisticsOptions(r.URL.Query())
+	if err != nil {
+		responder.writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	solarPanelData, ok := responder.getSolarPanelData(w, dataUuid, &statisticsOptions.EventSelection)
+	if !ok {
+		return
+	}
+
+	statistics, err := handler.SolarPanelDataStatisticsCalculator.CalculateStatistics(solarPanelData, statisticsOptions)
+	if err != nil {
+		responder.writeAnalysisError(w, err)
+
+		return
+	}
+
+	response := &GetSolarPanelDataStatisticsResponse{
+		Parameters: make([]ParameterStatisticsDto, 0, len(statistics)),
+	}
+
+	for _, parameterStatistics := range statistics {
+		response.Parameters = append(response.Parameters, toParameterStatisticsDto(parameterStatistics))
+	}
+
+	responder.writeResponse(w, response)
+}
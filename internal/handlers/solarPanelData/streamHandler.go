@@ -0,0 +1,170 @@
+package solarPanelData
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/services"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// StreamSolarPanelDataHandler writes a dataset in the write format of a
+// time-series database, so that it can be piped straight into one. The events
+// are collected and sorted in memory first, like every export, and only the
+// encoded output is flushed to the client as it is written.
+type StreamSolarPanelDataHandler struct {
+	SolarPanelDataService services.SolarPanelDataServiceInterface
+	SolarPanelDataEncoder helper.SolarPanelDataEncoderInterface
+	contentType           string
+	contentEncoding       string
+	logger                *log.Logger
+}
+
+// NewStreamSolarPanelDataHandler streams with the encoder, whose output is sent
+// as the content type and, if not empty, content encoding. Errors are always
+// sent as plain text.
+func NewStreamSolarPanelDataHandler(
+	service *services.SolarPanelDataService,
+	encoder helper.SolarPanelDataEncoderInterface,
+	contentType string,
+	contentEncoding string,
+	logger *log.Logger,
+) *StreamSolarPanelDataHandler {
+	return &StreamSolarPanelDataHandler{
+		SolarPanelDataService: service,
+		SolarPanelDataEncoder: encoder,
+		contentType:           contentType,
+		contentEncoding:       contentEncoding,
+		logger:                logger,
+	}
+}
+
+func (handler *StreamSolarPanelDataHandler) StreamSolarPanelDataController(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", handler.contentType)
+	if handler.contentEncoding != "" {
+		w.Header().Set("Content-Encoding", handler.contentEncoding)
+	}
+
+	dataUuid := mux.Vars(r)["id"]
+	if dataUuid == "" {
+		handler.writeError(w, http.StatusBadRequest, "missing solarPanelData id")
+
+		return
+	}
+
+	exportOptions, err := helper.ParseExportOptions(r.URL.Query())
+	if err != nil {
+		handler.writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	strict, err := parseStrict(r.URL.Query())
+	if err != nil {
+		handler.writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	getSolarPanelData := handler.SolarPanelDataService.GetSolarPanelData
+	if !strict {
+		getSolarPanelData = handler.SolarPanelDataService.GetSolarPanelDataSkippingMalformedEvents
+	}
+
+	solarPanelData, err := getSolarPanelData(dataUuid, &exportOptions.EventSelection)
+	if dataNotFoundErrorWrapper, ok := err.(*apierrors.DataNotFoundErrorWrapper); ok {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": dataNotFoundErrorWrapper.Unwrap().Error(),
+		}).Debug("Error in streaming solar panel data")
+
+		w.WriteHeader(dataNotFoundErrorWrapper.ReturnedStatusCode)
+
+		return
+	}
+
+	if malformedEventDataError, ok := err.(apierrors.MalformedEventDataError); ok {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": malformedEventDataError.Unwrap().Error(),
+		}).Debug("Error in streaming solar panel data")
+
+		handler.writeError(w, malformedEventDataError.ReturnedStatusCode, malformedEventDataError.Error())
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error("Error in streaming solar panel data")
+
+		return
+	}
+
+	// the status code is sent with the first events, so a failure halfway can
+	// only be logged and seen by the client as a cut short body. Unknown
+	// parameters are found before any event is written, and answered without
+	// the metadata headers of the dataset.
+	streamWriter := &metadataHeadersWriter{w: w, solarPanelData: solarPanelData}
+	err = handler.SolarPanelDataEncoder.EncodeSolarPanelData(streamWriter, solarPanelData, exportOptions)
+	if unknownParametersError, ok := err.(apierrors.UnknownParametersError); ok {
+		handler.writeError(w, unknownParametersError.ReturnedStatusCode, unknownParametersError.Error())
+
+		return
+	}
+
+	if err != nil {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error("Error in streaming solar panel data")
+
+		return
+	}
+
+	// an export with no events never wrote, so its headers are not set yet
+	streamWriter.setMetadataHeaders()
+
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (handler *StreamSolarPanelDataHandler) writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Del("Content-Encoding")
+	w.WriteHeader(statusCode)
+
+	err := handler.SolarPanelDataEncoder.EncodeError(w, message)
+	if err != nil {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error("Error in streaming solar panel data")
+	}
+}
+
+// metadataHeadersWriter sets the metadata headers of the dataset right before
+// the first of its events is streamed, once the encoder has validated the
+// export.
+type metadataHeadersWriter struct {
+	w              http.ResponseWriter
+	solarPanelData *domain.SolarPanelData
+	headersSet     bool
+}
+
+func (writer *metadataHeadersWriter) Write(p []byte) (int, error) {
+	writer.setMetadataHeaders()
+
+	return writer.w.Write(p)
+}
+
+func (writer *metadataHeadersWriter) setMetadataHeaders() {
+	if writer.headersSet {
+		return
+	}
+
+	setMetadataHeaders(writer.w, writer.solarPanelData)
+	writer.headersSet = true
+}
@@ -0,0 +1,403 @@
+package solarPanelData
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	mock_services "github.com/loukaspe/solar-panel-data-crud/mocks/mock_internal/core/services"
+	mock_helper "github.com/loukaspe/solar-panel-data-crud/mocks/mock_pkg/helper"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStreamSolarPanelDataHandler_StreamSolarPanelDataController(t *testing.T) {
+	logger := logrus.New()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockService := mock_services.NewMockSolarPanelDataServiceInterface(mockCtrl)
+	mockEncoder := mock_helper.NewMockSolarPanelDataEncoderInterface(mockCtrl)
+
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+				{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+			},
+		},
+		Version: 2,
+	}
+
+	tests := []struct {
+		name                        string
+		contentType                 string
+		contentEncoding             string
+		requestedUuid               string
+		requestQuery                string
+		shouldMockServiceRun        bool
+		shouldMockLenientServiceRun bool
+		mockServiceResponseData     *domain.SolarPanelData
+		mockServiceResponseError    error
+		shouldMockEncoderRun        bool
+		expectedExportOptions       *helper.ExportOptions
+		mockEncoderResponseBody     string
+		mockEncoderResponseError    error
+		shouldMockErrorRun          bool
+		expectedErrorMessage        string
+		expected                    string
+		expectedStatusCode          int
+		expectedHeaders             map[string]string
+	}{
+		{
+			name:                     "valid",
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:             "?source=all",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  solarPanelData,
+			mockServiceResponseError: nil,
+			shouldMockEncoderRun:     true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceAll,
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEncoderResponseBody:  "solar,parameter=38d503e5-dc1c-4549-8172-09d9c29070f7 value=0 1640988900000000000\n",
+			mockEncoderResponseError: nil,
+			expected:                 "solar,parameter=38d503e5-dc1c-4549-8172-09d9c29070f7 value=0 1640988900000000000\n",
+			expectedStatusCode:       200,
+			expectedHeaders: map[string]string{
+				"Content-Type": "text/plain; charset=utf-8",
+				"ETag":         `"2"`,
+			},
+		},
+		{
+			name:                     "valid without events",
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:             "?source=wind",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  solarPanelData,
+			mockServiceResponseError: nil,
+			shouldMockEncoderRun:     true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceWind,
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEncoderResponseBody:  "",
+			mockEncoderResponseError: nil,
+			expected:                 "",
+			expectedStatusCode:       200,
+			expectedHeaders: map[string]string{
+				"ETag": `"2"`,
+			},
+		},
+		{
+			name:                     "unknown parameters",
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:             "?parameter=51df2e4c-2002-11ea-95a5-525400b2701a",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  solarPanelData,
+			mockServiceResponseError: nil,
+			shouldMockEncoderRun:     true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceSolar,
+					Parameters: []string{"51df2e4c-2002-11ea-95a5-525400b2701a"},
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEncoderResponseBody: "",
+			mockEncoderResponseError: apierrors.UnknownParametersError{
+				ReturnedStatusCode: http.StatusNotFound,
+				ParameterIds:       []string{"51df2e4c-2002-11ea-95a5-525400b2701a"},
+			},
+			shouldMockErrorRun:   true,
+			expectedErrorMessage: "unknown parameters 51df2e4c-2002-11ea-95a5-525400b2701a",
+			expected:             "unknown parameters 51df2e4c-2002-11ea-95a5-525400b2701a\n",
+			expectedStatusCode:   404,
+			expectedHeaders: map[string]string{
+				"ETag":          "",
+				"Last-Modified": "",
+			},
+		},
+		{
+			name:                        "valid lenient with skipped malformed events",
+			requestedUuid:               "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:                "?strict=false",
+			shouldMockLenientServiceRun: true,
+			mockServiceResponseData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{},
+				},
+				MalformedEvents: []domain.MalformedEvent{
+					{
+						Source:        domain.EventSourceSolar,
+						ParameterId:   "38d503e5-dc1c-4549-8172-09d9c29070f7",
+						Position:      0,
+						OriginalError: errors.New("event with no timestamp or value"),
+					},
+				},
+			},
+			mockServiceResponseError: nil,
+			shouldMockEncoderRun:     true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEncoderResponseBody:  "",
+			mockEncoderResponseError: nil,
+			expected:                 "",
+			expectedStatusCode:       200,
+			expectedHeaders: map[string]string{
+				"X-Solar-Panel-Data-Skipped-Events":     "1",
+				"X-Solar-Panel-Data-Skipped-Parameters": "solar:38d503e5-dc1c-4549-8172-09d9c29070f7",
+			},
+		},
+		{
+			name:                 "invalid strict",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:         "?strict=1",
+			shouldMockServiceRun: false,
+			shouldMockEncoderRun: false,
+			shouldMockErrorRun:   true,
+			expectedErrorMessage: "strict must be one of true, false",
+			expected:             "strict must be one of true, false\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name:                 "invalid sort",
+			requestedUuid:        "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:         "?sort=value",
+			shouldMockServiceRun: false,
+			shouldMockEncoderRun: false,
+			shouldMockErrorRun:   true,
+			expectedErrorMessage: "sort must be one of parameterId, timestamp",
+			expected:             "sort must be one of parameterId, timestamp\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name:                 "missing id",
+			requestedUuid:        "",
+			shouldMockServiceRun: false,
+			shouldMockEncoderRun: false,
+			shouldMockErrorRun:   true,
+			expectedErrorMessage: "missing solarPanelData id",
+			expected:             "missing solarPanelData id\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name:                    "not found",
+			requestedUuid:           "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:    true,
+			mockServiceResponseData: nil,
+			mockServiceResponseError: &apierrors.DataNotFoundErrorWrapper{
+				ReturnedStatusCode: http.StatusNoContent,
+				OriginalError:      errors.New("solarPanelData not found"),
+			},
+			shouldMockEncoderRun: false,
+			expected:             "",
+			expectedStatusCode:   204,
+		},
+		{
+			name:                    "malformed events",
+			requestedUuid:           "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:    true,
+			mockServiceResponseData: nil,
+			mockServiceResponseError: apierrors.MalformedEventDataError{
+				ReturnedStatusCode:   http.StatusInternalServerError,
+				OriginalError:        errors.New("event with no timestamp or value"),
+				MalformedParameterId: "uuid1",
+			},
+			shouldMockErrorRun:   true,
+			expectedErrorMessage: "malformed solar panel data, check parameter uuid1",
+			expected:             "malformed solar panel data, check parameter uuid1\n",
+			expectedStatusCode:   500,
+		},
+		{
+			name:                     "valid remote-write",
+			contentType:              helper.MediaTypePrometheusRemoteWrite,
+			contentEncoding:          helper.ContentEncodingSnappy,
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  solarPanelData,
+			mockServiceResponseError: nil,
+			shouldMockEncoderRun:     true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEncoderResponseBody:  "write request",
+			mockEncoderResponseError: nil,
+			expected:                 "write request",
+			expectedStatusCode:       200,
+			expectedHeaders: map[string]string{
+				"Content-Type":     "application/x-protobuf",
+				"Content-Encoding": "snappy",
+			},
+		},
+		{
+			name:                     "remote-write errors in plain text",
+			contentType:              helper.MediaTypePrometheusRemoteWrite,
+			contentEncoding:          helper.ContentEncodingSnappy,
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			requestQuery:             "?parameter=51df2e4c-2002-11ea-95a5-525400b2701a",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  solarPanelData,
+			mockServiceResponseError: nil,
+			shouldMockEncoderRun:     true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceSolar,
+					Parameters: []string{"51df2e4c-2002-11ea-95a5-525400b2701a"},
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEncoderResponseBody: "",
+			mockEncoderResponseError: apierrors.UnknownParametersError{
+				ReturnedStatusCode: http.StatusNotFound,
+				ParameterIds:       []string{"51df2e4c-2002-11ea-95a5-525400b2701a"},
+			},
+			shouldMockErrorRun:   true,
+			expectedErrorMessage: "unknown parameters 51df2e4c-2002-11ea-95a5-525400b2701a",
+			expected:             "unknown parameters 51df2e4c-2002-11ea-95a5-525400b2701a\n",
+			expectedStatusCode:   404,
+			expectedHeaders: map[string]string{
+				"Content-Type":     "text/plain; charset=utf-8",
+				"Content-Encoding": "",
+			},
+		},
+		{
+			name:                     "random service error",
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  nil,
+			mockServiceResponseError: errors.New("random error"),
+			shouldMockEncoderRun:     false,
+			expected:                 "",
+			expectedStatusCode:       500,
+		},
+		{
+			name:                     "encoder error after streaming started",
+			requestedUuid:            "38d503e5-dc1c-4549-8172-09d9c29070f7",
+			shouldMockServiceRun:     true,
+			mockServiceResponseData:  solarPanelData,
+			mockServiceResponseError: nil,
+			shouldMockEncoderRun:     true,
+			expectedExportOptions: &helper.ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout: helper.ExportLayoutEvents,
+				Sort:   helper.ExportSortParameterId,
+			},
+			mockEncoderResponseBody:  "solar,parameter=38d503e5-dc1c-4549-8172-09d9c29070f7 ",
+			mockEncoderResponseError: errors.New("random error"),
+			expected:                 "solar,parameter=38d503e5-dc1c-4549-8172-09d9c29070f7 ",
+			expectedStatusCode:       200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRequest := httptest.NewRequest(
+				"GET",
+				"/solarPanelData/line-protocol"+tt.requestQuery,
+				nil,
+			)
+			vars := map[string]string{
+				"id": tt.requestedUuid,
+			}
+			mockRequest = mux.SetURLVars(mockRequest, vars)
+			mockResponseRecorder := httptest.NewRecorder()
+
+			// the service is asked for the events the query selects, which
+			// its malformed events are scoped to
+			requestedOptions, _ := helper.ParseExportOptions(mockRequest.URL.Query())
+
+			if tt.shouldMockServiceRun {
+				mockService.EXPECT().
+					GetSolarPanelData(tt.requestedUuid, &requestedOptions.EventSelection).
+					Return(tt.mockServiceResponseData, tt.mockServiceResponseError)
+			}
+
+			if tt.shouldMockLenientServiceRun {
+				mockService.EXPECT().
+					GetSolarPanelDataSkippingMalformedEvents(tt.requestedUuid, &requestedOptions.EventSelection).
+					Return(tt.mockServiceResponseData, tt.mockServiceResponseError)
+			}
+
+			if tt.shouldMockEncoderRun {
+				mockEncoder.EXPECT().
+					EncodeSolarPanelData(gomock.Any(), tt.mockServiceResponseData, tt.expectedExportOptions).
+					DoAndReturn(func(w io.Writer, _ *domain.SolarPanelData, _ *helper.ExportOptions) error {
+						if tt.mockEncoderResponseBody != "" {
+							_, err := io.WriteString(w, tt.mockEncoderResponseBody)
+							assert.NoError(t, err)
+						}
+
+						return tt.mockEncoderResponseError
+					})
+			}
+
+			if tt.shouldMockErrorRun {
+				mockEncoder.EXPECT().
+					EncodeError(gomock.Any(), tt.expectedErrorMessage).
+					DoAndReturn(func(w io.Writer, message string) error {
+						_, err := io.WriteString(w, message+"\n")
+
+						return err
+					})
+			}
+
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = helper.MediaTypeInfluxLineProtocol + "; charset=utf-8"
+			}
+
+			handler := &StreamSolarPanelDataHandler{
+				SolarPanelDataService: mockService,
+				SolarPanelDataEncoder: mockEncoder,
+				contentType:           contentType,
+				contentEncoding:       tt.contentEncoding,
+				logger:                logger,
+			}
+			sut := handler.StreamSolarPanelDataController
+
+			sut(mockResponseRecorder, mockRequest)
+
+			mockResponse := mockResponseRecorder.Result()
+			actual, err := io.ReadAll(mockResponse.Body)
+			if err != nil {
+				t.Errorf("error with response reading: %v", err)
+				return
+			}
+			actualStatusCode := mockResponse.StatusCode
+
+			assert.Equal(t, tt.expected, string(actual))
+			assert.Equal(t, tt.expectedStatusCode, actualStatusCode)
+
+			for header, expectedValue := range tt.expectedHeaders {
+				assert.Equal(t, expectedValue, mockResponse.Header.Get(header))
+			}
+		})
+	}
+}
@@ -3,25 +3,28 @@ package solarPanelData
 import (
 	"encoding/json"
 	"github.com/gorilla/mux"
-	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
 	"github.com/loukaspe/solar-panel-data-crud/internal/core/services"
 	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
 	log "github.com/sirupsen/logrus"
 	"net/http"
 )
 
 type UpdateSolarPanelDataHandler struct {
-	SolarPanelDataService services.SolarPanelDataServiceInterface
-	logger                *log.Logger
+	SolarPanelDataService   services.SolarPanelDataServiceInterface
+	SolarPanelDataValidator helper.SolarPanelDataValidatorInterface
+	logger                  *log.Logger
 }
 
 func NewUpdateSolarPanelDataHandler(
 	service *services.SolarPanelDataService,
+	validator *helper.SolarPanelDataValidator,
 	logger *log.Logger,
 ) *UpdateSolarPanelDataHandler {
 	return &UpdateSolarPanelDataHandler{
-		SolarPanelDataService: service,
-		logger:                logger,
+		SolarPanelDataService:   service,
+		SolarPanelDataValidator: validator,
+		logger:                  logger,
 	}
 }
 
@@ -53,9 +56,62 @@ func (handler *UpdateSolarPanelDataHandler) UpdateSolarPanelDataController(w htt
 		return
 	}
 
-	domainSolarPanelData := &domain.SolarPanelData{
-		Solar: solarPanelDataRequest.Solar,
-		Wind:  solarPanelDataRequest.Wind,
+	err = handler.SolarPanelDataValidator.ValidateSolarPanelData(
+		solarPanelDataRequest.Solar,
+		solarPanelDataRequest.Wind,
+	)
+	if validationError, ok := err.(apierrors.ValidationError); ok {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": validationError.Error(),
+		}).Debug("Error in updating solar panel data")
+
+		w.WriteHeader(validationError.ReturnedStatusCode)
+		response.ErrorMessage = validationError.Error()
+		response.Violations = newViolationDtos(validationError.Violations)
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			handler.logger.WithFields(log.Fields{
+				"errorMessage": err.Error(),
+			}).Error("Error in updating solar panel data")
+
+			return
+		}
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error("Error in updating solar panel data")
+
+		return
+	}
+
+	domainSolarPanelData, err := solarPanelDataRequest.toDomain()
+	if invalidEventError, ok := err.(apierrors.InvalidEventError); ok {
+		handler.logger.WithFields(log.Fields{
+			"errorMessage": invalidEventError.Error(),
+		}).Debug("Error in updating solar panel data")
+
+		w.WriteHeader(invalidEventError.ReturnedStatusCode)
+		response.ErrorMessage = invalidEventError.Error()
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			handler.logger.WithFields(log.Fields{
+				"errorMessage": err.Error(),
+			}).Error("Error in updating solar panel data")
+
+			return
+		}
+
+		return
 	}
 
 	uuid := mux.Vars(r)["id"]
@@ -8,6 +8,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
 	mock_services "github.com/loukaspe/solar-panel-data-crud/mocks/mock_internal/core/services"
+	mock_helper "github.com/loukaspe/solar-panel-data-crud/mocks/mock_pkg/helper"
 	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -15,6 +16,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestUpdateSolarPanelDataHandler_UpdateSolarPanelDataController(t *testing.T) {
@@ -23,17 +25,20 @@ func TestUpdateSolarPanelDataHandler_UpdateSolarPanelDataController(t *testing.T
 	defer mockCtrl.Finish()
 
 	mockService := mock_services.NewMockSolarPanelDataServiceInterface(mockCtrl)
+	mockValidator := mock_helper.NewMockSolarPanelDataValidatorInterface(mockCtrl)
 
 	tests := []struct {
 		name            string
 		requestBody     []byte
 		requestedUuid   string
 		mockRequestData *domain.SolarPanelData
-		// variable to check if the handler returns error before the mock service runs
-		shouldMockServiceRun     bool
-		mockServiceResponseError error
-		expected                 []byte
-		expectedStatusCode       int
+		// variables to check if the handler returns error before the mock validator and service run
+		shouldMockValidatorRun     bool
+		mockValidatorResponseError error
+		shouldMockServiceRun       bool
+		mockServiceResponseError   error
+		expected                   []byte
+		expectedStatusCode         int
 	}{
 		{
 			name: "valid",
@@ -50,13 +55,14 @@ func TestUpdateSolarPanelDataHandler_UpdateSolarPanelDataController(t *testing.T
 }`),
 			requestedUuid: "uuid",
 			mockRequestData: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"38d503e5-dc1c-4549-8172-09d9c29070f7": [][]string{
-						{"20211231T221500Z", "0.0"},
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
 					},
 				},
 				Wind: nil,
 			},
+			shouldMockValidatorRun:   true,
 			shouldMockServiceRun:     true,
 			mockServiceResponseError: nil,
 			expected:                 json.RawMessage(``),
@@ -75,8 +81,9 @@ func TestUpdateSolarPanelDataHandler_UpdateSolarPanelDataController(t *testing.T
   },
   "wind": null
 }`),
-			requestedUuid:        "",
-			shouldMockServiceRun: false,
+			requestedUuid:          "",
+			shouldMockValidatorRun: true,
+			shouldMockServiceRun:   false,
 			expected: json.RawMessage(`{"errorMessage":"missing solarPanelData id"}
 `),
 			expectedStatusCode: 400,
@@ -96,6 +103,76 @@ func TestUpdateSolarPanelDataHandler_UpdateSolarPanelDataController(t *testing.T
 }`),
 			shouldMockServiceRun: false,
 			expected: json.RawMessage(`{"errorMessage":"malformed solar panel data request"}
+`),
+			expectedStatusCode: 400,
+		},
+		{
+			name: "invalid event timestamp",
+			requestBody: json.RawMessage(`{
+  "solar": {
+    "38d503e5-dc1c-4549-8172-09d9c29070f7": [
+      [
+        "2021-12-31 22:15:00",
+        "0.0"
+      ]
+    ]
+  },
+  "wind": null
+}`),
+			requestedUuid:          "uuid",
+			shouldMockValidatorRun: true,
+			shouldMockServiceRun:   false,
+			expected: json.RawMessage(`{"errorMessage":"invalid event 0 of parameter 38d503e5-dc1c-4549-8172-09d9c29070f7: timestamp \"2021-12-31 22:15:00\" is not in the 20060102T150405Z format"}
+`),
+			expectedStatusCode: 400,
+		},
+		{
+			name: "invalid event value",
+			requestBody: json.RawMessage(`{
+  "solar": {
+    "38d503e5-dc1c-4549-8172-09d9c29070f7": [
+      [
+        "20211231T221500Z",
+        "0.0"
+      ],
+      [
+        "20211231T223000Z",
+        "n/a"
+      ]
+    ]
+  },
+  "wind": null
+}`),
+			requestedUuid:          "uuid",
+			shouldMockValidatorRun: true,
+			shouldMockServiceRun:   false,
+			expected: json.RawMessage(`{"errorMessage":"invalid event 1 of parameter 38d503e5-dc1c-4549-8172-09d9c29070f7: value \"n/a\" is not a number"}
+`),
+			expectedStatusCode: 400,
+		},
+		{
+			name: "invalid validation error",
+			requestBody: json.RawMessage(`{
+  "solar": {
+    "not-a-uuid": [
+      [
+        "20211231T221500Z"
+      ]
+    ]
+  },
+  "wind": null
+}`),
+			requestedUuid:          "uuid",
+			shouldMockValidatorRun: true,
+			mockValidatorResponseError: apierrors.ValidationError{
+				ReturnedStatusCode: http.StatusBadRequest,
+				Violations: []apierrors.Violation{
+					{Path: `$.solar["not-a-uuid"]`, Message: "is not a UUID"},
+					{Path: `$.solar["not-a-uuid"][0]`, Message: "must be a [timestamp, value] pair"},
+				},
+			},
+			shouldMockServiceRun: false,
+			expected: json.RawMessage(`{"errorMessage":"invalid solar panel data, check violations","violations":[{"path":"$.solar[\"not-a-uuid\"]","message":"is not a UUID"},{"path":"$.solar[\"not-a-uuid\"][0]","message":"must be a [timestamp, value] pair"}]}
 `),
 			expectedStatusCode: 400,
 		},
@@ -116,7 +193,8 @@ func TestUpdateSolarPanelDataHandler_UpdateSolarPanelDataController(t *testing.T
 			mockRequestData: &domain.SolarPanelData{
 				Wind: nil,
 			},
-			shouldMockServiceRun: true,
+			shouldMockValidatorRun: true,
+			shouldMockServiceRun:   true,
 			mockServiceResponseError: apierrors.EmptySolarDataError{
 				ReturnedStatusCode: http.StatusBadRequest,
 			},
@@ -139,14 +217,15 @@ func TestUpdateSolarPanelDataHandler_UpdateSolarPanelDataController(t *testing.T
 }`),
 			requestedUuid: "uuidNotExisting",
 			mockRequestData: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"38d503e5-dc1c-4549-8172-09d9c29070f7": [][]string{
-						{"20211231T221500Z", "0.0"},
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
 					},
 				},
 				Wind: nil,
 			},
-			shouldMockServiceRun: true,
+			shouldMockValidatorRun: true,
+			shouldMockServiceRun:   true,
 			mockServiceResponseError: &apierrors.DataNotFoundErrorWrapper{
 				ReturnedStatusCode: http.StatusNotFound,
 				OriginalError:      errors.New("uuid uuidNotExisting not found"),
@@ -169,13 +248,14 @@ func TestUpdateSolarPanelDataHandler_UpdateSolarPanelDataController(t *testing.T
 }`),
 			requestedUuid: "uuid",
 			mockRequestData: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"38d503e5-dc1c-4549-8172-09d9c29070f7": [][]string{
-						{"20211231T221500Z", "0.0"},
+				Solar: map[string][]domain.Event{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
 					},
 				},
 				Wind: nil,
 			},
+			shouldMockValidatorRun:   true,
 			shouldMockServiceRun:     true,
 			mockServiceResponseError: errors.New("random error"),
 			expected: json.RawMessage(`{"errorMessage":"random error"}
@@ -196,6 +276,12 @@ func TestUpdateSolarPanelDataHandler_UpdateSolarPanelDataController(t *testing.T
 			mockRequest.Header.Set("Content-Type", "application/json")
 			mockResponseRecorder := httptest.NewRecorder()
 
+			if tt.shouldMockValidatorRun {
+				mockValidator.EXPECT().
+					ValidateSolarPanelData(gomock.Any(), gomock.Any()).
+					Return(tt.mockValidatorResponseError)
+			}
+
 			if tt.shouldMockServiceRun {
 				mockService.EXPECT().
 					UpdateSolarPanelData(tt.requestedUuid, tt.mockRequestData).
@@ -203,8 +289,9 @@ func TestUpdateSolarPanelDataHandler_UpdateSolarPanelDataController(t *testing.T
 			}
 
 			handler := &UpdateSolarPanelDataHandler{
-				SolarPanelDataService: mockService,
-				logger:                logger,
+				SolarPanelDataService:   mockService,
+				SolarPanelDataValidator: mockValidator,
+				logger:                  logger,
 			}
 			sut := handler.UpdateSolarPanelDataController
 
@@ -0,0 +1,390 @@
+package repositories
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	solarPanelDataLogFileName      = "solar-panel-data.log"
+	solarPanelDataSnapshotFileName = "solar-panel-data.snapshot"
+
+	solarPanelDataLogOperationPut    = "put"
+	solarPanelDataLogOperationDelete = "delete"
+)
+
+// solarPanelDataLogRecord is a single line of the append-only log. Put records
+// hold the whole dataset so that replaying them is idempotent.
+type solarPanelDataLogRecord struct {
+	Operation string          `json:"op"`
+	Id        string          `json:"id"`
+	Data      *SolarPanelData `json:"data,omitempty"`
+}
+
+// FileSolarPanelDataRepository keeps the datasets in memory and persists every
+// change to an append-only log in dataDir. Every snapshotInterval changes the
+// whole database is written to a snapshot file and the log is truncated. On
+// start-up the snapshot is loaded and the log is replayed on top of it.
+type FileSolarPanelDataRepository struct {
+	mutex                   sync.RWMutex
+	db                      SolarPanelDataDB
+	dataDir                 string
+	logFile                 *os.File
+	snapshotInterval        int
+	operationsSinceSnapshot int
+	logger                  *log.Logger
+}
+
+func NewFileSolarPanelDataRepository(
+	dataDir string,
+	snapshotInterval int,
+	logger *log.Logger,
+) (*FileSolarPanelDataRepository, error) {
+	err := os.MkdirAll(dataDir, 0o750)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &FileSolarPanelDataRepository{
+		db:               make(SolarPanelDataDB),
+		dataDir:          dataDir,
+		snapshotInterval: snapshotInterval,
+		logger:           logger,
+	}
+
+	err = repo.loadSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.replayLog()
+	if err != nil {
+		return nil, err
+	}
+
+	repo.logFile, err = os.OpenFile(repo.logFilePath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+func (repo *FileSolarPanelDataRepository) CreateSolarPanelData(solarPanelData *domain.SolarPanelData) (string, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	insertedId := uuid.New().String()
+
+	dao := newSolarPanelDataDao(solarPanelData)
+	dao.CreatedAt = time.Now().UTC()
+	dao.UpdatedAt = dao.CreatedAt
+	dao.Version = 1
+
+	err := repo.put(insertedId, dao)
+	if err != nil {
+		return "", err
+	}
+
+	dao.setServerManagedFields(solarPanelData)
+
+	return insertedId, nil
+}
+
+func (repo *FileSolarPanelDataRepository) GetSolarPanelData(uuid string) (*domain.SolarPanelData, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	retrievedSolarPanelData, exists := repo.db[uuid]
+
+	if !exists {
+		return &domain.SolarPanelData{},
+			&apierrors.DataNotFoundErrorWrapper{
+				ReturnedStatusCode: http.StatusNoContent,
+				OriginalError:      errors.New("uuid " + uuid + " not found"),
+			}
+	}
+
+	return retrievedSolarPanelData.toDomain(), nil
+}
+
+func (repo *FileSolarPanelDataRepository) ListSolarPanelData(
+	options *domain.SolarPanelDataListOptions,
+) (*domain.SolarPanelDataPage, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	return listSolarPanelData(repo.db, options), nil
+}
+
+func (repo *FileSolarPanelDataRepository) UpdateSolarPanelData(uuid string, solarPanelData *domain.SolarPanelData) error {
+	return repo.update(uuid, solarPanelData, 0)
+}
+
+func (repo *FileSolarPanelDataRepository) UpdateSolarPanelDataIfVersion(
+	uuid string,
+	solarPanelData *domain.SolarPanelData,
+	version int,
+) error {
+	return repo.update(uuid, solarPanelData, version)
+}
+
+func (repo *FileSolarPanelDataRepository) update(
+	uuid string,
+	solarPanelData *domain.SolarPanelData,
+	expectedVersion int,
+) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	dao, err := updatedSolarPanelData(repo.db, uuid, solarPanelData, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	err = repo.put(uuid, dao)
+	if err != nil {
+		return err
+	}
+
+	dao.setServerManagedFields(solarPanelData)
+
+	return nil
+}
+
+func (repo *FileSolarPanelDataRepository) DeleteSolarPanelData(uuid string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	_, exists := repo.db[uuid]
+	if !exists {
+		return nil
+	}
+
+	err := repo.appendLogRecord(&solarPanelDataLogRecord{
+		Operation: solarPanelDataLogOperationDelete,
+		Id:        uuid,
+	})
+	if err != nil {
+		return err
+	}
+
+	delete(repo.db, uuid)
+
+	repo.snapshotIfNeeded()
+
+	return nil
+}
+
+// Close flushes the log file to disk and releases it. The repository must not
+// be used afterwards.
+func (repo *FileSolarPanelDataRepository) Close() error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	err := repo.logFile.Sync()
+	if err != nil {
+		return err
+	}
+
+	return repo.logFile.Close()
+}
+
+// put must be called with the write lock held. The record is written to the log
+// before the in-memory database is changed, so a failed append, which is cut
+// from the log again, leaves both untouched. Once it is appended the change is
+// durable, so a failed snapshot after it does not fail the change.
+func (repo *FileSolarPanelDataRepository) put(uuid string, dao *SolarPanelData) error {
+	err := repo.appendLogRecord(&solarPanelDataLogRecord{
+		Operation: solarPanelDataLogOperationPut,
+		Id:        uuid,
+		Data:      dao,
+	})
+	if err != nil {
+		return err
+	}
+
+	repo.db[uuid] = dao
+
+	repo.snapshotIfNeeded()
+
+	return nil
+}
+
+// appendLogRecord truncates the log back to where it was on a failed write or
+// sync, so that neither a torn line nor a record of a change the client was
+// told failed is replayed on the next start-up.
+func (repo *FileSolarPanelDataRepository) appendLogRecord(record *solarPanelDataLogRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	logFileInfo, err := repo.logFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.logFile.Write(append(line, '\n'))
+	if err == nil {
+		err = repo.logFile.Sync()
+	}
+	if err != nil {
+		truncateErr := repo.logFile.Truncate(logFileInfo.Size())
+		if truncateErr != nil {
+			repo.logger.WithFields(log.Fields{
+				"errorMessage": truncateErr.Error(),
+			}).Error("Error in truncating solar panel data log")
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// snapshotIfNeeded only logs a failed snapshot, as the changes are already in
+// the log. The next change tries again, the log growing until one succeeds.
+func (repo *FileSolarPanelDataRepository) snapshotIfNeeded() {
+	repo.operationsSinceSnapshot++
+
+	if repo.snapshotInterval <= 0 || repo.operationsSinceSnapshot < repo.snapshotInterval {
+		return
+	}
+
+	err := repo.snapshot()
+	if err != nil {
+		repo.logger.WithFields(log.Fields{
+			"errorMessage": err.Error(),
+		}).Error("Error in snapshotting solar panel data")
+	}
+}
+
+// snapshot writes the whole database to a temporary file which is then renamed
+// over the previous snapshot, so a crash never leaves a half written snapshot
+// behind. The log is truncated only after the rename; if the process dies in
+// between, replaying the log over the new snapshot is harmless.
+func (repo *FileSolarPanelDataRepository) snapshot() error {
+	temporaryPath := repo.snapshotFilePath() + ".tmp"
+
+	temporaryFile, err := os.OpenFile(temporaryPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	err = json.NewEncoder(temporaryFile).Encode(repo.db)
+	if err != nil {
+		temporaryFile.Close()
+		return err
+	}
+
+	err = temporaryFile.Sync()
+	if err != nil {
+		temporaryFile.Close()
+		return err
+	}
+
+	err = temporaryFile.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(temporaryPath, repo.snapshotFilePath())
+	if err != nil {
+		return err
+	}
+
+	err = repo.logFile.Truncate(0)
+	if err != nil {
+		return err
+	}
+
+	repo.operationsSinceSnapshot = 0
+
+	return nil
+}
+
+func (repo *FileSolarPanelDataRepository) loadSnapshot() error {
+	snapshotFile, err := os.Open(repo.snapshotFilePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer snapshotFile.Close()
+
+	return json.NewDecoder(snapshotFile).Decode(&repo.db)
+}
+
+// replayLog applies the log records on top of the loaded snapshot. A record
+// that was cut short by a crash can only be the last one; it is dropped and
+// the log is truncated right before it.
+func (repo *FileSolarPanelDataRepository) replayLog() error {
+	logFile, err := os.OpenFile(repo.logFilePath(), os.O_RDWR, 0o600)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	reader := bufio.NewReader(logFile)
+	var offset int64
+
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if errors.Is(readErr, io.EOF) {
+			if len(line) == 0 {
+				return nil
+			}
+
+			return logFile.Truncate(offset)
+		}
+		if readErr != nil {
+			return readErr
+		}
+
+		record := &solarPanelDataLogRecord{}
+		err = json.Unmarshal(line, record)
+		if err != nil {
+			return err
+		}
+
+		switch record.Operation {
+		case solarPanelDataLogOperationPut:
+			if record.Data == nil {
+				return errors.New("put of " + record.Id + " without data in solar panel data log")
+			}
+
+			repo.db[record.Id] = record.Data
+		case solarPanelDataLogOperationDelete:
+			delete(repo.db, record.Id)
+		default:
+			return errors.New("unknown operation " + record.Operation + " in solar panel data log")
+		}
+
+		offset += int64(len(line))
+		repo.operationsSinceSnapshot++
+	}
+}
+
+func (repo *FileSolarPanelDataRepository) logFilePath() string {
+	return filepath.Join(repo.dataDir, solarPanelDataLogFileName)
+}
+
+func (repo *FileSolarPanelDataRepository) snapshotFilePath() string {
+	return filepath.Join(repo.dataDir, solarPanelDataSnapshotFileName)
+}
@@ -0,0 +1,322 @@
+package repositories
+
+import (
+	"errors"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestFileSolarPanelDataRepository(t *testing.T, dataDir string, snapshotInterval int) *FileSolarPanelDataRepository {
+	repo, err := NewFileSolarPanelDataRepository(dataDir, snapshotInterval, logrus.New())
+	if err != nil {
+		t.Fatalf("NewFileSolarPanelDataRepository() error = %v", err)
+	}
+
+	return repo
+}
+
+func TestFileSolarPanelDataRepository_Restart(t *testing.T) {
+	dataDir := t.TempDir()
+	repo := newTestFileSolarPanelDataRepository(t, dataDir, 0)
+
+	createdId, err := repo.CreateSolarPanelData(&domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	updatedId, err := repo.CreateSolarPanelData(&domain.SolarPanelData{})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.UpdateSolarPanelData(updatedId, &domain.SolarPanelData{
+		Wind: map[string][]domain.Event{
+			"turbine1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 12.5},
+			},
+		},
+	}))
+	assert.NoError(t, repo.UpdateSolarPanelDataIfVersion(updatedId, &domain.SolarPanelData{
+		Wind: map[string][]domain.Event{
+			"turbine1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 13},
+			},
+		},
+	}, 2))
+
+	deletedId, err := repo.CreateSolarPanelData(&domain.SolarPanelData{})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.DeleteSolarPanelData(deletedId))
+
+	expected, err := repo.GetSolarPanelData(updatedId)
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.Close())
+
+	// every change must survive a restart
+	reopenedRepo := newTestFileSolarPanelDataRepository(t, dataDir, 0)
+	defer reopenedRepo.Close()
+
+	actual, err := reopenedRepo.GetSolarPanelData(createdId)
+	assert.NoError(t, err)
+	assert.EqualValues(t, &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+			},
+		},
+		Version: 1,
+	}, withoutTimestamps(actual))
+
+	actual, err = reopenedRepo.GetSolarPanelData(updatedId)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, 3, actual.Version)
+
+	_, err = reopenedRepo.GetSolarPanelData(deletedId)
+	assert.Equal(t, &apierrors.DataNotFoundErrorWrapper{
+		ReturnedStatusCode: http.StatusNoContent,
+		OriginalError:      errors.New("uuid " + deletedId + " not found"),
+	}, err)
+}
+
+func TestFileSolarPanelDataRepository_Snapshot(t *testing.T) {
+	dataDir := t.TempDir()
+	repo := newTestFileSolarPanelDataRepository(t, dataDir, 2)
+
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+			},
+		},
+		Wind: nil,
+	}
+
+	firstId, err := repo.CreateSolarPanelData(solarPanelData)
+	assert.NoError(t, err)
+	secondId, err := repo.CreateSolarPanelData(solarPanelData)
+	assert.NoError(t, err)
+	thirdId, err := repo.CreateSolarPanelData(solarPanelData)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Close())
+
+	// the first two creations were compacted into the snapshot, so only the
+	// third one remains in the log
+	_, err = os.Stat(filepath.Join(dataDir, solarPanelDataSnapshotFileName))
+	assert.NoError(t, err)
+	logContent, err := os.ReadFile(filepath.Join(dataDir, solarPanelDataLogFileName))
+	assert.NoError(t, err)
+	assert.Contains(t, string(logContent), thirdId)
+	assert.NotContains(t, string(logContent), firstId)
+
+	reopenedRepo := newTestFileSolarPanelDataRepository(t, dataDir, 2)
+	defer reopenedRepo.Close()
+
+	for _, id := range []string{firstId, secondId, thirdId} {
+		actual, err := reopenedRepo.GetSolarPanelData(id)
+		assert.NoError(t, err)
+		assert.Equal(t, solarPanelData.Solar, actual.Solar)
+		assert.Equal(t, 1, actual.Version)
+	}
+}
+
+func TestFileSolarPanelDataRepository_FailedSnapshot(t *testing.T) {
+	dataDir := t.TempDir()
+	repo := newTestFileSolarPanelDataRepository(t, dataDir, 1)
+
+	// a directory in the way of the temporary snapshot file fails every snapshot
+	err := os.Mkdir(filepath.Join(dataDir, solarPanelDataSnapshotFileName+".tmp"), 0o750)
+	assert.NoError(t, err)
+
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+			},
+		},
+	}
+
+	// the change is in the log already, so it succeeds
+	id, err := repo.CreateSolarPanelData(solarPanelData)
+	assert.NoError(t, err)
+	err = repo.UpdateSolarPanelData(id, solarPanelData)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Close())
+
+	reopenedRepo := newTestFileSolarPanelDataRepository(t, dataDir, 1)
+	defer reopenedRepo.Close()
+
+	actual, err := reopenedRepo.GetSolarPanelData(id)
+	assert.NoError(t, err)
+	assert.Equal(t, solarPanelData.Solar, actual.Solar)
+	assert.Equal(t, 2, actual.Version)
+}
+
+func TestFileSolarPanelDataRepository_ReplayTruncatedLog(t *testing.T) {
+	dataDir := t.TempDir()
+	repo := newTestFileSolarPanelDataRepository(t, dataDir, 0)
+
+	insertedId, err := repo.CreateSolarPanelData(&domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+			},
+		},
+		Wind: nil,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Close())
+
+	// simulate a crash in the middle of writing a record
+	logFile, err := os.OpenFile(filepath.Join(dataDir, solarPanelDataLogFileName), os.O_WRONLY|os.O_APPEND, 0o600)
+	assert.NoError(t, err)
+	_, err = logFile.WriteString(`{"op":"put","id":"half-writt`)
+	assert.NoError(t, err)
+	assert.NoError(t, logFile.Close())
+
+	reopenedRepo := newTestFileSolarPanelDataRepository(t, dataDir, 0)
+
+	_, err = reopenedRepo.GetSolarPanelData(insertedId)
+	assert.NoError(t, err)
+
+	// new records must not be glued to the dropped partial one
+	secondId, err := reopenedRepo.CreateSolarPanelData(&domain.SolarPanelData{})
+	assert.NoError(t, err)
+	assert.NoError(t, reopenedRepo.Close())
+
+	lastRepo := newTestFileSolarPanelDataRepository(t, dataDir, 0)
+	defer lastRepo.Close()
+
+	_, err = lastRepo.GetSolarPanelData(secondId)
+	assert.NoError(t, err)
+}
+
+func TestFileSolarPanelDataRepository_FailedAppend(t *testing.T) {
+	dataDir := t.TempDir()
+	repo := newTestFileSolarPanelDataRepository(t, dataDir, 0)
+
+	firstId, err := repo.CreateSolarPanelData(&domain.SolarPanelData{})
+	assert.NoError(t, err)
+
+	// a read-only log file fails every write
+	writableLogFile := repo.logFile
+	repo.logFile, err = os.Open(filepath.Join(dataDir, solarPanelDataLogFileName))
+	assert.NoError(t, err)
+
+	err = repo.DeleteSolarPanelData(firstId)
+	assert.Error(t, err)
+	_, err = repo.GetSolarPanelData(firstId)
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.logFile.Close())
+	repo.logFile = writableLogFile
+
+	secondId, err := repo.CreateSolarPanelData(&domain.SolarPanelData{})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Close())
+
+	reopenedRepo := newTestFileSolarPanelDataRepository(t, dataDir, 0)
+	defer reopenedRepo.Close()
+
+	for _, id := range []string{firstId, secondId} {
+		_, err = reopenedRepo.GetSolarPanelData(id)
+		assert.NoError(t, err)
+	}
+}
+
+func TestFileSolarPanelDataRepository_ReplayPutWithoutData(t *testing.T) {
+	dataDir := t.TempDir()
+
+	err := os.WriteFile(
+		filepath.Join(dataDir, solarPanelDataLogFileName),
+		[]byte(`{"op":"put","id":"uuid1"}`+"\n"),
+		0o600,
+	)
+	assert.NoError(t, err)
+
+	_, err = NewFileSolarPanelDataRepository(dataDir, 0, logrus.New())
+
+	assert.EqualError(t, err, "put of uuid1 without data in solar panel data log")
+}
+
+func TestFileSolarPanelDataRepository_LoadLegacyFormat(t *testing.T) {
+	dataDir := t.TempDir()
+
+	// written before events were typed, when the events of both sources were
+	// kept as submitted and wind could be any JSON value
+	err := os.WriteFile(
+		filepath.Join(dataDir, solarPanelDataSnapshotFileName),
+		[]byte(`{"uuid1":{"Solar":{"uuid1":[["20220101T010000Z","1"]]},"Wind":null}}`+"\n"),
+		0o600,
+	)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(
+		filepath.Join(dataDir, solarPanelDataLogFileName),
+		[]byte(`{"op":"put","id":"uuid2","data":{"Solar":{"uuid1":[["20220101T010000Z","1"],["timestamp1","event1"]]},"Wind":{"turbine1":[["20220101T010000Z","2"]]}}}`+"\n"+
+			`{"op":"put","id":"uuid3","data":{"Solar":{},"Wind":"calm"}}`+"\n"),
+		0o600,
+	)
+	assert.NoError(t, err)
+
+	repo := newTestFileSolarPanelDataRepository(t, dataDir, 1)
+
+	actual, err := repo.GetSolarPanelData("uuid1")
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+			},
+		},
+	}, actual)
+
+	actual, err = repo.GetSolarPanelData("uuid2")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]domain.Event{
+		"uuid1": []domain.Event{
+			{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+		},
+	}, actual.Solar)
+	assert.Equal(t, map[string][]domain.Event{
+		"turbine1": []domain.Event{
+			{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 2},
+		},
+	}, actual.Wind)
+	assert.Len(t, actual.MalformedEvents, 1)
+	assert.Equal(t, domain.EventSourceSolar, actual.MalformedEvents[0].Source)
+	assert.Equal(t, "uuid1", actual.MalformedEvents[0].ParameterId)
+	assert.Equal(t, 1, actual.MalformedEvents[0].Position)
+
+	actual, err = repo.GetSolarPanelData("uuid3")
+	assert.NoError(t, err)
+	assert.Nil(t, actual.Wind)
+	assert.Len(t, actual.MalformedEvents, 1)
+	assert.Equal(t, domain.EventSourceWind, actual.MalformedEvents[0].Source)
+
+	// a snapshot rewrites every dataset, which must keep the malformed events
+	_, err = repo.CreateSolarPanelData(&domain.SolarPanelData{})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Close())
+
+	snapshot, err := os.ReadFile(filepath.Join(dataDir, solarPanelDataSnapshotFileName))
+	assert.NoError(t, err)
+	assert.Contains(t, string(snapshot), `["timestamp1","event1"]`)
+	assert.Contains(t, string(snapshot), `"Wind":"calm"`)
+
+	reopenedRepo := newTestFileSolarPanelDataRepository(t, dataDir, 0)
+	defer reopenedRepo.Close()
+
+	actual, err = reopenedRepo.GetSolarPanelData("uuid2")
+	assert.NoError(t, err)
+	assert.Len(t, actual.MalformedEvents, 1)
+}
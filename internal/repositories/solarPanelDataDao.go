@@ -1,8 +1,233 @@
 package repositories
 
+import (
+	"encoding/json"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"time"
+)
+
 type SolarPanelDataDB map[string]*SolarPanelData
 
 type SolarPanelData struct {
-	Solar map[string][][]string
-	Wind  interface{}
+	Solar     map[string][]domain.Event
+	Wind      map[string][]domain.Event
+	Name      string
+	Site      string
+	Tags      []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int
+
+	// malformedEvents are the persisted events that could not be parsed back.
+	// The series they are in are kept as they were persisted, in legacySolar
+	// and legacyWind, so that writing the dataset again does not drop them.
+	malformedEvents []domain.MalformedEvent
+	legacySolar     map[string][][]string
+	legacyWind      json.RawMessage
+}
+
+// solarPanelDataRecord is the form a dataset is persisted in by the file
+// repository, with its events in the [timestamp, value] form they are submitted
+// in, as they have been persisted since before they were typed. Wind persisted
+// before it was typed may be any JSON value.
+type solarPanelDataRecord struct {
+	Solar     map[string][][]string
+	Wind      json.RawMessage
+	Name      string
+	Site      string
+	Tags      []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int
+}
+
+func (dao *SolarPanelData) MarshalJSON() ([]byte, error) {
+	record := solarPanelDataRecord{
+		Solar:     domain.FormatEventSeries(dao.Solar),
+		Wind:      dao.legacyWind,
+		Name:      dao.Name,
+		Site:      dao.Site,
+		Tags:      dao.Tags,
+		CreatedAt: dao.CreatedAt,
+		UpdatedAt: dao.UpdatedAt,
+		Version:   dao.Version,
+	}
+
+	for parameterId, pairs := range dao.legacySolar {
+		record.Solar[parameterId] = pairs
+	}
+
+	if record.Wind == nil {
+		wind, err := json.Marshal(domain.FormatEventSeries(dao.Wind))
+		if err != nil {
+			return nil, err
+		}
+
+		record.Wind = wind
+	}
+
+	return json.Marshal(record)
+}
+
+// UnmarshalJSON parses the events back, leaving the ones that cannot be parsed
+// out of the series and reporting them as malformed, as the SQLite repository
+// does for the rows it stored before events were validated.
+func (dao *SolarPanelData) UnmarshalJSON(data []byte) error {
+	record := solarPanelDataRecord{}
+
+	err := json.Unmarshal(data, &record)
+	if err != nil {
+		return err
+	}
+
+	*dao = SolarPanelData{
+		Name:      record.Name,
+		Site:      record.Site,
+		Tags:      record.Tags,
+		CreatedAt: record.CreatedAt,
+		UpdatedAt: record.UpdatedAt,
+		Version:   record.Version,
+	}
+
+	dao.Solar, dao.malformedEvents = domain.ParseEventSeries(domain.EventSourceSolar, record.Solar)
+	for _, malformedEvent := range dao.malformedEvents {
+		if dao.legacySolar == nil {
+			dao.legacySolar = map[string][][]string{}
+		}
+
+		dao.legacySolar[malformedEvent.ParameterId] = record.Solar[malformedEvent.ParameterId]
+	}
+
+	if len(record.Wind) == 0 {
+		return nil
+	}
+
+	var pairsPerTurbineId map[string][][]string
+
+	err = json.Unmarshal(record.Wind, &pairsPerTurbineId)
+	if err != nil {
+		dao.legacyWind = record.Wind
+		dao.malformedEvents = append(dao.malformedEvents, domain.MalformedEvent{
+			Source:        domain.EventSourceWind,
+			OriginalError: err,
+		})
+
+		return nil
+	}
+
+	var malformedWindEvents []domain.MalformedEvent
+
+	dao.Wind, malformedWindEvents = domain.ParseEventSeries(domain.EventSourceWind, pairsPerTurbineId)
+	if len(malformedWindEvents) > 0 {
+		dao.legacyWind = record.Wind
+		dao.malformedEvents = append(dao.malformedEvents, malformedWindEvents...)
+	}
+
+	return nil
+}
+
+// newSolarPanelDataDao copies the dataset, so that the caller changing it
+// afterwards does not change the stored one.
+func newSolarPanelDataDao(solarPanelData *domain.SolarPanelData) *SolarPanelData {
+	return &SolarPanelData{
+		Solar: copyEventSeries(solarPanelData.Solar),
+		Wind:  copyEventSeries(solarPanelData.Wind),
+		Name:  solarPanelData.Name,
+		Site:  solarPanelData.Site,
+		Tags:  copyTags(solarPanelData.Tags),
+	}
+}
+
+// toDomain copies the stored dataset, for the same reason as
+// newSolarPanelDataDao.
+func (dao *SolarPanelData) toDomain() *domain.SolarPanelData {
+	return &domain.SolarPanelData{
+		Solar:     copyEventSeries(dao.Solar),
+		Wind:      copyEventSeries(dao.Wind),
+		Name:      dao.Name,
+		Site:      dao.Site,
+		Tags:      copyTags(dao.Tags),
+		CreatedAt: dao.CreatedAt,
+		UpdatedAt: dao.UpdatedAt,
+		Version:   dao.Version,
+
+		MalformedEvents: dao.malformedEvents,
+	}
+}
+
+func copyEventSeries(eventsPerParameterId map[string][]domain.Event) map[string][]domain.Event {
+	if eventsPerParameterId == nil {
+		return nil
+	}
+
+	copied := make(map[string][]domain.Event, len(eventsPerParameterId))
+	for parameterId, events := range eventsPerParameterId {
+		copied[parameterId] = append(make([]domain.Event, 0, len(events)), events...)
+	}
+
+	return copied
+}
+
+func copyTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	return append(make([]string, 0, len(tags)), tags...)
+}
+
+// setServerManagedFields copies the fields the repository manages back to the
+// dataset the client submitted.
+func (dao *SolarPanelData) setServerManagedFields(solarPanelData *domain.SolarPanelData) {
+	solarPanelData.CreatedAt = dao.CreatedAt
+	solarPanelData.UpdatedAt = dao.UpdatedAt
+	solarPanelData.Version = dao.Version
+}
+
+func (dao *SolarPanelData) toSummary(uuid string) domain.SolarPanelDataSummary {
+	eventCount := 0
+	for _, events := range dao.Solar {
+		eventCount += len(events)
+	}
+
+	return domain.SolarPanelDataSummary{
+		Id:             uuid,
+		Name:           dao.Name,
+		Site:           dao.Site,
+		Tags:           dao.Tags,
+		CreatedAt:      dao.CreatedAt,
+		UpdatedAt:      dao.UpdatedAt,
+		Version:        dao.Version,
+		ParameterCount: len(dao.Solar),
+		EventCount:     eventCount,
+	}
+}
+
+// matches reports whether the dataset passes the filters of the list options.
+func (dao *SolarPanelData) matches(options *domain.SolarPanelDataListOptions) bool {
+	if options.Name != "" && dao.Name != options.Name {
+		return false
+	}
+
+	if options.Site != "" && dao.Site != options.Site {
+		return false
+	}
+
+	for _, wantedTag := range options.Tags {
+		if !containsTag(dao.Tags, wantedTag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsTag(tags []string, wantedTag string) bool {
+	for _, tag := range tags {
+		if tag == wantedTag {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"sort"
+)
+
+// listSolarPanelData pages through an in-memory database. It is shared by the
+// repositories that keep all the datasets in a SolarPanelDataDB and must be
+// called with their read lock held.
+func listSolarPanelData(
+	db SolarPanelDataDB,
+	options *domain.SolarPanelDataListOptions,
+) *domain.SolarPanelDataPage {
+	summaries := make([]domain.SolarPanelDataSummary, 0, len(db))
+	for uuid, dao := range db {
+		if dao.matches(options) {
+			summaries = append(summaries, dao.toSummary(uuid))
+		}
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return isListedBefore(summaries[i], summaries[j], options.Descending)
+	})
+
+	start := 0
+	if options.After != nil {
+		after := domain.SolarPanelDataSummary{
+			Id:        options.After.Id,
+			CreatedAt: options.After.CreatedAt,
+		}
+
+		start = sort.Search(len(summaries), func(i int) bool {
+			return isListedBefore(after, summaries[i], options.Descending)
+		})
+	}
+
+	summaries = summaries[start:]
+
+	page := &domain.SolarPanelDataPage{
+		Items: summaries,
+	}
+
+	if options.Limit > 0 && len(summaries) > options.Limit {
+		page.Items = summaries[:options.Limit]
+		page.HasMore = true
+	}
+
+	return page
+}
+
+func isListedBefore(a, b domain.SolarPanelDataSummary, descending bool) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt) != descending
+	}
+
+	if a.Id == b.Id {
+		return false
+	}
+
+	return (a.Id < b.Id) != descending
+}
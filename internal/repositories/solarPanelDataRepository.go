@@ -6,10 +6,15 @@ import (
 	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
 	"github.com/loukaspe/solar-panel-data-crud/pkg/errors"
 	"net/http"
+	"sync"
+	"time"
 )
 
+// SolarPanelDataRepository keeps the datasets in memory. It is safe for
+// concurrent use by the HTTP handlers.
 type SolarPanelDataRepository struct {
-	db SolarPanelDataDB
+	mutex sync.RWMutex
+	db    SolarPanelDataDB
 }
 
 func NewSolarPanelDataRepository(db SolarPanelDataDB) *SolarPanelDataRepository {
@@ -17,19 +22,26 @@ func NewSolarPanelDataRepository(db SolarPanelDataDB) *SolarPanelDataRepository
 }
 
 func (repo *SolarPanelDataRepository) CreateSolarPanelData(solarPanelData *domain.SolarPanelData) (string, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
 	insertedId := uuid.New().String()
 
-	dao := SolarPanelData{
-		Solar: solarPanelData.Solar,
-		Wind:  solarPanelData.Wind,
-	}
+	dao := newSolarPanelDataDao(solarPanelData)
+	dao.CreatedAt = time.Now().UTC()
+	dao.UpdatedAt = dao.CreatedAt
+	dao.Version = 1
 
-	repo.db[insertedId] = &dao
+	repo.db[insertedId] = dao
+	dao.setServerManagedFields(solarPanelData)
 
 	return insertedId, nil
 }
 
 func (repo *SolarPanelDataRepository) GetSolarPanelData(uuid string) (*domain.SolarPanelData, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
 	var err error
 
 	retrievedSolarPanelData, exists := repo.db[uuid]
@@ -42,33 +54,53 @@ func (repo *SolarPanelDataRepository) GetSolarPanelData(uuid string) (*domain.So
 			}
 	}
 
-	return &domain.SolarPanelData{
-		Solar: retrievedSolarPanelData.Solar,
-		Wind:  retrievedSolarPanelData.Wind,
-	}, err
+	return retrievedSolarPanelData.toDomain(), err
+}
+
+func (repo *SolarPanelDataRepository) ListSolarPanelData(
+	options *domain.SolarPanelDataListOptions,
+) (*domain.SolarPanelDataPage, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	return listSolarPanelData(repo.db, options), nil
 }
 
 func (repo *SolarPanelDataRepository) UpdateSolarPanelData(uuid string, solarPanelData *domain.SolarPanelData) error {
-	_, exists := repo.db[uuid]
+	return repo.update(uuid, solarPanelData, 0)
+}
 
-	if !exists {
-		return &apierrors.DataNotFoundErrorWrapper{
-			ReturnedStatusCode: http.StatusNotFound,
-			OriginalError:      errors.New("uuid " + uuid + " not found"),
-		}
-	}
+func (repo *SolarPanelDataRepository) UpdateSolarPanelDataIfVersion(
+	uuid string,
+	solarPanelData *domain.SolarPanelData,
+	version int,
+) error {
+	return repo.update(uuid, solarPanelData, version)
+}
 
-	dao := SolarPanelData{
-		Solar: solarPanelData.Solar,
-		Wind:  solarPanelData.Wind,
+func (repo *SolarPanelDataRepository) update(
+	uuid string,
+	solarPanelData *domain.SolarPanelData,
+	expectedVersion int,
+) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	dao, err := updatedSolarPanelData(repo.db, uuid, solarPanelData, expectedVersion)
+	if err != nil {
+		return err
 	}
 
-	repo.db[uuid] = &dao
+	repo.db[uuid] = dao
+	dao.setServerManagedFields(solarPanelData)
 
 	return nil
 }
 
 func (repo *SolarPanelDataRepository) DeleteSolarPanelData(uuid string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
 	delete(repo.db, uuid)
 
 	return nil
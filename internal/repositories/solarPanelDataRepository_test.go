@@ -3,10 +3,13 @@ package repositories
 import (
 	"errors"
 	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/ports"
 	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"net/http"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestSolarPanelDataRepository_CreateSolarPanelData(t *testing.T) {
@@ -14,8 +17,6 @@ func TestSolarPanelDataRepository_CreateSolarPanelData(t *testing.T) {
 		solarPanelData *domain.SolarPanelData
 	}
 
-	mockDb := SolarPanelDataDB{}
-
 	tests := []struct {
 		name                   string
 		args                   args
@@ -26,44 +27,73 @@ func TestSolarPanelDataRepository_CreateSolarPanelData(t *testing.T) {
 			name: "creation ok",
 			args: args{
 				solarPanelData: &domain.SolarPanelData{
-					Solar: map[string][][]string{
-						"uuid1": [][]string{
-							{"timestamp1", "event1"},
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 						},
 					},
 					Wind: nil,
 				},
 			},
 			expectedSolarPanelData: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"uuid1": [][]string{
-						{"timestamp1", "event1"},
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 					},
 				},
-				Wind: nil,
+				Wind:    nil,
+				Version: 1,
+			},
+			expectError: false,
+		},
+		{
+			name: "creation ok with wind",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{},
+					Wind: map[string][]domain.Event{
+						"turbine1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 12.5},
+						},
+					},
+				},
+			},
+			expectedSolarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{},
+				Wind: map[string][]domain.Event{
+					"turbine1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 12.5},
+					},
+				},
+				Version: 1,
 			},
 			expectError: false,
 		},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			repo := &SolarPanelDataRepository{
-				db: mockDb,
-			}
+	for name, newRepository := range solarPanelDataRepositoryConstructors() {
+		t.Run(name, func(t *testing.T) {
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					repo, db := newRepository(t, SolarPanelDataDB{})
 
-			actualInsertedId, err := repo.CreateSolarPanelData(tt.args.solarPanelData)
-			if (err != nil) != tt.expectError {
-				t.Errorf("CreateSolarPanelData() error = %v, expectError %v", err, tt.expectError)
-				return
-			}
+					actualInsertedId, err := repo.CreateSolarPanelData(tt.args.solarPanelData)
+					if (err != nil) != tt.expectError {
+						t.Errorf("CreateSolarPanelData() error = %v, expectError %v", err, tt.expectError)
+						return
+					}
 
-			actual, ok := mockDb[actualInsertedId]
-			if !ok {
-				t.Errorf("data with uuid %s not found", actualInsertedId)
-				return
-			}
+					actual, ok := db[actualInsertedId]
+					if !ok {
+						t.Errorf("data with uuid %s not found", actualInsertedId)
+						return
+					}
 
-			assert.EqualValues(t, tt.expectedSolarPanelData, actual)
+					assert.EqualValues(t, tt.expectedSolarPanelData, withoutTimestamps(actual.toDomain()))
+					assert.False(t, actual.CreatedAt.IsZero())
+					assert.Equal(t, actual.CreatedAt, actual.UpdatedAt)
+					assert.Equal(t, actual.toDomain(), tt.args.solarPanelData)
+				})
+			}
 		})
 	}
 }
@@ -93,9 +123,9 @@ func TestSolarPanelDataRepository_GetSolarPanelData(t *testing.T) {
 			fields: fields{
 				db: SolarPanelDataDB{
 					"uuid": {
-						Solar: map[string][][]string{
-							"uuid1": [][]string{
-								{"timestamp1", "event1"},
+						Solar: map[string][]domain.Event{
+							"uuid1": []domain.Event{
+								{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 							},
 						},
 						Wind: nil,
@@ -103,9 +133,9 @@ func TestSolarPanelDataRepository_GetSolarPanelData(t *testing.T) {
 				},
 			},
 			expected: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"uuid1": [][]string{
-						{"timestamp1", "event1"},
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 					},
 				},
 				Wind: nil,
@@ -120,9 +150,9 @@ func TestSolarPanelDataRepository_GetSolarPanelData(t *testing.T) {
 			fields: fields{
 				db: SolarPanelDataDB{
 					"uuid": {
-						Solar: map[string][][]string{
-							"uuid1": [][]string{
-								{"timestamp1", "event1"},
+						Solar: map[string][]domain.Event{
+							"uuid1": []domain.Event{
+								{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 							},
 						},
 						Wind: nil,
@@ -137,21 +167,24 @@ func TestSolarPanelDataRepository_GetSolarPanelData(t *testing.T) {
 			expectError: true,
 		},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			repo := &SolarPanelDataRepository{
-				db: tt.fields.db,
-			}
-			actual, actualError := repo.GetSolarPanelData(tt.args.uuid)
-			if (actualError != nil) != tt.expectError {
-				t.Errorf("GetSolarPanelData() error = %v, expectError %v", actualError, tt.expectError)
-				return
-			}
+	for name, newRepository := range solarPanelDataRepositoryConstructors() {
+		t.Run(name, func(t *testing.T) {
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					repo, _ := newRepository(t, tt.fields.db)
+
+					actual, actualError := repo.GetSolarPanelData(tt.args.uuid)
+					if (actualError != nil) != tt.expectError {
+						t.Errorf("GetSolarPanelData() error = %v, expectError %v", actualError, tt.expectError)
+						return
+					}
 
-			assert.Equal(t, tt.expected, actual)
+					assert.Equal(t, tt.expected, actual)
 
-			if tt.expectError {
-				assert.Equal(t, tt.expectedError, actualError)
+					if tt.expectError {
+						assert.Equal(t, tt.expectedError, actualError)
+					}
+				})
 			}
 		})
 	}
@@ -163,14 +196,19 @@ func TestSolarPanelDataRepository_UpdateSolarPanelData(t *testing.T) {
 		solarPanelData *domain.SolarPanelData
 	}
 
-	mockDb := SolarPanelDataDB{
+	createdAt := time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC)
+
+	seed := SolarPanelDataDB{
 		"uuid1": &SolarPanelData{
-			Solar: map[string][][]string{
-				"uuid1": [][]string{
-					{"timestamp1", "event1"},
+			Solar: map[string][]domain.Event{
+				"uuid1": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 				},
 			},
-			Wind: nil,
+			Wind:      nil,
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+			Version:   1,
 		},
 	}
 
@@ -186,21 +224,22 @@ func TestSolarPanelDataRepository_UpdateSolarPanelData(t *testing.T) {
 			args: args{
 				uuid: "uuid1",
 				solarPanelData: &domain.SolarPanelData{
-					Solar: map[string][][]string{
-						"uuid1": [][]string{
-							{"timestamp2", "event2"},
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
 						},
 					},
 					Wind: nil,
 				},
 			},
 			expectedSolarPanelData: &domain.SolarPanelData{
-				Solar: map[string][][]string{
-					"uuid1": [][]string{
-						{"timestamp2", "event2"},
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
 					},
 				},
-				Wind: nil,
+				Wind:    nil,
+				Version: 2,
 			},
 			expectError: false,
 		},
@@ -209,9 +248,9 @@ func TestSolarPanelDataRepository_UpdateSolarPanelData(t *testing.T) {
 			args: args{
 				uuid: "uuidNotExisting",
 				solarPanelData: &domain.SolarPanelData{
-					Solar: map[string][][]string{
-						"uuid1": [][]string{
-							{"timestamp1", "event1"},
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 						},
 					},
 					Wind: nil,
@@ -222,30 +261,150 @@ func TestSolarPanelDataRepository_UpdateSolarPanelData(t *testing.T) {
 			expectedErrorMessage: "",
 		},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			repo := &SolarPanelDataRepository{
-				db: mockDb,
-			}
+	for name, newRepository := range solarPanelDataRepositoryConstructors() {
+		t.Run(name, func(t *testing.T) {
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					repo, db := newRepository(t, seed)
 
-			err := repo.UpdateSolarPanelData(tt.args.uuid, tt.args.solarPanelData)
-			if (err != nil) != tt.expectError {
-				t.Errorf("CreateSolarPanelData() error = %v, expectError %v", err, tt.expectError)
-				return
-			}
+					err := repo.UpdateSolarPanelData(tt.args.uuid, tt.args.solarPanelData)
+					if (err != nil) != tt.expectError {
+						t.Errorf("CreateSolarPanelData() error = %v, expectError %v", err, tt.expectError)
+						return
+					}
 
-			if tt.expectError {
-				assert.Equal(t, tt.expectedErrorMessage, err.Error())
-				return
-			}
+					if tt.expectError {
+						assert.Equal(t, tt.expectedErrorMessage, err.Error())
+						return
+					}
+
+					actual, ok := db[tt.args.uuid]
+					if !ok {
+						t.Errorf("data with uuid %s not found", tt.args.uuid)
+						return
+					}
 
-			actual, ok := mockDb[tt.args.uuid]
-			if !ok {
-				t.Errorf("data with uuid %s not found", tt.args.uuid)
-				return
+					assert.EqualValues(t, tt.expectedSolarPanelData, withoutTimestamps(actual.toDomain()))
+					assert.Equal(t, createdAt, actual.CreatedAt)
+					assert.True(t, actual.UpdatedAt.After(createdAt))
+					assert.Equal(t, actual.toDomain(), tt.args.solarPanelData)
+				})
 			}
+		})
+	}
+}
 
-			assert.EqualValues(t, tt.expectedSolarPanelData, actual)
+func TestSolarPanelDataRepository_UpdateSolarPanelDataIfVersion(t *testing.T) {
+	type args struct {
+		uuid            string
+		solarPanelData  *domain.SolarPanelData
+		expectedVersion int
+	}
+
+	createdAt := time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                   string
+		args                   args
+		expectedSolarPanelData *domain.SolarPanelData
+		expectError            bool
+		expectedErrorMessage   string
+	}{
+		{
+			name: "update ok at the expected version",
+			args: args{
+				uuid: "uuid1",
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+						},
+					},
+					Wind: nil,
+				},
+				expectedVersion: 1,
+			},
+			expectedSolarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+					},
+				},
+				Wind:    nil,
+				Version: 2,
+			},
+			expectError: false,
+		},
+		{
+			name: "error changed since the expected version",
+			args: args{
+				uuid: "uuid1",
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+						},
+					},
+					Wind: nil,
+				},
+				expectedVersion: 5,
+			},
+			expectError:          true,
+			expectedErrorMessage: "solar panel data was changed by another request, try again",
+		},
+		{
+			name: "error data not found",
+			args: args{
+				uuid: "uuidNotExisting",
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+						},
+					},
+					Wind: nil,
+				},
+				expectedVersion: 1,
+			},
+			expectError: true,
+			// data not found error does not have an error message
+			expectedErrorMessage: "",
+		},
+	}
+	seed := SolarPanelDataDB{
+		"uuid1": &SolarPanelData{
+			Solar: map[string][]domain.Event{
+				"uuid1": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+				},
+			},
+			Wind:      nil,
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+			Version:   1,
+		},
+	}
+
+	for name, newRepository := range solarPanelDataRepositoryConstructors() {
+		t.Run(name, func(t *testing.T) {
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					repo, db := newRepository(t, seed)
+
+					err := repo.UpdateSolarPanelDataIfVersion(tt.args.uuid, tt.args.solarPanelData, tt.args.expectedVersion)
+					if (err != nil) != tt.expectError {
+						t.Errorf("UpdateSolarPanelDataIfVersion() error = %v, expectError %v", err, tt.expectError)
+						return
+					}
+
+					if tt.expectError {
+						assert.Equal(t, tt.expectedErrorMessage, err.Error())
+						return
+					}
+
+					assert.EqualValues(t, tt.expectedSolarPanelData, withoutTimestamps(db[tt.args.uuid].toDomain()))
+				})
+			}
 		})
 	}
 }
@@ -273,9 +432,9 @@ func TestSolarPanelDataRepository_DeleteSolarPanelData(t *testing.T) {
 			fields: fields{
 				db: SolarPanelDataDB{
 					"uuid": {
-						Solar: map[string][][]string{
-							"uuid1": [][]string{
-								{"timestamp1", "event1"},
+						Solar: map[string][]domain.Event{
+							"uuid1": []domain.Event{
+								{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 							},
 						},
 						Wind: nil,
@@ -284,15 +443,332 @@ func TestSolarPanelDataRepository_DeleteSolarPanelData(t *testing.T) {
 			},
 			expected: nil,
 		},
+		{
+			name: "delete not existing",
+			args: args{
+				uuid: "uuidNotExisting",
+			},
+			fields: fields{
+				db: SolarPanelDataDB{},
+			},
+			expected: nil,
+		},
+	}
+	for name, newRepository := range solarPanelDataRepositoryConstructors() {
+		t.Run(name, func(t *testing.T) {
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					repo, db := newRepository(t, tt.fields.db)
+
+					actual := repo.DeleteSolarPanelData(tt.args.uuid)
+
+					assert.Equal(t, tt.expected, actual)
+					assert.NotContains(t, db, tt.args.uuid)
+				})
+			}
+		})
+	}
+}
+
+func TestSolarPanelDataRepository_ListSolarPanelData(t *testing.T) {
+	firstCreatedAt := time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC)
+	secondCreatedAt := time.Date(2022, 1, 2, 6, 0, 0, 0, time.UTC)
+
+	db := SolarPanelDataDB{
+		"uuid3": {
+			Solar: map[string][]domain.Event{
+				"uuid1": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+					{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+				},
+				"uuid2": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+				},
+			},
+			Name:      "roof",
+			Site:      "athens",
+			Tags:      []string{"south", "inverter-a"},
+			CreatedAt: secondCreatedAt,
+			UpdatedAt: secondCreatedAt,
+			Version:   1,
+		},
+		"uuid2": {
+			Solar:     map[string][]domain.Event{},
+			Site:      "athens",
+			Tags:      []string{"south"},
+			CreatedAt: firstCreatedAt,
+			UpdatedAt: secondCreatedAt,
+			Version:   2,
+		},
+		"uuid1": {
+			Solar:     map[string][]domain.Event{},
+			CreatedAt: firstCreatedAt,
+			UpdatedAt: firstCreatedAt,
+		},
+	}
+
+	first := domain.SolarPanelDataSummary{
+		Id:        "uuid1",
+		CreatedAt: firstCreatedAt,
+		UpdatedAt: firstCreatedAt,
+	}
+	second := domain.SolarPanelDataSummary{
+		Id:        "uuid2",
+		Site:      "athens",
+		Tags:      []string{"south"},
+		CreatedAt: firstCreatedAt,
+		UpdatedAt: secondCreatedAt,
+		Version:   2,
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			repo := &SolarPanelDataRepository{
-				db: tt.fields.db,
+	third := domain.SolarPanelDataSummary{
+		Id:             "uuid3",
+		Name:           "roof",
+		Site:           "athens",
+		Tags:           []string{"south", "inverter-a"},
+		CreatedAt:      secondCreatedAt,
+		UpdatedAt:      secondCreatedAt,
+		Version:        1,
+		ParameterCount: 2,
+		EventCount:     3,
+	}
+
+	tests := []struct {
+		name     string
+		options  *domain.SolarPanelDataListOptions
+		expected *domain.SolarPanelDataPage
+	}{
+		{
+			name:    "all ascending",
+			options: &domain.SolarPanelDataListOptions{Limit: 10},
+			expected: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{first, second, third},
+			},
+		},
+		{
+			name:    "first page",
+			options: &domain.SolarPanelDataListOptions{Limit: 2},
+			expected: &domain.SolarPanelDataPage{
+				Items:   []domain.SolarPanelDataSummary{first, second},
+				HasMore: true,
+			},
+		},
+		{
+			name: "page after cursor with the same creation time",
+			options: &domain.SolarPanelDataListOptions{
+				After: &domain.SolarPanelDataListCursor{CreatedAt: firstCreatedAt, Id: "uuid1"},
+				Limit: 2,
+			},
+			expected: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{second, third},
+			},
+		},
+		{
+			name:    "descending",
+			options: &domain.SolarPanelDataListOptions{Limit: 2, Descending: true},
+			expected: &domain.SolarPanelDataPage{
+				Items:   []domain.SolarPanelDataSummary{third, second},
+				HasMore: true,
+			},
+		},
+		{
+			name: "descending after cursor",
+			options: &domain.SolarPanelDataListOptions{
+				After:      &domain.SolarPanelDataListCursor{CreatedAt: firstCreatedAt, Id: "uuid2"},
+				Limit:      2,
+				Descending: true,
+			},
+			expected: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{first},
+			},
+		},
+		{
+			name: "cursor past the end",
+			options: &domain.SolarPanelDataListOptions{
+				After: &domain.SolarPanelDataListCursor{CreatedAt: secondCreatedAt, Id: "uuid3"},
+				Limit: 2,
+			},
+			expected: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{},
+			},
+		},
+		{
+			name:    "filtered by site",
+			options: &domain.SolarPanelDataListOptions{Limit: 10, Site: "athens"},
+			expected: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{second, third},
+			},
+		},
+		{
+			name:    "filtered by name and all tags",
+			options: &domain.SolarPanelDataListOptions{Limit: 10, Name: "roof", Tags: []string{"inverter-a", "south"}},
+			expected: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{third},
+			},
+		},
+		{
+			name:    "filtered by page of tag matches",
+			options: &domain.SolarPanelDataListOptions{Limit: 1, Tags: []string{"south"}},
+			expected: &domain.SolarPanelDataPage{
+				Items:   []domain.SolarPanelDataSummary{second},
+				HasMore: true,
+			},
+		},
+	}
+	for name, newRepository := range solarPanelDataRepositoryConstructors() {
+		t.Run(name, func(t *testing.T) {
+			repo, _ := newRepository(t, db)
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					actual, err := repo.ListSolarPanelData(tt.options)
+
+					assert.NoError(t, err)
+					assert.Equal(t, tt.expected, actual)
+				})
+			}
+		})
+	}
+}
+
+func TestSolarPanelDataRepository_CopiesDatasets(t *testing.T) {
+	for name, newRepository := range solarPanelDataRepositoryConstructors() {
+		t.Run(name, func(t *testing.T) {
+			repo, _ := newRepository(t, SolarPanelDataDB{})
+
+			submitted := &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+					},
+				},
+				Tags: []string{"south"},
+			}
+
+			id, err := repo.CreateSolarPanelData(submitted)
+			assert.NoError(t, err)
+
+			// changing the submitted dataset does not change the stored one
+			submitted.Solar["uuid1"][0].Value = 2
+			submitted.Solar["uuid2"] = []domain.Event{}
+			submitted.Tags[0] = "north"
+
+			actual, err := repo.GetSolarPanelData(id)
+			assert.NoError(t, err)
+			assert.Equal(t, map[string][]domain.Event{
+				"uuid1": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+				},
+			}, actual.Solar)
+			assert.Equal(t, []string{"south"}, actual.Tags)
+
+			// nor does changing a read one
+			actual.Solar["uuid1"][0].Value = 3
+			actual.Tags[0] = "east"
+
+			actual, err = repo.GetSolarPanelData(id)
+			assert.NoError(t, err)
+			assert.Equal(t, 1.0, actual.Solar["uuid1"][0].Value)
+			assert.Equal(t, []string{"south"}, actual.Tags)
+		})
+	}
+}
+
+// TestSolarPanelDataRepository_ConcurrentAccess is meant to be run with -race.
+// It creates, reads, updates and deletes datasets from many goroutines at once.
+func TestSolarPanelDataRepository_ConcurrentAccess(t *testing.T) {
+	const workers = 16
+	const iterations = 50
+
+	for name, newRepository := range solarPanelDataRepositoryConstructors() {
+		t.Run(name, func(t *testing.T) {
+			repo, _ := newRepository(t, SolarPanelDataDB{})
+
+			solarPanelData := &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+					},
+				},
+				Wind: nil,
 			}
-			actual := repo.DeleteSolarPanelData(tt.args.uuid)
 
-			assert.Equal(t, tt.expected, actual)
+			sharedId, err := repo.CreateSolarPanelData(solarPanelData)
+			assert.NoError(t, err)
+
+			var waitGroup sync.WaitGroup
+			for worker := 0; worker < workers; worker++ {
+				waitGroup.Add(1)
+
+				go func() {
+					defer waitGroup.Done()
+
+					for i := 0; i < iterations; i++ {
+						insertedId, err := repo.CreateSolarPanelData(solarPanelData)
+						assert.NoError(t, err)
+
+						_, err = repo.GetSolarPanelData(insertedId)
+						assert.NoError(t, err)
+
+						assert.NoError(t, repo.UpdateSolarPanelData(insertedId, solarPanelData))
+						assert.NoError(t, repo.UpdateSolarPanelData(sharedId, solarPanelData))
+
+						_, err = repo.GetSolarPanelData(sharedId)
+						assert.NoError(t, err)
+
+						assert.NoError(t, repo.DeleteSolarPanelData(insertedId))
+					}
+				}()
+			}
+			waitGroup.Wait()
+
+			actual, err := repo.GetSolarPanelData(sharedId)
+			assert.NoError(t, err)
+			assert.Equal(t, solarPanelData, actual)
 		})
 	}
 }
+
+// solarPanelDataRepositoryConstructors build each repository that keeps all the
+// datasets in a SolarPanelDataDB, seeded with a copy of the given datasets, so
+// that the same behavioural tests run against all of them. The repository's own
+// database is returned as well, to check what was stored.
+func solarPanelDataRepositoryConstructors() map[string]func(
+	*testing.T,
+	SolarPanelDataDB,
+) (ports.SolarPanelDataRepositoryInterface, SolarPanelDataDB) {
+	return map[string]func(*testing.T, SolarPanelDataDB) (ports.SolarPanelDataRepositoryInterface, SolarPanelDataDB){
+		"memory": func(t *testing.T, seed SolarPanelDataDB) (ports.SolarPanelDataRepositoryInterface, SolarPanelDataDB) {
+			db := SolarPanelDataDB{}
+			for uuid, dao := range seed {
+				db[uuid] = dao
+			}
+
+			return NewSolarPanelDataRepository(db), db
+		},
+		"file": func(t *testing.T, seed SolarPanelDataDB) (ports.SolarPanelDataRepositoryInterface, SolarPanelDataDB) {
+			repo := newTestFileSolarPanelDataRepository(t, t.TempDir(), 50)
+			t.Cleanup(func() {
+				assert.NoError(t, repo.Close())
+			})
+
+			for uuid, dao := range seed {
+				err := repo.put(uuid, dao)
+				if err != nil {
+					t.Fatalf("put() error = %v", err)
+				}
+			}
+
+			return repo, repo.db
+		},
+	}
+}
+
+// withoutTimestamps returns a copy of the dataset with the server managed
+// timestamps cleared, so that it can be compared against fixed expectations.
+func withoutTimestamps(solarPanelData *domain.SolarPanelData) *domain.SolarPanelData {
+	copied := *solarPanelData
+	copied.CreatedAt = time.Time{}
+	copied.UpdatedAt = time.Time{}
+
+	return &copied
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"errors"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"net/http"
+	"time"
+)
+
+// updatedSolarPanelData returns the dao replacing a stored dataset, with its
+// server managed fields moved on. It is shared by the repositories that keep
+// all the datasets in a SolarPanelDataDB and must be called with their write
+// lock held.
+func updatedSolarPanelData(
+	db SolarPanelDataDB,
+	uuid string,
+	solarPanelData *domain.SolarPanelData,
+	expectedVersion int,
+) (*SolarPanelData, error) {
+	existingSolarPanelData, exists := db[uuid]
+
+	if !exists {
+		return nil, &apierrors.DataNotFoundErrorWrapper{
+			ReturnedStatusCode: http.StatusNotFound,
+			OriginalError:      errors.New("uuid " + uuid + " not found"),
+		}
+	}
+
+	if expectedVersion != 0 && existingSolarPanelData.Version != expectedVersion {
+		return nil, apierrors.VersionConflictError{
+			ReturnedStatusCode: http.StatusConflict,
+			ExpectedVersion:    expectedVersion,
+		}
+	}
+
+	dao := newSolarPanelDataDao(solarPanelData)
+	dao.CreatedAt = existingSolarPanelData.CreatedAt
+	dao.UpdatedAt = time.Now().UTC()
+	dao.Version = existingSolarPanelData.Version + 1
+
+	return dao, nil
+}
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+)
+
+// schemaMigrations are applied in order on start-up. Each one runs in its own
+// transaction and is recorded in schema_migrations, so a migration must never
+// be edited once released; add a new one instead.
+func schemaMigrations() []string {
+	return []string{
+		`
+		CREATE TABLE datasets (
+			id   TEXT PRIMARY KEY,
+			wind TEXT
+		);
+
+		CREATE TABLE parameters (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			dataset_id   TEXT NOT NULL REFERENCES datasets (id) ON DELETE CASCADE,
+			parameter_id TEXT NOT NULL,
+			UNIQUE (dataset_id, parameter_id)
+		);
+
+		CREATE TABLE events (
+			parameter_row_id INTEGER NOT NULL REFERENCES parameters (id) ON DELETE CASCADE,
+			position         INTEGER NOT NULL,
+			timestamp        TEXT,
+			value            TEXT,
+			PRIMARY KEY (parameter_row_id, position)
+		);
+		`,
+		// timestamps are stored as Unix nanoseconds so they sort numerically
+		`
+		ALTER TABLE datasets ADD COLUMN created_at INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE datasets ADD COLUMN updated_at INTEGER NOT NULL DEFAULT 0;
+
+		CREATE INDEX datasets_created_at_id ON datasets (created_at, id);
+		`,
+		`
+		ALTER TABLE datasets ADD COLUMN name TEXT NOT NULL DEFAULT '';
+		ALTER TABLE datasets ADD COLUMN site TEXT NOT NULL DEFAULT '';
+		ALTER TABLE datasets ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+		CREATE TABLE dataset_tags (
+			dataset_id TEXT NOT NULL REFERENCES datasets (id) ON DELETE CASCADE,
+			position   INTEGER NOT NULL,
+			tag        TEXT NOT NULL,
+			PRIMARY KEY (dataset_id, tag)
+		);
+
+		CREATE INDEX dataset_tags_tag ON dataset_tags (tag);
+		`,
+	}
+}
+
+func migrate(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			applied_at TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	var currentVersion int
+	err = db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&currentVersion)
+	if err != nil {
+		return err
+	}
+
+	migrations := schemaMigrations()
+	for version := currentVersion + 1; version <= len(migrations); version++ {
+		err = applyMigration(db, version, migrations[version-1])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(db *sql.DB, version int, migration string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(migration)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+		version,
+		time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
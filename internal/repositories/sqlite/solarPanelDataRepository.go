@@ -0,0 +1,575 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	// registers the pure Go "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+)
+
+// SolarPanelDataRepository stores the datasets in an embedded SQLite database.
+// The Solar map is normalised into the datasets, parameters and events tables
+// so that the stored events can be queried with plain SQL.
+type SolarPanelDataRepository struct {
+	db *sql.DB
+}
+
+// NewSolarPanelDataRepository opens (or creates) the database file at path and
+// applies any pending schema migrations.
+func NewSolarPanelDataRepository(path string) (*SolarPanelDataRepository, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer; sharing one connection serialises the
+	// handlers instead of failing them with "database is locked"
+	db.SetMaxOpenConns(1)
+
+	err = migrate(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SolarPanelDataRepository{db: db}, nil
+}
+
+func (repo *SolarPanelDataRepository) CreateSolarPanelData(solarPanelData *domain.SolarPanelData) (string, error) {
+	insertedId := uuid.New().String()
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	wind, err := marshalWind(solarPanelData.Wind)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+
+	_, err = tx.Exec(
+		`INSERT INTO datasets (id, wind, name, site, created_at, updated_at, version) VALUES (?, ?, ?, ?, ?, ?, 1)`,
+		insertedId,
+		string(wind),
+		solarPanelData.Name,
+		solarPanelData.Site,
+		now.UnixNano(),
+		now.UnixNano(),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	err = insertTags(tx, insertedId, solarPanelData.Tags)
+	if err != nil {
+		return "", err
+	}
+
+	err = insertSolar(tx, insertedId, solarPanelData.Solar)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", err
+	}
+
+	solarPanelData.CreatedAt = now
+	solarPanelData.UpdatedAt = now
+	solarPanelData.Version = 1
+
+	return insertedId, nil
+}
+
+// GetSolarPanelData reads the dataset, its tags and its events in a single
+// transaction, so that an update committing in between cannot mix its version
+// of the dataset with the one it replaced.
+func (repo *SolarPanelDataRepository) GetSolarPanelData(uuid string) (*domain.SolarPanelData, error) {
+	var wind sql.NullString
+	var createdAt, updatedAt int64
+
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{},
+	}
+
+	tx, err := repo.db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return &domain.SolarPanelData{}, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		`SELECT wind, name, site, created_at, updated_at, version FROM datasets WHERE id = ?`,
+		uuid,
+	).Scan(&wind, &solarPanelData.Name, &solarPanelData.Site, &createdAt, &updatedAt, &solarPanelData.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &domain.SolarPanelData{},
+			&apierrors.DataNotFoundErrorWrapper{
+				ReturnedStatusCode: http.StatusNoContent,
+				OriginalError:      errors.New("uuid " + uuid + " not found"),
+			}
+	}
+	if err != nil {
+		return &domain.SolarPanelData{}, err
+	}
+
+	solarPanelData.CreatedAt = time.Unix(0, createdAt).UTC()
+	solarPanelData.UpdatedAt = time.Unix(0, updatedAt).UTC()
+
+	tags, err := loadTags(tx, []string{uuid})
+	if err != nil {
+		return &domain.SolarPanelData{}, err
+	}
+
+	solarPanelData.Tags = tags[uuid]
+
+	rows, err := tx.Query(`
+		SELECT p.parameter_id, e.position, e.timestamp, e.value
+		FROM parameters p
+		LEFT JOIN events e ON e.parameter_row_id = p.id
+		WHERE p.dataset_id = ?
+		ORDER BY p.id, e.position
+	`, uuid)
+	if err != nil {
+		return &domain.SolarPanelData{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var parameterId string
+		var position sql.NullInt64
+		var timestamp, value sql.NullString
+
+		err = rows.Scan(&parameterId, &position, &timestamp, &value)
+		if err != nil {
+			return &domain.SolarPanelData{}, err
+		}
+
+		events, exists := solarPanelData.Solar[parameterId]
+		if !exists {
+			events = []domain.Event{}
+		}
+
+		// a parameter without events only shows up once, with a NULL event
+		if position.Valid {
+			event, err := newEvent(timestamp, value)
+			if err != nil {
+				malformedEvent := domain.MalformedEvent{
+					Source:        domain.EventSourceSolar,
+					ParameterId:   parameterId,
+					Position:      int(position.Int64),
+					OriginalError: err,
+				}
+
+				if timestamp.Valid {
+					malformedEvent.Timestamp = domain.MalformedEventTimestamp(timestamp.String)
+				}
+
+				solarPanelData.MalformedEvents = append(solarPanelData.MalformedEvents, malformedEvent)
+			} else {
+				events = append(events, event)
+			}
+		}
+
+		solarPanelData.Solar[parameterId] = events
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return &domain.SolarPanelData{}, err
+	}
+
+	// reported after the solar ones, which are the ones a dataset is about
+	if wind.Valid {
+		var malformedWindEvents []domain.MalformedEvent
+
+		solarPanelData.Wind, malformedWindEvents = unmarshalWind(wind.String)
+		solarPanelData.MalformedEvents = append(solarPanelData.MalformedEvents, malformedWindEvents...)
+	}
+
+	return solarPanelData, nil
+}
+
+// ListSolarPanelData reads the page and the tags of its datasets in a single
+// transaction, for the same reason as GetSolarPanelData.
+func (repo *SolarPanelDataRepository) ListSolarPanelData(
+	options *domain.SolarPanelDataListOptions,
+) (*domain.SolarPanelDataPage, error) {
+	direction, comparison := "ASC", ">"
+	if options.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	query := `
+		SELECT
+			d.id,
+			d.name,
+			d.site,
+			d.created_at,
+			d.updated_at,
+			d.version,
+			(SELECT COUNT(*) FROM parameters p WHERE p.dataset_id = d.id),
+			(
+				SELECT COUNT(*)
+				FROM events e
+				JOIN parameters p ON p.id = e.parameter_row_id
+				WHERE p.dataset_id = d.id
+			)
+		FROM datasets d
+		WHERE 1 = 1
+	`
+	var arguments []interface{}
+
+	if options.After != nil {
+		query += ` AND (d.created_at, d.id) ` + comparison + ` (?, ?)`
+		arguments = append(arguments, options.After.CreatedAt.UnixNano(), options.After.Id)
+	}
+
+	if options.Name != "" {
+		query += ` AND d.name = ?`
+		arguments = append(arguments, options.Name)
+	}
+
+	if options.Site != "" {
+		query += ` AND d.site = ?`
+		arguments = append(arguments, options.Site)
+	}
+
+	for _, tag := range options.Tags {
+		query += ` AND EXISTS (SELECT 1 FROM dataset_tags t WHERE t.dataset_id = d.id AND t.tag = ?)`
+		arguments = append(arguments, tag)
+	}
+
+	query += ` ORDER BY d.created_at ` + direction + `, d.id ` + direction
+
+	// one extra row tells whether there is a next page
+	if options.Limit > 0 {
+		query += ` LIMIT ` + strconv.Itoa(options.Limit+1)
+	}
+
+	tx, err := repo.db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(query, arguments...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &domain.SolarPanelDataPage{
+		Items: []domain.SolarPanelDataSummary{},
+	}
+
+	for rows.Next() {
+		var summary domain.SolarPanelDataSummary
+		var createdAt, updatedAt int64
+
+		err = rows.Scan(
+			&summary.Id,
+			&summary.Name,
+			&summary.Site,
+			&createdAt,
+			&updatedAt,
+			&summary.Version,
+			&summary.ParameterCount,
+			&summary.EventCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		summary.CreatedAt = time.Unix(0, createdAt).UTC()
+		summary.UpdatedAt = time.Unix(0, updatedAt).UTC()
+
+		page.Items = append(page.Items, summary)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Limit > 0 && len(page.Items) > options.Limit {
+		page.Items = page.Items[:options.Limit]
+		page.HasMore = true
+	}
+
+	ids := make([]string, 0, len(page.Items))
+	for _, summary := range page.Items {
+		ids = append(ids, summary.Id)
+	}
+
+	tags, err := loadTags(tx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range page.Items {
+		page.Items[i].Tags = tags[page.Items[i].Id]
+	}
+
+	return page, nil
+}
+
+func (repo *SolarPanelDataRepository) UpdateSolarPanelData(uuid string, solarPanelData *domain.SolarPanelData) error {
+	return repo.update(uuid, solarPanelData, 0)
+}
+
+func (repo *SolarPanelDataRepository) UpdateSolarPanelDataIfVersion(
+	uuid string,
+	solarPanelData *domain.SolarPanelData,
+	version int,
+) error {
+	return repo.update(uuid, solarPanelData, version)
+}
+
+// update checks the expected version in the UPDATE itself, so that no other
+// write can come in between the check and the change.
+func (repo *SolarPanelDataRepository) update(
+	uuid string,
+	solarPanelData *domain.SolarPanelData,
+	expectedVersion int,
+) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	wind, err := marshalWind(solarPanelData.Wind)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var createdAt int64
+	var version int
+
+	err = tx.QueryRow(
+		`UPDATE datasets SET wind = ?, name = ?, site = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+		RETURNING created_at, version`,
+		string(wind),
+		solarPanelData.Name,
+		solarPanelData.Site,
+		now.UnixNano(),
+		uuid,
+		expectedVersion,
+		expectedVersion,
+	).Scan(&createdAt, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notUpdatedError(tx, uuid, expectedVersion)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM dataset_tags WHERE dataset_id = ?`, uuid)
+	if err != nil {
+		return err
+	}
+
+	err = insertTags(tx, uuid, solarPanelData.Tags)
+	if err != nil {
+		return err
+	}
+
+	// events are removed along with their parameters by ON DELETE CASCADE
+	_, err = tx.Exec(`DELETE FROM parameters WHERE dataset_id = ?`, uuid)
+	if err != nil {
+		return err
+	}
+
+	err = insertSolar(tx, uuid, solarPanelData.Solar)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	solarPanelData.CreatedAt = time.Unix(0, createdAt).UTC()
+	solarPanelData.UpdatedAt = now
+	solarPanelData.Version = version
+
+	return nil
+}
+
+// notUpdatedError tells apart a dataset that does not exist from one that is
+// no longer at the expected version, when an update matched no row.
+func notUpdatedError(tx *sql.Tx, uuid string, expectedVersion int) error {
+	var exists bool
+
+	err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM datasets WHERE id = ?)`, uuid).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return &apierrors.DataNotFoundErrorWrapper{
+			ReturnedStatusCode: http.StatusNotFound,
+			OriginalError:      errors.New("uuid " + uuid + " not found"),
+		}
+	}
+
+	return apierrors.VersionConflictError{
+		ReturnedStatusCode: http.StatusConflict,
+		ExpectedVersion:    expectedVersion,
+	}
+}
+
+func (repo *SolarPanelDataRepository) DeleteSolarPanelData(uuid string) error {
+	_, err := repo.db.Exec(`DELETE FROM datasets WHERE id = ?`, uuid)
+
+	return err
+}
+
+func (repo *SolarPanelDataRepository) Close() error {
+	return repo.db.Close()
+}
+
+// loadTags returns the tags of the given datasets in the order they were
+// submitted. Datasets without tags are missing from the returned map.
+func loadTags(tx *sql.Tx, datasetIds []string) (map[string][]string, error) {
+	tags := map[string][]string{}
+
+	if len(datasetIds) == 0 {
+		return tags, nil
+	}
+
+	placeholders := strings.Repeat("?, ", len(datasetIds)-1) + "?"
+	arguments := make([]interface{}, 0, len(datasetIds))
+	for _, datasetId := range datasetIds {
+		arguments = append(arguments, datasetId)
+	}
+
+	rows, err := tx.Query(
+		`SELECT dataset_id, tag FROM dataset_tags WHERE dataset_id IN (`+placeholders+`) ORDER BY position`,
+		arguments...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datasetId, tag string
+
+		err = rows.Scan(&datasetId, &tag)
+		if err != nil {
+			return nil, err
+		}
+
+		tags[datasetId] = append(tags[datasetId], tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func insertTags(tx *sql.Tx, datasetId string, tags []string) error {
+	for position, tag := range tags {
+		_, err := tx.Exec(
+			`INSERT OR IGNORE INTO dataset_tags (dataset_id, position, tag) VALUES (?, ?, ?)`,
+			datasetId,
+			position,
+			tag,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func insertSolar(tx *sql.Tx, datasetId string, solar map[string][]domain.Event) error {
+	parameterStatement, err := tx.Prepare(`INSERT INTO parameters (dataset_id, parameter_id) VALUES (?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer parameterStatement.Close()
+
+	eventStatement, err := tx.Prepare(
+		`INSERT INTO events (parameter_row_id, position, timestamp, value) VALUES (?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer eventStatement.Close()
+
+	for parameterId, events := range solar {
+		result, err := parameterStatement.Exec(datasetId, parameterId)
+		if err != nil {
+			return err
+		}
+
+		parameterRowId, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		for position, event := range events {
+			_, err = eventStatement.Exec(parameterRowId, position, event.FormatTimestamp(), event.FormatValue())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// newEvent parses an event back from its columns. Rows written before events
+// were validated on ingestion may still hold NULLs or unparsable text.
+func newEvent(timestamp sql.NullString, value sql.NullString) (domain.Event, error) {
+	if !timestamp.Valid || !value.Valid {
+		return domain.Event{}, errors.New("event with no timestamp or value")
+	}
+
+	return domain.ParseEvent([]string{timestamp.String, value.String})
+}
+
+// marshalWind stores the wind events as JSON, in the same [timestamp, value]
+// form they are submitted in.
+func marshalWind(wind map[string][]domain.Event) ([]byte, error) {
+	return json.Marshal(domain.FormatEventSeries(wind))
+}
+
+// unmarshalWind parses the wind events back, along with the ones that could not
+// be. Wind stored before it was typed may be any JSON value, which has no events
+// to leave out and is reported as malformed as a whole.
+func unmarshalWind(storedWind string) (map[string][]domain.Event, []domain.MalformedEvent) {
+	var pairsPerTurbineId map[string][][]string
+
+	err := json.Unmarshal([]byte(storedWind), &pairsPerTurbineId)
+	if err != nil {
+		return nil, []domain.MalformedEvent{
+			{
+				Source:        domain.EventSourceWind,
+				OriginalError: err,
+			},
+		}
+	}
+
+	return domain.ParseEventSeries(domain.EventSourceWind, pairsPerTurbineId)
+}
@@ -0,0 +1,770 @@
+package sqlite
+
+import (
+	"errors"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestSolarPanelDataRepository(t *testing.T, path string) *SolarPanelDataRepository {
+	repo, err := NewSolarPanelDataRepository(path)
+	if err != nil {
+		t.Fatalf("NewSolarPanelDataRepository() error = %v", err)
+	}
+
+	return repo
+}
+
+func TestSolarPanelDataRepository_CreateSolarPanelData(t *testing.T) {
+	type args struct {
+		solarPanelData *domain.SolarPanelData
+	}
+
+	tests := []struct {
+		name                   string
+		args                   args
+		expectedSolarPanelData *domain.SolarPanelData
+		expectError            bool
+	}{
+		{
+			name: "creation ok",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+						},
+					},
+					Wind: nil,
+				},
+			},
+			expectedSolarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+					},
+				},
+				Wind:    nil,
+				Version: 1,
+			},
+			expectError: false,
+		},
+		{
+			name: "creation ok with metadata",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+						},
+					},
+					Wind: nil,
+					Name: "roof",
+					Site: "athens",
+					Tags: []string{"south", "inverter-a"},
+				},
+			},
+			expectedSolarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+					},
+				},
+				Wind:    nil,
+				Name:    "roof",
+				Site:    "athens",
+				Tags:    []string{"south", "inverter-a"},
+				Version: 1,
+			},
+			expectError: false,
+		},
+		{
+			name: "creation ok with fractional and zero events, empty parameter and wind",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 81.9354839},
+							{},
+						},
+						"uuid2": []domain.Event{},
+					},
+					Wind: map[string][]domain.Event{
+						"c078ff68-04fb-11e9-a615-42010afa015a": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 12.5},
+						},
+					},
+				},
+			},
+			expectedSolarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 81.9354839},
+						{},
+					},
+					"uuid2": []domain.Event{},
+				},
+				Wind: map[string][]domain.Event{
+					"c078ff68-04fb-11e9-a615-42010afa015a": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 12.5},
+					},
+				},
+				Version: 1,
+			},
+			expectError: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.db")
+			repo := newTestSolarPanelDataRepository(t, path)
+
+			actualInsertedId, err := repo.CreateSolarPanelData(tt.args.solarPanelData)
+			if (err != nil) != tt.expectError {
+				t.Errorf("CreateSolarPanelData() error = %v, expectError %v", err, tt.expectError)
+				return
+			}
+
+			assert.NoError(t, repo.Close())
+
+			// the created data must survive a restart
+			reopenedRepo := newTestSolarPanelDataRepository(t, path)
+			defer reopenedRepo.Close()
+
+			actual, err := reopenedRepo.GetSolarPanelData(actualInsertedId)
+			if err != nil {
+				t.Errorf("data with uuid %s not found", actualInsertedId)
+				return
+			}
+
+			assert.EqualValues(t, tt.expectedSolarPanelData, withoutTimestamps(actual))
+			assert.True(t, tt.args.solarPanelData.CreatedAt.Equal(actual.CreatedAt))
+		})
+	}
+}
+
+func TestSolarPanelDataRepository_GetSolarPanelData(t *testing.T) {
+	type args struct {
+		uuid string
+	}
+
+	tests := []struct {
+		name          string
+		args          args
+		expected      *domain.SolarPanelData
+		expectError   bool
+		expectedError *apierrors.DataNotFoundErrorWrapper
+	}{
+		{
+			name: "get ok",
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+					},
+					"uuid2": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+					},
+				},
+				Wind:    nil,
+				Version: 1,
+			},
+			expectError: false,
+		},
+		{
+			name: "data not found",
+			args: args{
+				uuid: "uuidNotExisting",
+			},
+			expected: &domain.SolarPanelData{},
+			expectedError: &apierrors.DataNotFoundErrorWrapper{
+				ReturnedStatusCode: http.StatusNoContent,
+				OriginalError:      errors.New("uuid uuidNotExisting not found"),
+			},
+			expectError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestSolarPanelDataRepository(t, filepath.Join(t.TempDir(), "test.db"))
+			defer repo.Close()
+
+			insertedId, err := repo.CreateSolarPanelData(&domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+					},
+					"uuid2": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+					},
+				},
+				Wind: nil,
+			})
+			assert.NoError(t, err)
+
+			uuid := tt.args.uuid
+			if uuid == "" {
+				uuid = insertedId
+			}
+
+			actual, actualError := repo.GetSolarPanelData(uuid)
+			if (actualError != nil) != tt.expectError {
+				t.Errorf("GetSolarPanelData() error = %v, expectError %v", actualError, tt.expectError)
+				return
+			}
+
+			assert.Equal(t, tt.expected, withoutTimestamps(actual))
+
+			if tt.expectError {
+				assert.Equal(t, tt.expectedError, actualError)
+			}
+		})
+	}
+}
+
+func TestSolarPanelDataRepository_GetSolarPanelDataWithMalformedEvents(t *testing.T) {
+	repo := newTestSolarPanelDataRepository(t, filepath.Join(t.TempDir(), "test.db"))
+	defer repo.Close()
+
+	// events stored before they were validated on ingestion may be malformed
+	_, err := repo.db.Exec(`INSERT INTO datasets (id, wind, created_at, updated_at) VALUES ('uuid1', 'null', 0, 0)`)
+	assert.NoError(t, err)
+	_, err = repo.db.Exec(`INSERT INTO parameters (id, dataset_id, parameter_id) VALUES (1, 'uuid1', 'parameter1')`)
+	assert.NoError(t, err)
+	_, err = repo.db.Exec(
+		`INSERT INTO events (parameter_row_id, position, timestamp, value) VALUES
+			(1, 0, '20211231T221500Z', NULL),
+			(1, 1, '20220101T060000Z', '81.9354839')`,
+	)
+	assert.NoError(t, err)
+
+	actual, err := repo.GetSolarPanelData("uuid1")
+
+	// left out and reported, for the service to decide whether to return the rest
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]domain.Event{
+		"parameter1": []domain.Event{
+			{Timestamp: time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC), Value: 81.9354839},
+		},
+	}, actual.Solar)
+	assert.Len(t, actual.MalformedEvents, 1)
+	assert.Equal(t, domain.EventSourceSolar, actual.MalformedEvents[0].Source)
+	assert.Equal(t, "parameter1", actual.MalformedEvents[0].ParameterId)
+	assert.Equal(t, 0, actual.MalformedEvents[0].Position)
+	assert.EqualError(t, actual.MalformedEvents[0].OriginalError, "event with no timestamp or value")
+
+	// as may wind events
+	_, err = repo.db.Exec(
+		`INSERT INTO datasets (id, wind, created_at, updated_at) VALUES
+			('uuid2', '{"turbine": [["20220101T060000Z", "12.5"], ["20220101T061500Z"]]}', 0, 0)`,
+	)
+	assert.NoError(t, err)
+
+	actual, err = repo.GetSolarPanelData("uuid2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]domain.Event{
+		"turbine": []domain.Event{
+			{Timestamp: time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC), Value: 12.5},
+		},
+	}, actual.Wind)
+	assert.Len(t, actual.MalformedEvents, 1)
+	assert.Equal(t, domain.EventSourceWind, actual.MalformedEvents[0].Source)
+	assert.Equal(t, "turbine", actual.MalformedEvents[0].ParameterId)
+	assert.Equal(t, 1, actual.MalformedEvents[0].Position)
+
+	// while wind stored before it was typed has no events to leave out, so it is
+	// reported as a whole, after the malformed solar events
+	_, err = repo.db.Exec(`INSERT INTO datasets (id, wind, created_at, updated_at) VALUES ('uuid3', '{"turbine": "event"}', 0, 0)`)
+	assert.NoError(t, err)
+	_, err = repo.db.Exec(`INSERT INTO parameters (id, dataset_id, parameter_id) VALUES (2, 'uuid3', 'parameter1')`)
+	assert.NoError(t, err)
+	_, err = repo.db.Exec(`INSERT INTO events (parameter_row_id, position, timestamp, value) VALUES (2, 0, '20211231T221500Z', NULL)`)
+	assert.NoError(t, err)
+
+	actual, err = repo.GetSolarPanelData("uuid3")
+
+	assert.NoError(t, err)
+	assert.Empty(t, actual.Wind)
+	assert.Len(t, actual.MalformedEvents, 2)
+	assert.Equal(t, domain.EventSourceSolar, actual.MalformedEvents[0].Source)
+	assert.Equal(t, "parameter1", actual.MalformedEvents[0].ParameterId)
+	assert.Equal(t, domain.EventSourceWind, actual.MalformedEvents[1].Source)
+	assert.Equal(t, "", actual.MalformedEvents[1].ParameterId)
+	assert.Error(t, actual.MalformedEvents[1].OriginalError)
+}
+
+func TestSolarPanelDataRepository_UpdateSolarPanelData(t *testing.T) {
+	type args struct {
+		uuid           string
+		solarPanelData *domain.SolarPanelData
+	}
+
+	tests := []struct {
+		name                   string
+		args                   args
+		expectedSolarPanelData *domain.SolarPanelData
+		expectError            bool
+		expectedErrorMessage   string
+	}{
+		{
+			name: "update ok",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+						},
+					},
+					Wind: nil,
+				},
+			},
+			expectedSolarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+					},
+				},
+				Wind:    nil,
+				Version: 2,
+			},
+			expectError: false,
+		},
+		{
+			name: "error data not found",
+			args: args{
+				uuid: "uuidNotExisting",
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+						},
+					},
+					Wind: nil,
+				},
+			},
+			expectError: true,
+			// data not found error does not have an error message
+			expectedErrorMessage: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestSolarPanelDataRepository(t, filepath.Join(t.TempDir(), "test.db"))
+			defer repo.Close()
+
+			insertedId, err := repo.CreateSolarPanelData(&domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+					},
+					"uuid2": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+					},
+				},
+				Wind: nil,
+			})
+			assert.NoError(t, err)
+
+			uuid := tt.args.uuid
+			if uuid == "" {
+				uuid = insertedId
+			}
+
+			err = repo.UpdateSolarPanelData(uuid, tt.args.solarPanelData)
+			if (err != nil) != tt.expectError {
+				t.Errorf("UpdateSolarPanelData() error = %v, expectError %v", err, tt.expectError)
+				return
+			}
+
+			if tt.expectError {
+				assert.Equal(t, tt.expectedErrorMessage, err.Error())
+				return
+			}
+
+			actual, err := repo.GetSolarPanelData(uuid)
+			if err != nil {
+				t.Errorf("data with uuid %s not found", uuid)
+				return
+			}
+
+			assert.EqualValues(t, tt.expectedSolarPanelData, withoutTimestamps(actual))
+			assert.False(t, actual.UpdatedAt.Before(actual.CreatedAt))
+		})
+	}
+}
+
+func TestSolarPanelDataRepository_UpdateSolarPanelDataIfVersion(t *testing.T) {
+	type args struct {
+		uuid            string
+		solarPanelData  *domain.SolarPanelData
+		expectedVersion int
+	}
+
+	tests := []struct {
+		name                   string
+		args                   args
+		expectedSolarPanelData *domain.SolarPanelData
+		expectError            bool
+		expectedErrorMessage   string
+	}{
+		{
+			name: "update ok at the expected version",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+						},
+					},
+					Wind: nil,
+				},
+				expectedVersion: 1,
+			},
+			expectedSolarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+					},
+				},
+				Wind:    nil,
+				Version: 2,
+			},
+			expectError: false,
+		},
+		{
+			name: "error changed since the expected version",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+						},
+					},
+					Wind: nil,
+				},
+				expectedVersion: 5,
+			},
+			expectError:          true,
+			expectedErrorMessage: "solar panel data was changed by another request, try again",
+		},
+		{
+			name: "error data not found",
+			args: args{
+				uuid: "uuidNotExisting",
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+						},
+					},
+					Wind: nil,
+				},
+				expectedVersion: 1,
+			},
+			expectError: true,
+			// data not found error does not have an error message
+			expectedErrorMessage: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestSolarPanelDataRepository(t, filepath.Join(t.TempDir(), "test.db"))
+			defer repo.Close()
+
+			insertedId, err := repo.CreateSolarPanelData(&domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+					},
+				},
+				Wind: nil,
+			})
+			assert.NoError(t, err)
+
+			uuid := tt.args.uuid
+			if uuid == "" {
+				uuid = insertedId
+			}
+
+			err = repo.UpdateSolarPanelDataIfVersion(uuid, tt.args.solarPanelData, tt.args.expectedVersion)
+			if (err != nil) != tt.expectError {
+				t.Errorf("UpdateSolarPanelDataIfVersion() error = %v, expectError %v", err, tt.expectError)
+				return
+			}
+
+			if tt.expectError {
+				assert.Equal(t, tt.expectedErrorMessage, err.Error())
+				return
+			}
+
+			actual, err := repo.GetSolarPanelData(uuid)
+			if err != nil {
+				t.Errorf("data with uuid %s not found", uuid)
+				return
+			}
+
+			assert.EqualValues(t, tt.expectedSolarPanelData, withoutTimestamps(actual))
+		})
+	}
+}
+
+func TestSolarPanelDataRepository_DeleteSolarPanelData(t *testing.T) {
+	repo := newTestSolarPanelDataRepository(t, filepath.Join(t.TempDir(), "test.db"))
+	defer repo.Close()
+
+	insertedId, err := repo.CreateSolarPanelData(&domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+			},
+		},
+		Wind: nil,
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.DeleteSolarPanelData(insertedId))
+	assert.NoError(t, repo.DeleteSolarPanelData("uuidNotExisting"))
+
+	_, err = repo.GetSolarPanelData(insertedId)
+	assert.Error(t, err)
+
+	// parameters and events are deleted along with the dataset
+	var remainingEvents int
+	err = repo.db.QueryRow(`SELECT COUNT(*) FROM events`).Scan(&remainingEvents)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, remainingEvents)
+}
+
+func TestSolarPanelDataRepository_ListSolarPanelData(t *testing.T) {
+	repo := newTestSolarPanelDataRepository(t, filepath.Join(t.TempDir(), "test.db"))
+	defer repo.Close()
+
+	firstCreatedAt := time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC)
+	secondCreatedAt := time.Date(2022, 1, 2, 6, 0, 0, 0, time.UTC)
+
+	datasets := []struct {
+		id        string
+		createdAt time.Time
+		name      string
+		site      string
+		tags      []string
+		solar     map[string][]domain.Event
+	}{
+		{
+			id:        "uuid3",
+			createdAt: secondCreatedAt,
+			name:      "roof",
+			site:      "athens",
+			tags:      []string{"south", "inverter-a"},
+			solar: map[string][]domain.Event{
+				"uuid1": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+					{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+				},
+				"uuid2": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+				},
+			},
+		},
+		{id: "uuid2", createdAt: firstCreatedAt, solar: map[string][]domain.Event{}},
+		{id: "uuid1", createdAt: firstCreatedAt, solar: map[string][]domain.Event{}},
+	}
+
+	// insert directly so that the ids and timestamps, and thus the order, are known
+	for _, dataset := range datasets {
+		tx, err := repo.db.Begin()
+		assert.NoError(t, err)
+
+		_, err = tx.Exec(
+			`INSERT INTO datasets (id, wind, name, site, created_at, updated_at) VALUES (?, 'null', ?, ?, ?, ?)`,
+			dataset.id,
+			dataset.name,
+			dataset.site,
+			dataset.createdAt.UnixNano(),
+			dataset.createdAt.UnixNano(),
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, insertTags(tx, dataset.id, dataset.tags))
+		assert.NoError(t, insertSolar(tx, dataset.id, dataset.solar))
+		assert.NoError(t, tx.Commit())
+	}
+
+	first := domain.SolarPanelDataSummary{Id: "uuid1", CreatedAt: firstCreatedAt, UpdatedAt: firstCreatedAt, Version: 1}
+	second := domain.SolarPanelDataSummary{Id: "uuid2", CreatedAt: firstCreatedAt, UpdatedAt: firstCreatedAt, Version: 1}
+	third := domain.SolarPanelDataSummary{
+		Id:             "uuid3",
+		Name:           "roof",
+		Site:           "athens",
+		Tags:           []string{"south", "inverter-a"},
+		CreatedAt:      secondCreatedAt,
+		UpdatedAt:      secondCreatedAt,
+		Version:        1,
+		ParameterCount: 2,
+		EventCount:     3,
+	}
+
+	tests := []struct {
+		name     string
+		options  *domain.SolarPanelDataListOptions
+		expected *domain.SolarPanelDataPage
+	}{
+		{
+			name:    "all ascending",
+			options: &domain.SolarPanelDataListOptions{Limit: 10},
+			expected: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{first, second, third},
+			},
+		},
+		{
+			name:    "first page",
+			options: &domain.SolarPanelDataListOptions{Limit: 2},
+			expected: &domain.SolarPanelDataPage{
+				Items:   []domain.SolarPanelDataSummary{first, second},
+				HasMore: true,
+			},
+		},
+		{
+			name: "page after cursor with the same creation time",
+			options: &domain.SolarPanelDataListOptions{
+				After: &domain.SolarPanelDataListCursor{CreatedAt: firstCreatedAt, Id: "uuid1"},
+				Limit: 2,
+			},
+			expected: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{second, third},
+			},
+		},
+		{
+			name: "descending after cursor",
+			options: &domain.SolarPanelDataListOptions{
+				After:      &domain.SolarPanelDataListCursor{CreatedAt: firstCreatedAt, Id: "uuid2"},
+				Limit:      2,
+				Descending: true,
+			},
+			expected: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{first},
+			},
+		},
+		{
+			name:    "filtered by name and site",
+			options: &domain.SolarPanelDataListOptions{Limit: 10, Name: "roof", Site: "athens"},
+			expected: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{third},
+			},
+		},
+		{
+			name:    "filtered by all tags",
+			options: &domain.SolarPanelDataListOptions{Limit: 10, Tags: []string{"inverter-a", "south"}},
+			expected: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{third},
+			},
+		},
+		{
+			name:    "filtered by missing tag",
+			options: &domain.SolarPanelDataListOptions{Limit: 10, Tags: []string{"south", "north"}},
+			expected: &domain.SolarPanelDataPage{
+				Items: []domain.SolarPanelDataSummary{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := repo.ListSolarPanelData(tt.options)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+// TestSolarPanelDataRepository_ConsistentReads updates a dataset back and forth
+// between two versions while reading it, each read having to see the name, tags
+// and events of the same version.
+func TestSolarPanelDataRepository_ConsistentReads(t *testing.T) {
+	repo := newTestSolarPanelDataRepository(t, filepath.Join(t.TempDir(), "test.db"))
+	defer repo.Close()
+
+	versions := []*domain.SolarPanelData{
+		{
+			Solar: map[string][]domain.Event{
+				"uuid1": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+				},
+			},
+			Name: "a",
+			Tags: []string{"a"},
+		},
+		{
+			Solar: map[string][]domain.Event{
+				"uuid2": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 2},
+				},
+			},
+			Name: "b",
+			Tags: []string{"b"},
+		},
+	}
+
+	id, err := repo.CreateSolarPanelData(versions[0])
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 1; i <= 50; i++ {
+			assert.NoError(t, repo.UpdateSolarPanelData(id, versions[i%2]))
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		actual, err := repo.GetSolarPanelData(id)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{actual.Name}, actual.Tags)
+		if actual.Name == "a" {
+			assert.Contains(t, actual.Solar, "uuid1")
+		} else {
+			assert.Contains(t, actual.Solar, "uuid2")
+		}
+		assert.Len(t, actual.Solar, 1)
+	}
+
+	wg.Wait()
+}
+
+func TestMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	// opening twice must not re-apply the migrations
+	newTestSolarPanelDataRepository(t, path).Close()
+	repo := newTestSolarPanelDataRepository(t, path)
+	defer repo.Close()
+
+	var appliedMigrations int
+	err := repo.db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&appliedMigrations)
+	assert.NoError(t, err)
+	assert.Equal(t, len(schemaMigrations()), appliedMigrations)
+}
+
+// withoutTimestamps returns a copy of the dataset with the server managed
+// timestamps cleared, so that it can be compared against fixed expectations.
+func withoutTimestamps(solarPanelData *domain.SolarPanelData) *domain.SolarPanelData {
+	copied := *solarPanelData
+	copied.CreatedAt = time.Time{}
+	copied.UpdatedAt = time.Time{}
+
+	return &copied
+}
@@ -78,6 +78,21 @@ func (mr *MockSolarPanelDataRepositoryInterfaceMockRecorder) GetSolarPanelData(u
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolarPanelData", reflect.TypeOf((*MockSolarPanelDataRepositoryInterface)(nil).GetSolarPanelData), uuid)
 }
 
+// ListSolarPanelData mocks base method.
+func (m *MockSolarPanelDataRepositoryInterface) ListSolarPanelData(arg0 *domain.SolarPanelDataListOptions) (*domain.SolarPanelDataPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSolarPanelData", arg0)
+	ret0, _ := ret[0].(*domain.SolarPanelDataPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSolarPanelData indicates an expected call of ListSolarPanelData.
+func (mr *MockSolarPanelDataRepositoryInterfaceMockRecorder) ListSolarPanelData(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSolarPanelData", reflect.TypeOf((*MockSolarPanelDataRepositoryInterface)(nil).ListSolarPanelData), arg0)
+}
+
 // UpdateSolarPanelData mocks base method.
 func (m *MockSolarPanelDataRepositoryInterface) UpdateSolarPanelData(arg0 string, arg1 *domain.SolarPanelData) error {
 	m.ctrl.T.Helper()
@@ -91,3 +106,17 @@ func (mr *MockSolarPanelDataRepositoryInterfaceMockRecorder) UpdateSolarPanelDat
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSolarPanelData", reflect.TypeOf((*MockSolarPanelDataRepositoryInterface)(nil).UpdateSolarPanelData), arg0, arg1)
 }
+
+// UpdateSolarPanelDataIfVersion mocks base method.
+func (m *MockSolarPanelDataRepositoryInterface) UpdateSolarPanelDataIfVersion(arg0 string, arg1 *domain.SolarPanelData, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSolarPanelDataIfVersion", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSolarPanelDataIfVersion indicates an expected call of UpdateSolarPanelDataIfVersion.
+func (mr *MockSolarPanelDataRepositoryInterfaceMockRecorder) UpdateSolarPanelDataIfVersion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSolarPanelDataIfVersion", reflect.TypeOf((*MockSolarPanelDataRepositoryInterface)(nil).UpdateSolarPanelDataIfVersion), arg0, arg1, arg2)
+}
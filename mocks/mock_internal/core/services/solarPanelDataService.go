@@ -64,18 +64,63 @@ func (mr *MockSolarPanelDataServiceInterfaceMockRecorder) DeleteSolarPanelData(a
 }
 
 // GetSolarPanelData mocks base method.
-func (m *MockSolarPanelDataServiceInterface) GetSolarPanelData(arg0 string) (*domain.SolarPanelData, error) {
+func (m *MockSolarPanelDataServiceInterface) GetSolarPanelData(arg0 string, arg1 *domain.EventSelection) (*domain.SolarPanelData, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSolarPanelData", arg0)
+	ret := m.ctrl.Call(m, "GetSolarPanelData", arg0, arg1)
 	ret0, _ := ret[0].(*domain.SolarPanelData)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetSolarPanelData indicates an expected call of GetSolarPanelData.
-func (mr *MockSolarPanelDataServiceInterfaceMockRecorder) GetSolarPanelData(arg0 interface{}) *gomock.Call {
+func (mr *MockSolarPanelDataServiceInterfaceMockRecorder) GetSolarPanelData(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolarPanelData", reflect.TypeOf((*MockSolarPanelDataServiceInterface)(nil).GetSolarPanelData), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolarPanelData", reflect.TypeOf((*MockSolarPanelDataServiceInterface)(nil).GetSolarPanelData), arg0, arg1)
+}
+
+// GetSolarPanelDataSkippingMalformedEvents mocks base method.
+func (m *MockSolarPanelDataServiceInterface) GetSolarPanelDataSkippingMalformedEvents(arg0 string, arg1 *domain.EventSelection) (*domain.SolarPanelData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSolarPanelDataSkippingMalformedEvents", arg0, arg1)
+	ret0, _ := ret[0].(*domain.SolarPanelData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSolarPanelDataSkippingMalformedEvents indicates an expected call of GetSolarPanelDataSkippingMalformedEvents.
+func (mr *MockSolarPanelDataServiceInterfaceMockRecorder) GetSolarPanelDataSkippingMalformedEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolarPanelDataSkippingMalformedEvents", reflect.TypeOf((*MockSolarPanelDataServiceInterface)(nil).GetSolarPanelDataSkippingMalformedEvents), arg0, arg1)
+}
+
+// ListSolarPanelData mocks base method.
+func (m *MockSolarPanelDataServiceInterface) ListSolarPanelData(arg0 *domain.SolarPanelDataListOptions) (*domain.SolarPanelDataPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSolarPanelData", arg0)
+	ret0, _ := ret[0].(*domain.SolarPanelDataPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSolarPanelData indicates an expected call of ListSolarPanelData.
+func (mr *MockSolarPanelDataServiceInterfaceMockRecorder) ListSolarPanelData(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSolarPanelData", reflect.TypeOf((*MockSolarPanelDataServiceInterface)(nil).ListSolarPanelData), arg0)
+}
+
+// MergeSolarPanelData mocks base method.
+func (m *MockSolarPanelDataServiceInterface) MergeSolarPanelData(arg0 string, arg1 *domain.SolarPanelData, arg2 domain.MergeConflictPolicy) (*domain.MergeResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeSolarPanelData", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.MergeResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeSolarPanelData indicates an expected call of MergeSolarPanelData.
+func (mr *MockSolarPanelDataServiceInterfaceMockRecorder) MergeSolarPanelData(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeSolarPanelData", reflect.TypeOf((*MockSolarPanelDataServiceInterface)(nil).MergeSolarPanelData), arg0, arg1, arg2)
 }
 
 // UpdateSolarPanelData mocks base method.
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/helper/solarPanelDataAnomalyDetector.go
+
+// Package mock_helper is a generated GoMock package.
+package mock_helper
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	helper "github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+)
+
+// MockSolarPanelDataAnomalyDetectorInterface is a mock of SolarPanelDataAnomalyDetectorInterface interface.
+type MockSolarPanelDataAnomalyDetectorInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockSolarPanelDataAnomalyDetectorInterfaceMockRecorder
+}
+
+// MockSolarPanelDataAnomalyDetectorInterfaceMockRecorder is the mock recorder for MockSolarPanelDataAnomalyDetectorInterface.
+type MockSolarPanelDataAnomalyDetectorInterfaceMockRecorder struct {
+	mock *MockSolarPanelDataAnomalyDetectorInterface
+}
+
+// NewMockSolarPanelDataAnomalyDetectorInterface creates a new mock instance.
+func NewMockSolarPanelDataAnomalyDetectorInterface(ctrl *gomock.Controller) *MockSolarPanelDataAnomalyDetectorInterface {
+	mock := &MockSolarPanelDataAnomalyDetectorInterface{ctrl: ctrl}
+	mock.recorder = &MockSolarPanelDataAnomalyDetectorInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSolarPanelDataAnomalyDetectorInterface) EXPECT() *MockSolarPanelDataAnomalyDetectorInterfaceMockRecorder {
+	return m.recorder
+}
+
+// DetectAnomalies mocks base method.
+func (m *MockSolarPanelDataAnomalyDetectorInterface) DetectAnomalies(arg0 *domain.SolarPanelData, arg1 *helper.AnomalyOptions) ([]helper.ParameterAnomalies, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetectAnomalies", arg0, arg1)
+	ret0, _ := ret[0].([]helper.ParameterAnomalies)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DetectAnomalies indicates an expected call of DetectAnomalies.
+func (mr *MockSolarPanelDataAnomalyDetectorInterfaceMockRecorder) DetectAnomalies(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetectAnomalies", reflect.TypeOf((*MockSolarPanelDataAnomalyDetectorInterface)(nil).DetectAnomalies), arg0, arg1)
+}
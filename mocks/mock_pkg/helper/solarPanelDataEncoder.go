@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/helper/solarPanelDataEncoder.go
+
+// Package mock_helper is a generated GoMock package.
+package mock_helper
+
+import (
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	helper "github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+)
+
+// MockSolarPanelDataEncoderInterface is a mock of SolarPanelDataEncoderInterface interface.
+type MockSolarPanelDataEncoderInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockSolarPanelDataEncoderInterfaceMockRecorder
+}
+
+// MockSolarPanelDataEncoderInterfaceMockRecorder is the mock recorder for MockSolarPanelDataEncoderInterface.
+type MockSolarPanelDataEncoderInterfaceMockRecorder struct {
+	mock *MockSolarPanelDataEncoderInterface
+}
+
+// NewMockSolarPanelDataEncoderInterface creates a new mock instance.
+func NewMockSolarPanelDataEncoderInterface(ctrl *gomock.Controller) *MockSolarPanelDataEncoderInterface {
+	mock := &MockSolarPanelDataEncoderInterface{ctrl: ctrl}
+	mock.recorder = &MockSolarPanelDataEncoderInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSolarPanelDataEncoderInterface) EXPECT() *MockSolarPanelDataEncoderInterfaceMockRecorder {
+	return m.recorder
+}
+
+// EncodeError mocks base method.
+func (m *MockSolarPanelDataEncoderInterface) EncodeError(arg0 io.Writer, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EncodeError", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EncodeError indicates an expected call of EncodeError.
+func (mr *MockSolarPanelDataEncoderInterfaceMockRecorder) EncodeError(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EncodeError", reflect.TypeOf((*MockSolarPanelDataEncoderInterface)(nil).EncodeError), arg0, arg1)
+}
+
+// EncodeSolarPanelData mocks base method.
+func (m *MockSolarPanelDataEncoderInterface) EncodeSolarPanelData(arg0 io.Writer, arg1 *domain.SolarPanelData, arg2 *helper.ExportOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EncodeSolarPanelData", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EncodeSolarPanelData indicates an expected call of EncodeSolarPanelData.
+func (mr *MockSolarPanelDataEncoderInterfaceMockRecorder) EncodeSolarPanelData(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EncodeSolarPanelData", reflect.TypeOf((*MockSolarPanelDataEncoderInterface)(nil).EncodeSolarPanelData), arg0, arg1, arg2)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/helper/solarPanelDataEnergyCalculator.go
+
+// Package mock_helper is a generated GoMock package.
+package mock_helper
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	helper "github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+)
+
+// MockSolarPanelDataEnergyCalculatorInterface is a mock of SolarPanelDataEnergyCalculatorInterface interface.
+type MockSolarPanelDataEnergyCalculatorInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockSolarPanelDataEnergyCalculatorInterfaceMockRecorder
+}
+
+// MockSolarPanelDataEnergyCalculatorInterfaceMockRecorder is the mock recorder for MockSolarPanelDataEnergyCalculatorInterface.
+type MockSolarPanelDataEnergyCalculatorInterfaceMockRecorder struct {
+	mock *MockSolarPanelDataEnergyCalculatorInterface
+}
+
+// NewMockSolarPanelDataEnergyCalculatorInterface creates a new mock instance.
+func NewMockSolarPanelDataEnergyCalculatorInterface(ctrl *gomock.Controller) *MockSolarPanelDataEnergyCalculatorInterface {
+	mock := &MockSolarPanelDataEnergyCalculatorInterface{ctrl: ctrl}
+	mock.recorder = &MockSolarPanelDataEnergyCalculatorInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSolarPanelDataEnergyCalculatorInterface) EXPECT() *MockSolarPanelDataEnergyCalculatorInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CalculateEnergy mocks base method.
+func (m *MockSolarPanelDataEnergyCalculatorInterface) CalculateEnergy(arg0 *domain.SolarPanelData, arg1 *helper.EnergyOptions) (*helper.SolarPanelDataEnergy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalculateEnergy", arg0, arg1)
+	ret0, _ := ret[0].(*helper.SolarPanelDataEnergy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalculateEnergy indicates an expected call of CalculateEnergy.
+func (mr *MockSolarPanelDataEnergyCalculatorInterfaceMockRecorder) CalculateEnergy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalculateEnergy", reflect.TypeOf((*MockSolarPanelDataEnergyCalculatorInterface)(nil).CalculateEnergy), arg0, arg1)
+}
@@ -9,6 +9,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	helper "github.com/loukaspe/solar-panel-data-crud/pkg/helper"
 )
 
 // MockSolarPanelDataEventExtractorInterface is a mock of SolarPanelDataEventExtractorInterface interface.
@@ -35,16 +36,16 @@ func (m *MockSolarPanelDataEventExtractorInterface) EXPECT() *MockSolarPanelData
 }
 
 // ExtractEventsPerParameterIdToCsvForm mocks base method.
-func (m *MockSolarPanelDataEventExtractorInterface) ExtractEventsPerParameterIdToCsvForm(arg0 *domain.SolarPanelData) ([][]string, error) {
+func (m *MockSolarPanelDataEventExtractorInterface) ExtractEventsPerParameterIdToCsvForm(arg0 *domain.SolarPanelData, arg1 *helper.ExportOptions) ([][]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExtractEventsPerParameterIdToCsvForm", arg0)
+	ret := m.ctrl.Call(m, "ExtractEventsPerParameterIdToCsvForm", arg0, arg1)
 	ret0, _ := ret[0].([][]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ExtractEventsPerParameterIdToCsvForm indicates an expected call of ExtractEventsPerParameterIdToCsvForm.
-func (mr *MockSolarPanelDataEventExtractorInterfaceMockRecorder) ExtractEventsPerParameterIdToCsvForm(arg0 interface{}) *gomock.Call {
+func (mr *MockSolarPanelDataEventExtractorInterfaceMockRecorder) ExtractEventsPerParameterIdToCsvForm(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractEventsPerParameterIdToCsvForm", reflect.TypeOf((*MockSolarPanelDataEventExtractorInterface)(nil).ExtractEventsPerParameterIdToCsvForm), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractEventsPerParameterIdToCsvForm", reflect.TypeOf((*MockSolarPanelDataEventExtractorInterface)(nil).ExtractEventsPerParameterIdToCsvForm), arg0, arg1)
 }
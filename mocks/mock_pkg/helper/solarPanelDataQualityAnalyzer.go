@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/helper/solarPanelDataQualityAnalyzer.go
+
+// Package mock_helper is a generated GoMock package.
+package mock_helper
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	helper "github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+)
+
+// MockSolarPanelDataQualityAnalyzerInterface is a mock of SolarPanelDataQualityAnalyzerInterface interface.
+type MockSolarPanelDataQualityAnalyzerInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockSolarPanelDataQualityAnalyzerInterfaceMockRecorder
+}
+
+// MockSolarPanelDataQualityAnalyzerInterfaceMockRecorder is the mock recorder for MockSolarPanelDataQualityAnalyzerInterface.
+type MockSolarPanelDataQualityAnalyzerInterfaceMockRecorder struct {
+	mock *MockSolarPanelDataQualityAnalyzerInterface
+}
+
+// NewMockSolarPanelDataQualityAnalyzerInterface creates a new mock instance.
+func NewMockSolarPanelDataQualityAnalyzerInterface(ctrl *gomock.Controller) *MockSolarPanelDataQualityAnalyzerInterface {
+	mock := &MockSolarPanelDataQualityAnalyzerInterface{ctrl: ctrl}
+	mock.recorder = &MockSolarPanelDataQualityAnalyzerInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSolarPanelDataQualityAnalyzerInterface) EXPECT() *MockSolarPanelDataQualityAnalyzerInterfaceMockRecorder {
+	return m.recorder
+}
+
+// AnalyzeQuality mocks base method.
+func (m *MockSolarPanelDataQualityAnalyzerInterface) AnalyzeQuality(arg0 *domain.SolarPanelData, arg1 *helper.QualityOptions) ([]helper.ParameterQuality, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnalyzeQuality", arg0, arg1)
+	ret0, _ := ret[0].([]helper.ParameterQuality)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnalyzeQuality indicates an expected call of AnalyzeQuality.
+func (mr *MockSolarPanelDataQualityAnalyzerInterfaceMockRecorder) AnalyzeQuality(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnalyzeQuality", reflect.TypeOf((*MockSolarPanelDataQualityAnalyzerInterface)(nil).AnalyzeQuality), arg0, arg1)
+}
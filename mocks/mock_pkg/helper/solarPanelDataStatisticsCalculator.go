@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/helper/solarPanelDataStatisticsCalculator.go
+
+// Package mock_helper is a generated GoMock package.
+package mock_helper
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	helper "github.com/loukaspe/solar-panel-data-crud/pkg/helper"
+)
+
+// MockSolarPanelDataStatisticsCalculatorInterface is a mock of SolarPanelDataStatisticsCalculatorInterface interface.
+type MockSolarPanelDataStatisticsCalculatorInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockSolarPanelDataStatisticsCalculatorInterfaceMockRecorder
+}
+
+// MockSolarPanelDataStatisticsCalculatorInterfaceMockRecorder is the mock recorder for MockSolarPanelDataStatisticsCalculatorInterface.
+type MockSolarPanelDataStatisticsCalculatorInterfaceMockRecorder struct {
+	mock *MockSolarPanelDataStatisticsCalculatorInterface
+}
+
+// NewMockSolarPanelDataStatisticsCalculatorInterface creates a new mock instance.
+func NewMockSolarPanelDataStatisticsCalculatorInterface(ctrl *gomock.Controller) *MockSolarPanelDataStatisticsCalculatorInterface {
+	mock := &MockSolarPanelDataStatisticsCalculatorInterface{ctrl: ctrl}
+	mock.recorder = &MockSolarPanelDataStatisticsCalculatorInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSolarPanelDataStatisticsCalculatorInterface) EXPECT() *MockSolarPanelDataStatisticsCalculatorInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CalculateStatistics mocks base method.
+func (m *MockSolarPanelDataStatisticsCalculatorInterface) CalculateStatistics(arg0 *domain.SolarPanelData, arg1 *helper.StatisticsOptions) ([]helper.ParameterStatistics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalculateStatistics", arg0, arg1)
+	ret0, _ := ret[0].([]helper.ParameterStatistics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalculateStatistics indicates an expected call of CalculateStatistics.
+func (mr *MockSolarPanelDataStatisticsCalculatorInterfaceMockRecorder) CalculateStatistics(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalculateStatistics", reflect.TypeOf((*MockSolarPanelDataStatisticsCalculatorInterface)(nil).CalculateStatistics), arg0, arg1)
+}
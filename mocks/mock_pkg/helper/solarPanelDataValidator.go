@@ -0,0 +1,48 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/helper/solarPanelDataValidator.go
+
+// Package mock_helper is a generated GoMock package.
+package mock_helper
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSolarPanelDataValidatorInterface is a mock of SolarPanelDataValidatorInterface interface.
+type MockSolarPanelDataValidatorInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockSolarPanelDataValidatorInterfaceMockRecorder
+}
+
+// MockSolarPanelDataValidatorInterfaceMockRecorder is the mock recorder for MockSolarPanelDataValidatorInterface.
+type MockSolarPanelDataValidatorInterfaceMockRecorder struct {
+	mock *MockSolarPanelDataValidatorInterface
+}
+
+// NewMockSolarPanelDataValidatorInterface creates a new mock instance.
+func NewMockSolarPanelDataValidatorInterface(ctrl *gomock.Controller) *MockSolarPanelDataValidatorInterface {
+	mock := &MockSolarPanelDataValidatorInterface{ctrl: ctrl}
+	mock.recorder = &MockSolarPanelDataValidatorInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSolarPanelDataValidatorInterface) EXPECT() *MockSolarPanelDataValidatorInterfaceMockRecorder {
+	return m.recorder
+}
+
+// ValidateSolarPanelData mocks base method.
+func (m *MockSolarPanelDataValidatorInterface) ValidateSolarPanelData(arg0, arg1 map[string][][]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateSolarPanelData", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateSolarPanelData indicates an expected call of ValidateSolarPanelData.
+func (mr *MockSolarPanelDataValidatorInterfaceMockRecorder) ValidateSolarPanelData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateSolarPanelData", reflect.TypeOf((*MockSolarPanelDataValidatorInterface)(nil).ValidateSolarPanelData), arg0, arg1)
+}
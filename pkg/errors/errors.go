@@ -1,5 +1,10 @@
 package apierrors
 
+import (
+	"strconv"
+	"strings"
+)
+
 type DataNotFoundErrorWrapper struct {
 	ReturnedStatusCode int
 	OriginalError      error
@@ -16,14 +21,21 @@ func (err DataNotFoundErrorWrapper) Unwrap() error {
 }
 
 type MalformedEventDataError struct {
-	ReturnedStatusCode   int
-	OriginalError        error
+	ReturnedStatusCode int
+	OriginalError      error
+	MalformedSource    string
+	// MalformedParameterId is empty when the stored events of the source are
+	// malformed as a whole.
 	MalformedParameterId string
 }
 
 // Error the original error message remains as it is for logging reasons etc.
 // and the wrapper error message is empty because we don't want the client to see anything
 func (err MalformedEventDataError) Error() string {
+	if err.MalformedParameterId == "" {
+		return "malformed solar panel data, check the " + err.MalformedSource + " events"
+	}
+
 	return "malformed solar panel data, check parameter " + err.MalformedParameterId
 }
 
@@ -40,3 +52,84 @@ type EmptySolarDataError struct {
 func (err EmptySolarDataError) Error() string {
 	return "solar data is empty on request"
 }
+
+type InvalidEventError struct {
+	ReturnedStatusCode int
+	OriginalError      error
+	ParameterId        string
+	EventIndex         int
+}
+
+// Error unlike the other errors the reason is shown to the client, so that the
+// rejected event can be fixed and submitted again
+func (err InvalidEventError) Error() string {
+	return "invalid event " + strconv.Itoa(err.EventIndex) + " of parameter " + err.ParameterId + ": " +
+		err.OriginalError.Error()
+}
+
+func (err InvalidEventError) Unwrap() error {
+	return err.OriginalError
+}
+
+// Violation is a single reason a submitted document was rejected, located by the
+// JSON path of the offending field.
+type Violation struct {
+	Path    string
+	Message string
+}
+
+type ValidationError struct {
+	ReturnedStatusCode int
+	Violations         []Violation
+}
+
+// Error the violations are returned to the client next to this message, so that
+// every problem can be fixed at once
+func (err ValidationError) Error() string {
+	return "invalid solar panel data, check violations"
+}
+
+type NotAcceptableError struct {
+	ReturnedStatusCode  int
+	SupportedMediaTypes []string
+}
+
+// Error the supported media types are returned to the client, so that the
+// request can be repeated with one of them
+func (err NotAcceptableError) Error() string {
+	return "none of the requested media types is supported, use one of " +
+		strings.Join(err.SupportedMediaTypes, ", ")
+}
+
+type UnknownParametersError struct {
+	ReturnedStatusCode int
+	ParameterIds       []string
+}
+
+// Error the unknown parameter ids are shown to the client, so that the request
+// can be fixed
+func (err UnknownParametersError) Error() string {
+	return "unknown parameters " + strings.Join(err.ParameterIds, ", ")
+}
+
+type MergeConflictError struct {
+	ReturnedStatusCode int
+	Violations         []Violation
+}
+
+// Error the conflicting events are returned to the client as violations next to
+// this message, so that they can be fixed or merged with another policy
+func (err MergeConflictError) Error() string {
+	return "solar panel data conflicts with the stored events, check violations"
+}
+
+type VersionConflictError struct {
+	ReturnedStatusCode int
+	ExpectedVersion    int
+}
+
+// Error the dataset was changed by another request since it was read, so the
+// request can simply be repeated
+func (err VersionConflictError) Error() string {
+	return "solar panel data was changed by another request, try again"
+}
@@ -0,0 +1,61 @@
+package helper
+
+import (
+	"errors"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const defaultAnomalyThreshold = 0.3
+
+// AnomalyOptions controls which series of a dataset are compared with each
+// other for anomalies and how.
+type AnomalyOptions struct {
+	domain.EventSelection
+	// Interval compares the mean of each series over time buckets instead of
+	// the readings at the same timestamp, for series that are not sampled at
+	// the same time, when set.
+	Interval time.Duration
+	// Threshold is how far below the median of all series, as a fraction of
+	// the median, a reading must be to be flagged.
+	Threshold float64
+}
+
+// ParseAnomalyOptions reads the anomaly options from the query of a request,
+// falling back to the solar readings at the same timestamp, flagged 30% below
+// the median.
+func ParseAnomalyOptions(query url.Values) (*AnomalyOptions, error) {
+	options := &AnomalyOptions{
+		Threshold: defaultAnomalyThreshold,
+	}
+
+	var err error
+
+	options.EventSelection, err = parseEventSelection(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// panels are compared with panels and turbines with turbines
+	if options.Source == domain.EventSourceAll {
+		return nil, errors.New("source must be one of " + string(domain.EventSourceSolar) + ", " + string(domain.EventSourceWind))
+	}
+
+	if interval := query.Get("interval"); interval != "" {
+		options.Interval, err = parseInterval(interval)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if threshold := query.Get("threshold"); threshold != "" {
+		options.Threshold, err = strconv.ParseFloat(threshold, 64)
+		if err != nil || !(options.Threshold > 0 && options.Threshold <= 1) {
+			return nil, errors.New("threshold must be a fraction of the median above 0 and up to 1, e.g. 0.3")
+		}
+	}
+
+	return options, nil
+}
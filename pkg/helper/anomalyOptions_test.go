@@ -0,0 +1,105 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseAnomalyOptions(t *testing.T) {
+	from := time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		query                string
+		expected             *AnomalyOptions
+		expectError          bool
+		expectedErrorMessage string
+	}{
+		{
+			name:  "valid defaults",
+			query: "",
+			expected: &AnomalyOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Threshold: 0.3,
+			},
+		},
+		{
+			name:  "valid source, window, parameters, interval and threshold",
+			query: "source=wind&from=20211231T221500Z&parameters=uuid1,uuid2,uuid3&interval=15m&threshold=0.5",
+			expected: &AnomalyOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceWind,
+					From:       &from,
+					Parameters: []string{"uuid1", "uuid2", "uuid3"},
+				},
+				Interval:  15 * time.Minute,
+				Threshold: 0.5,
+			},
+		},
+		{
+			name:                 "all sources",
+			query:                "source=all",
+			expectError:          true,
+			expectedErrorMessage: "source must be one of solar, wind",
+		},
+		{
+			name:                 "invalid interval",
+			query:                "interval=7m",
+			expectError:          true,
+			expectedErrorMessage: "interval must be a number of minutes or hours that divides a day, e.g. 15m or 1h, or 1d",
+		},
+		{
+			name:                 "invalid interval overflowing to zero",
+			query:                "interval=2251799813685248h",
+			expectError:          true,
+			expectedErrorMessage: "interval must be a number of minutes or hours that divides a day, e.g. 15m or 1h, or 1d",
+		},
+		{
+			name:                 "invalid interval overflowing to negative",
+			query:                "interval=2251799813685224h",
+			expectError:          true,
+			expectedErrorMessage: "interval must be a number of minutes or hours that divides a day, e.g. 15m or 1h, or 1d",
+		},
+		{
+			name:                 "threshold above 1",
+			query:                "threshold=1.5",
+			expectError:          true,
+			expectedErrorMessage: "threshold must be a fraction of the median above 0 and up to 1, e.g. 0.3",
+		},
+		{
+			name:                 "zero threshold",
+			query:                "threshold=0",
+			expectError:          true,
+			expectedErrorMessage: "threshold must be a fraction of the median above 0 and up to 1, e.g. 0.3",
+		},
+		{
+			name:                 "not numeric threshold",
+			query:                "threshold=low",
+			expectError:          true,
+			expectedErrorMessage: "threshold must be a fraction of the median above 0 and up to 1, e.g. 0.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			assert.NoError(t, err)
+
+			actual, err := ParseAnomalyOptions(query)
+
+			if tt.expectError {
+				assert.EqualError(t, err, tt.expectedErrorMessage)
+				assert.Nil(t, actual)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package helper
+
+import (
+	"errors"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"net/url"
+	"time"
+)
+
+const (
+	EnergyUnitWatt     = "W"
+	EnergyUnitKilowatt = "kW"
+	EnergyUnitMegawatt = "MW"
+
+	// EnergyGapsInterpolate integrates across every interval between two
+	// readings, however long, as if the power changed linearly in between.
+	EnergyGapsInterpolate = "interpolate"
+	// EnergyGapsSkip leaves out the intervals between two readings that are
+	// longer than MaxGap, counting no energy for them.
+	EnergyGapsSkip = "skip"
+
+	defaultEnergyMaxGap = time.Hour
+)
+
+// EnergyOptions controls which power series of a dataset are integrated into
+// energy and how.
+type EnergyOptions struct {
+	domain.EventSelection
+	// Unit is the unit of power the readings are in.
+	Unit   string
+	Gaps   string
+	MaxGap time.Duration
+	// Location is the timezone days start at midnight in.
+	Location *time.Location
+}
+
+// ParseEnergyOptions reads the energy options from the query of a request,
+// falling back to the solar readings in watts, interpolated across gaps, with
+// days in UTC.
+func ParseEnergyOptions(query url.Values) (*EnergyOptions, error) {
+	options := &EnergyOptions{
+		Unit:     EnergyUnitWatt,
+		Gaps:     EnergyGapsInterpolate,
+		Location: time.UTC,
+	}
+
+	var err error
+
+	options.EventSelection, err = parseEventSelection(query)
+	if err != nil {
+		return nil, err
+	}
+
+	switch unit := query.Get("unit"); unit {
+	case "":
+	case EnergyUnitWatt, EnergyUnitKilowatt, EnergyUnitMegawatt:
+		options.Unit = unit
+	default:
+		return nil, errors.New("unit must be one of " + EnergyUnitWatt + ", " + EnergyUnitKilowatt + ", " + EnergyUnitMegawatt)
+	}
+
+	switch gaps := query.Get("gaps"); gaps {
+	case "":
+	case EnergyGapsInterpolate, EnergyGapsSkip:
+		options.Gaps = gaps
+	default:
+		return nil, errors.New("gaps must be one of " + EnergyGapsInterpolate + ", " + EnergyGapsSkip)
+	}
+
+	maxGap := query.Get("maxGap")
+	if options.Gaps == EnergyGapsSkip {
+		options.MaxGap = defaultEnergyMaxGap
+	}
+
+	if maxGap != "" {
+		if options.Gaps != EnergyGapsSkip {
+			return nil, errors.New("maxGap can only be chosen with gaps " + EnergyGapsSkip)
+		}
+
+		options.MaxGap, err = time.ParseDuration(maxGap)
+		if err != nil || options.MaxGap <= 0 {
+			return nil, errors.New("maxGap must be a positive duration, e.g. 30m or 1h")
+		}
+	}
+
+	options.Location, err = parseLocation(query.Get("tz"))
+	if err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
@@ -0,0 +1,137 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseEnergyOptions(t *testing.T) {
+	from := time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC)
+
+	athens, err := time.LoadLocation("Europe/Athens")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name                 string
+		query                string
+		expected             *EnergyOptions
+		expectError          bool
+		expectedErrorMessage string
+	}{
+		{
+			name:  "valid defaults",
+			query: "",
+			expected: &EnergyOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Unit:     EnergyUnitWatt,
+				Gaps:     EnergyGapsInterpolate,
+				Location: time.UTC,
+			},
+		},
+		{
+			name:  "valid unit, source, window, parameters and timezone",
+			query: "unit=kW&source=all&from=20211231T221500Z&parameters=uuid1,uuid2&tz=Europe/Athens",
+			expected: &EnergyOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceAll,
+					From:       &from,
+					Parameters: []string{"uuid1", "uuid2"},
+				},
+				Unit:     EnergyUnitKilowatt,
+				Gaps:     EnergyGapsInterpolate,
+				Location: athens,
+			},
+		},
+		{
+			name:  "valid skipped gaps with default max gap",
+			query: "gaps=skip",
+			expected: &EnergyOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Unit:     EnergyUnitWatt,
+				Gaps:     EnergyGapsSkip,
+				MaxGap:   time.Hour,
+				Location: time.UTC,
+			},
+		},
+		{
+			name:  "valid skipped gaps with chosen max gap",
+			query: "gaps=skip&maxGap=20m&unit=MW",
+			expected: &EnergyOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Unit:     EnergyUnitMegawatt,
+				Gaps:     EnergyGapsSkip,
+				MaxGap:   20 * time.Minute,
+				Location: time.UTC,
+			},
+		},
+		{
+			name:                 "invalid unit",
+			query:                "unit=kWh",
+			expectError:          true,
+			expectedErrorMessage: "unit must be one of W, kW, MW",
+		},
+		{
+			name:                 "invalid gaps",
+			query:                "gaps=zero",
+			expectError:          true,
+			expectedErrorMessage: "gaps must be one of interpolate, skip",
+		},
+		{
+			name:                 "max gap without skipped gaps",
+			query:                "maxGap=1h",
+			expectError:          true,
+			expectedErrorMessage: "maxGap can only be chosen with gaps skip",
+		},
+		{
+			name:                 "invalid max gap",
+			query:                "gaps=skip&maxGap=-1h",
+			expectError:          true,
+			expectedErrorMessage: "maxGap must be a positive duration, e.g. 30m or 1h",
+		},
+		{
+			name:                 "invalid source",
+			query:                "source=hydro",
+			expectError:          true,
+			expectedErrorMessage: "source must be one of solar, wind, all",
+		},
+		{
+			name:                 "invalid timezone",
+			query:                "tz=Local",
+			expectError:          true,
+			expectedErrorMessage: "tz must be an IANA timezone name, e.g. Europe/Athens",
+		},
+		{
+			name:                 "invalid window",
+			query:                "from=20220101T000000Z&to=20211231T000000Z",
+			expectError:          true,
+			expectedErrorMessage: "from must be before to",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			assert.NoError(t, err)
+
+			actual, err := ParseEnergyOptions(query)
+
+			if tt.expectError {
+				assert.EqualError(t, err, tt.expectedErrorMessage)
+				assert.Nil(t, actual)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
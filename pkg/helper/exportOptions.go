@@ -0,0 +1,460 @@
+package helper
+
+import (
+	"errors"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ExportLayoutEvents is a single column of event values, kept as the default
+	// for the clients that relied on it.
+	ExportLayoutEvents = "events"
+	// ExportLayoutLong is one row per event, with the columns of ExportOptions.
+	ExportLayoutLong = "long"
+	// ExportLayoutWide is one row per timestamp, with a column per parameter id.
+	ExportLayoutWide = "wide"
+
+	// ExportSortParameterId orders the events by source, parameter id and
+	// timestamp.
+	ExportSortParameterId = "parameterId"
+	// ExportSortTimestamp orders the events by timestamp, source and parameter id.
+	ExportSortTimestamp = "timestamp"
+
+	ExportAggregationSum   = "sum"
+	ExportAggregationMean  = "mean"
+	ExportAggregationMin   = "min"
+	ExportAggregationMax   = "max"
+	ExportAggregationLast  = "last"
+	ExportAggregationCount = "count"
+
+	// ExportFillNone leaves out the buckets without events.
+	ExportFillNone = "none"
+	// ExportFillZero fills the buckets without events with 0.
+	ExportFillZero = "zero"
+	// ExportFillPrevious fills the buckets without events with the value of the
+	// bucket before them.
+	ExportFillPrevious = "previous"
+	// ExportFillLinear fills the buckets without events with the values on the
+	// line between the buckets around them.
+	ExportFillLinear = "linear"
+
+	ExportColumnSource      = "source"
+	ExportColumnParameterId = "parameterId"
+	ExportColumnTimestamp   = "timestamp"
+	ExportColumnValue       = "value"
+	// ExportColumnFilled flags the events filled in for a bucket without
+	// events.
+	ExportColumnFilled = "filled"
+)
+
+// ExportOptions controls which events of a dataset are exported and how.
+type ExportOptions struct {
+	domain.EventSelection
+	Layout  string
+	Columns []string
+	Sort    string
+	// Interval buckets the events of each series by time, exporting a single
+	// Aggregation of each bucket instead, when set. Buckets start at midnight
+	// in Location, and the ones without events are filled as Fill asks.
+	Interval    time.Duration
+	Aggregation string
+	Location    *time.Location
+	Fill        string
+}
+
+// ParseExportOptions reads the export options from the query of a request,
+// falling back to the solar events in the events layout, ordered by parameter
+// id.
+func ParseExportOptions(query url.Values) (*ExportOptions, error) {
+	options := &ExportOptions{
+		Layout: ExportLayoutEvents,
+		Sort:   ExportSortParameterId,
+	}
+
+	var err error
+
+	options.EventSelection, err = parseEventSelection(query)
+	if err != nil {
+		return nil, err
+	}
+
+	switch layout := query.Get("layout"); layout {
+	case "":
+	case ExportLayoutEvents, ExportLayoutLong, ExportLayoutWide:
+		options.Layout = layout
+	default:
+		return nil, errors.New("layout must be one of " + ExportLayoutEvents + ", " + ExportLayoutLong + ", " + ExportLayoutWide)
+	}
+
+	switch sort := query.Get("sort"); sort {
+	case "":
+	case ExportSortParameterId, ExportSortTimestamp:
+		options.Sort = sort
+	default:
+		return nil, errors.New("sort must be one of " + ExportSortParameterId + ", " + ExportSortTimestamp)
+	}
+
+	err = parseAggregation(query, options)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := query.Get("columns")
+	if columns == "" {
+		if options.Layout == ExportLayoutLong {
+			options.Columns = []string{ExportColumnParameterId, ExportColumnTimestamp, ExportColumnValue}
+
+			if fillsBuckets(options) {
+				options.Columns = append(options.Columns, ExportColumnFilled)
+			}
+		}
+
+		return options, nil
+	}
+
+	if options.Layout != ExportLayoutLong {
+		return nil, errors.New("columns can only be chosen with layout " + ExportLayoutLong)
+	}
+
+	for _, column := range strings.Split(columns, ",") {
+		switch column {
+		case ExportColumnSource, ExportColumnParameterId, ExportColumnTimestamp, ExportColumnValue, ExportColumnFilled:
+			options.Columns = append(options.Columns, column)
+		default:
+			return nil, errors.New(
+				"columns must be a comma separated list of " + ExportColumnSource + ", " +
+					ExportColumnParameterId + ", " + ExportColumnTimestamp + ", " + ExportColumnValue + ", " +
+					ExportColumnFilled,
+			)
+		}
+	}
+
+	return options, nil
+}
+
+// parseEventSelection reads the source, time window and parameter ids of the
+// events to use, falling back to every solar event.
+func parseEventSelection(query url.Values) (domain.EventSelection, error) {
+	var selection domain.EventSelection
+	var err error
+
+	selection.Source, err = parseSource(query.Get("source"), domain.EventSourceSolar)
+	if err != nil {
+		return domain.EventSelection{}, err
+	}
+
+	selection.From, selection.To, err = parseTimeWindow(query)
+	if err != nil {
+		return domain.EventSelection{}, err
+	}
+
+	selection.Parameters, err = parseParameters(query)
+	if err != nil {
+		return domain.EventSelection{}, err
+	}
+
+	return selection, nil
+}
+
+// parseTimeWindow reads the from and to bounds of the time window, either of
+// which may be left open.
+func parseTimeWindow(query url.Values) (*time.Time, *time.Time, error) {
+	from, err := parseTimeBound(query.Get("from"))
+	if err != nil {
+		return nil, nil, errors.New("from " + err.Error())
+	}
+
+	to, err := parseTimeBound(query.Get("to"))
+	if err != nil {
+		return nil, nil, errors.New("to " + err.Error())
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, errors.New("from must be before to")
+	}
+
+	return from, to, nil
+}
+
+// parseTimeBound reads a time window bound in the format of the events or in
+// RFC 3339, for clients that already have one at hand.
+func parseTimeBound(bound string) (*time.Time, error) {
+	if bound == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{domain.EventTimestampLayout, time.RFC3339} {
+		parsed, err := time.Parse(layout, bound)
+		if err == nil {
+			parsed = parsed.UTC()
+
+			return &parsed, nil
+		}
+	}
+
+	return nil, errors.New("must be a timestamp in the " + domain.EventTimestampLayout + " or RFC 3339 format")
+}
+
+// parseParameters reads the parameter ids from both the repeatable parameter
+// and the comma separated parameters query parameters, without duplicates.
+func parseParameters(query url.Values) ([]string, error) {
+	var parameters []string
+	seen := map[string]bool{}
+
+	requested := append([]string{}, query["parameter"]...)
+	for _, list := range query["parameters"] {
+		requested = append(requested, strings.Split(list, ",")...)
+	}
+
+	for _, parameterId := range requested {
+		if parameterId == "" {
+			return nil, errors.New("parameters must be a comma separated list of parameter ids")
+		}
+
+		if seen[parameterId] {
+			continue
+		}
+
+		seen[parameterId] = true
+		parameters = append(parameters, parameterId)
+	}
+
+	return parameters, nil
+}
+
+// parseAggregation reads the interval, aggregation, timezone and filling of the
+// buckets, which default to the mean in UTC without filling once an interval is
+// chosen.
+func parseAggregation(query url.Values, options *ExportOptions) error {
+	interval := query.Get("interval")
+	if interval == "" {
+		if query.Get("agg") != "" || query.Get("tz") != "" {
+			return errors.New("agg and tz can only be chosen with interval")
+		}
+
+		// without buckets there is no telling which readings are missing
+		if query.Get("fill") != "" {
+			return errors.New("fill can only be chosen with interval")
+		}
+
+		return nil
+	}
+
+	var err error
+
+	options.Interval, err = parseInterval(interval)
+	if err != nil {
+		return err
+	}
+
+	switch aggregation := query.Get("agg"); aggregation {
+	case "":
+		options.Aggregation = ExportAggregationMean
+	case ExportAggregationSum, ExportAggregationMean, ExportAggregationMin, ExportAggregationMax,
+		ExportAggregationLast, ExportAggregationCount:
+		options.Aggregation = aggregation
+	default:
+		return errors.New(
+			"agg must be one of " + ExportAggregationSum + ", " + ExportAggregationMean + ", " +
+				ExportAggregationMin + ", " + ExportAggregationMax + ", " + ExportAggregationLast + ", " +
+				ExportAggregationCount,
+		)
+	}
+
+	switch fill := query.Get("fill"); fill {
+	case "":
+		options.Fill = ExportFillNone
+	case ExportFillNone, ExportFillZero, ExportFillPrevious, ExportFillLinear:
+		options.Fill = fill
+	default:
+		return errors.New(
+			"fill must be one of " + ExportFillNone + ", " + ExportFillZero + ", " + ExportFillPrevious + ", " +
+				ExportFillLinear,
+		)
+	}
+
+	options.Location, err = parseLocation(query.Get("tz"))
+
+	return err
+}
+
+// fillsBuckets returns whether the export fills in the buckets without events,
+// in which case the formats flag the events that were filled in.
+func fillsBuckets(options *ExportOptions) bool {
+	return options.Interval > 0 && options.Fill != "" && options.Fill != ExportFillNone
+}
+
+// intervalPattern matches a bucket length such as 15m, 1h or 1d.
+var intervalPattern = regexp.MustCompile(`^([1-9][0-9]*)([mhd])$`)
+
+// parseInterval reads the length of time buckets, which must divide a day so
+// that every day is bucketed the same.
+func parseInterval(interval string) (time.Duration, error) {
+	invalidIntervalError := errors.New("interval must be a number of minutes or hours that divides a day, e.g. 15m or 1h, or 1d")
+
+	matches := intervalPattern.FindStringSubmatch(interval)
+	if matches == nil {
+		return 0, invalidIntervalError
+	}
+
+	amount, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, invalidIntervalError
+	}
+
+	// bounded before multiplying, as a large amount overflows the duration
+	var unit time.Duration
+	var maximumAmount int
+
+	switch matches[2] {
+	case "m":
+		unit, maximumAmount = time.Minute, 24*60
+	case "h":
+		unit, maximumAmount = time.Hour, 24
+	case "d":
+		unit, maximumAmount = 24*time.Hour, 1
+	}
+
+	if amount > maximumAmount {
+		return 0, invalidIntervalError
+	}
+
+	parsed := time.Duration(amount) * unit
+	if parsed <= 0 || (24*time.Hour)%parsed != 0 {
+		return 0, invalidIntervalError
+	}
+
+	return parsed, nil
+}
+
+// parseSource reads the source of the events, falling back to the given one.
+func parseSource(source string, fallback domain.EventSource) (domain.EventSource, error) {
+	switch source := domain.EventSource(source); source {
+	case "":
+		return fallback, nil
+	case domain.EventSourceSolar, domain.EventSourceWind, domain.EventSourceAll:
+		return source, nil
+	default:
+		return "", errors.New(
+			"source must be one of " + string(domain.EventSourceSolar) + ", " +
+				string(domain.EventSourceWind) + ", " + string(domain.EventSourceAll),
+		)
+	}
+}
+
+// parseLocation reads the timezone days start at midnight in, falling back to
+// UTC.
+func parseLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.UTC, nil
+	}
+
+	// an empty or "Local" name would be the timezone of the server
+	location, err := time.LoadLocation(tz)
+	if err != nil || tz == "Local" {
+		return nil, errors.New("tz must be an IANA timezone name, e.g. Europe/Athens")
+	}
+
+	return location, nil
+}
+
+// exportedData returns the solar panel data as it is exported: the selected
+// events, aggregated into buckets when an interval is chosen, along with the
+// events filled in for the buckets without any. Every encoder starts from it, so
+// that each format exports the same events.
+func exportedData(
+	solarPanelData *domain.SolarPanelData,
+	options *ExportOptions,
+) (*domain.SolarPanelData, filledEvents, error) {
+	selected, err := selectEvents(solarPanelData, &options.EventSelection)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aggregated, filled := aggregateEvents(selected, options)
+
+	return aggregated, filled, nil
+}
+
+// selectEvents returns the solar panel data with only the selected events.
+// Asking for parameter ids the chosen source does not have is an error, as an
+// export silently missing a series is easy to mistake for a complete one.
+func selectEvents(
+	solarPanelData *domain.SolarPanelData,
+	selection *domain.EventSelection,
+) (*domain.SolarPanelData, error) {
+	if selection.From == nil && selection.To == nil && len(selection.Parameters) == 0 {
+		return solarPanelData, nil
+	}
+
+	var unknownParameterIds []string
+
+	for _, parameterId := range selection.Parameters {
+		known := false
+
+		for _, source := range selection.Source.Sources() {
+			if _, ok := solarPanelData.EventsOf(source)[parameterId]; ok {
+				known = true
+			}
+		}
+
+		if !known {
+			unknownParameterIds = append(unknownParameterIds, parameterId)
+		}
+	}
+
+	if len(unknownParameterIds) > 0 {
+		return nil, apierrors.UnknownParametersError{
+			ReturnedStatusCode: http.StatusNotFound,
+			ParameterIds:       unknownParameterIds,
+		}
+	}
+
+	selected := *solarPanelData
+	selected.Solar = selectSeriesEvents(solarPanelData.Solar, selection)
+	selected.Wind = selectSeriesEvents(solarPanelData.Wind, selection)
+
+	return &selected, nil
+}
+
+func selectSeriesEvents(events map[string][]domain.Event, selection *domain.EventSelection) map[string][]domain.Event {
+	if events == nil {
+		return nil
+	}
+
+	parameters := map[string]bool{}
+	for _, parameterId := range selection.Parameters {
+		parameters[parameterId] = true
+	}
+
+	selected := make(map[string][]domain.Event, len(events))
+
+	for parameterId, parameterIdEvents := range events {
+		if len(parameters) > 0 && !parameters[parameterId] {
+			continue
+		}
+
+		selected[parameterId] = []domain.Event{}
+
+		for _, event := range parameterIdEvents {
+			if selection.From != nil && event.Timestamp.Before(*selection.From) {
+				continue
+			}
+
+			if selection.To != nil && !event.Timestamp.Before(*selection.To) {
+				continue
+			}
+
+			selected[parameterId] = append(selected[parameterId], event)
+		}
+	}
+
+	return selected
+}
@@ -0,0 +1,483 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseExportOptions(t *testing.T) {
+	from := time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC)
+	to := time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC)
+
+	athens, err := time.LoadLocation("Europe/Athens")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name                 string
+		query                string
+		expected             *ExportOptions
+		expectError          bool
+		expectedErrorMessage string
+	}{
+		{
+			name:  "valid defaults",
+			query: "",
+			expected: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout: ExportLayoutEvents,
+				Sort:   ExportSortParameterId,
+			},
+		},
+		{
+			name:  "valid long layout with default columns",
+			query: "source=all&layout=long",
+			expected: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceAll,
+				},
+				Layout:  ExportLayoutLong,
+				Columns: []string{ExportColumnParameterId, ExportColumnTimestamp, ExportColumnValue},
+				Sort:    ExportSortParameterId,
+			},
+		},
+		{
+			name:  "valid long layout with chosen columns",
+			query: "layout=long&columns=value,source,timestamp",
+			expected: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout:  ExportLayoutLong,
+				Columns: []string{ExportColumnValue, ExportColumnSource, ExportColumnTimestamp},
+				Sort:    ExportSortParameterId,
+			},
+		},
+		{
+			name:  "valid timestamp sort",
+			query: "sort=timestamp",
+			expected: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout: ExportLayoutEvents,
+				Sort:   ExportSortTimestamp,
+			},
+		},
+		{
+			name:  "valid wide layout",
+			query: "source=wind&layout=wide",
+			expected: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceWind,
+				},
+				Layout: ExportLayoutWide,
+				Sort:   ExportSortParameterId,
+			},
+		},
+		{
+			name:  "valid time window in the event format",
+			query: "from=20211231T221500Z&to=20220101T060000Z",
+			expected: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+					From:   &from,
+					To:     &to,
+				},
+				Layout: ExportLayoutEvents,
+				Sort:   ExportSortParameterId,
+			},
+		},
+		{
+			name:  "valid time window in RFC 3339",
+			query: "from=2022-01-01T00:15:00%2B02:00&to=2022-01-01T06:00:00Z",
+			expected: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+					From:   &from,
+					To:     &to,
+				},
+				Layout: ExportLayoutEvents,
+				Sort:   ExportSortParameterId,
+			},
+		},
+		{
+			name:  "valid open ended time window",
+			query: "to=20220101T060000Z",
+			expected: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+					To:     &to,
+				},
+				Layout: ExportLayoutEvents,
+				Sort:   ExportSortParameterId,
+			},
+		},
+		{
+			name:  "valid parameters",
+			query: "parameter=uuid2&parameters=uuid1,uuid3&parameter=uuid1",
+			expected: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceSolar,
+					Parameters: []string{"uuid2", "uuid1", "uuid3"},
+				},
+				Layout: ExportLayoutEvents,
+				Sort:   ExportSortParameterId,
+			},
+		},
+		{
+			name:                 "invalid parameters",
+			query:                "parameters=uuid1,,uuid3",
+			expectError:          true,
+			expectedErrorMessage: "parameters must be a comma separated list of parameter ids",
+		},
+		{
+			name:  "valid interval with default aggregation",
+			query: "interval=15m",
+			expected: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout:      ExportLayoutEvents,
+				Sort:        ExportSortParameterId,
+				Interval:    15 * time.Minute,
+				Aggregation: ExportAggregationMean,
+				Location:    time.UTC,
+				Fill:        ExportFillNone,
+			},
+		},
+		{
+			name:  "valid daily interval with aggregation and timezone",
+			query: "interval=1d&agg=sum&tz=Europe/Athens",
+			expected: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout:      ExportLayoutEvents,
+				Sort:        ExportSortParameterId,
+				Interval:    24 * time.Hour,
+				Aggregation: ExportAggregationSum,
+				Location:    athens,
+				Fill:        ExportFillNone,
+			},
+		},
+		{
+			name:  "valid fill with long layout flagging filled events",
+			query: "interval=1h&fill=linear&layout=long",
+			expected: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout:      ExportLayoutLong,
+				Columns:     []string{ExportColumnParameterId, ExportColumnTimestamp, ExportColumnValue, ExportColumnFilled},
+				Sort:        ExportSortParameterId,
+				Interval:    time.Hour,
+				Aggregation: ExportAggregationMean,
+				Location:    time.UTC,
+				Fill:        ExportFillLinear,
+			},
+		},
+		{
+			name:  "valid none fill with long layout",
+			query: "interval=1h&fill=none&layout=long",
+			expected: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Layout:      ExportLayoutLong,
+				Columns:     []string{ExportColumnParameterId, ExportColumnTimestamp, ExportColumnValue},
+				Sort:        ExportSortParameterId,
+				Interval:    time.Hour,
+				Aggregation: ExportAggregationMean,
+				Location:    time.UTC,
+				Fill:        ExportFillNone,
+			},
+		},
+		{
+			name:                 "invalid fill",
+			query:                "interval=1h&fill=next",
+			expectError:          true,
+			expectedErrorMessage: "fill must be one of none, zero, previous, linear",
+		},
+		{
+			name:                 "invalid fill without interval",
+			query:                "fill=zero",
+			expectError:          true,
+			expectedErrorMessage: "fill can only be chosen with interval",
+		},
+		{
+			name:                 "invalid interval not dividing a day",
+			query:                "interval=7m",
+			expectError:          true,
+			expectedErrorMessage: "interval must be a number of minutes or hours that divides a day, e.g. 15m or 1h, or 1d",
+		},
+		{
+			name:                 "invalid interval over a day",
+			query:                "interval=2d",
+			expectError:          true,
+			expectedErrorMessage: "interval must be a number of minutes or hours that divides a day, e.g. 15m or 1h, or 1d",
+		},
+		{
+			name:                 "invalid interval unit",
+			query:                "interval=30s",
+			expectError:          true,
+			expectedErrorMessage: "interval must be a number of minutes or hours that divides a day, e.g. 15m or 1h, or 1d",
+		},
+		{
+			name:                 "invalid interval overflowing to zero",
+			query:                "interval=2251799813685248h",
+			expectError:          true,
+			expectedErrorMessage: "interval must be a number of minutes or hours that divides a day, e.g. 15m or 1h, or 1d",
+		},
+		{
+			name:                 "invalid interval overflowing to negative",
+			query:                "interval=2251799813685224h",
+			expectError:          true,
+			expectedErrorMessage: "interval must be a number of minutes or hours that divides a day, e.g. 15m or 1h, or 1d",
+		},
+		{
+			name:                 "invalid interval in minutes over a day",
+			query:                "interval=1441m",
+			expectError:          true,
+			expectedErrorMessage: "interval must be a number of minutes or hours that divides a day, e.g. 15m or 1h, or 1d",
+		},
+		{
+			name:                 "invalid negative interval",
+			query:                "interval=-1h",
+			expectError:          true,
+			expectedErrorMessage: "interval must be a number of minutes or hours that divides a day, e.g. 15m or 1h, or 1d",
+		},
+		{
+			name:                 "invalid aggregation",
+			query:                "interval=1h&agg=median",
+			expectError:          true,
+			expectedErrorMessage: "agg must be one of sum, mean, min, max, last, count",
+		},
+		{
+			name:                 "invalid aggregation without interval",
+			query:                "agg=sum",
+			expectError:          true,
+			expectedErrorMessage: "agg and tz can only be chosen with interval",
+		},
+		{
+			name:                 "invalid timezone",
+			query:                "interval=1h&tz=Mars/Olympus",
+			expectError:          true,
+			expectedErrorMessage: "tz must be an IANA timezone name, e.g. Europe/Athens",
+		},
+		{
+			name:                 "invalid from",
+			query:                "from=2021-12-31",
+			expectError:          true,
+			expectedErrorMessage: "from must be a timestamp in the 20060102T150405Z or RFC 3339 format",
+		},
+		{
+			name:                 "invalid to",
+			query:                "to=yesterday",
+			expectError:          true,
+			expectedErrorMessage: "to must be a timestamp in the 20060102T150405Z or RFC 3339 format",
+		},
+		{
+			name:                 "invalid from not before to",
+			query:                "from=20220101T060000Z&to=20220101T060000Z",
+			expectError:          true,
+			expectedErrorMessage: "from must be before to",
+		},
+		{
+			name:                 "invalid source",
+			query:                "source=tidal",
+			expectError:          true,
+			expectedErrorMessage: "source must be one of solar, wind, all",
+		},
+		{
+			name:                 "invalid layout",
+			query:                "layout=short",
+			expectError:          true,
+			expectedErrorMessage: "layout must be one of events, long, wide",
+		},
+		{
+			name:                 "invalid sort",
+			query:                "sort=value",
+			expectError:          true,
+			expectedErrorMessage: "sort must be one of parameterId, timestamp",
+		},
+		{
+			name:                 "invalid columns without long layout",
+			query:                "columns=value",
+			expectError:          true,
+			expectedErrorMessage: "columns can only be chosen with layout long",
+		},
+		{
+			name:                 "invalid columns with wide layout",
+			query:                "layout=wide&columns=value",
+			expectError:          true,
+			expectedErrorMessage: "columns can only be chosen with layout long",
+		},
+		{
+			name:                 "invalid column",
+			query:                "layout=long&columns=value,,timestamp",
+			expectError:          true,
+			expectedErrorMessage: "columns must be a comma separated list of source, parameterId, timestamp, value, filled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			assert.NoError(t, err)
+
+			actual, err := ParseExportOptions(query)
+			if (err != nil) != tt.expectError {
+				t.Errorf("ParseExportOptions() error = %v, expectError %v", err, tt.expectError)
+				return
+			}
+
+			assert.Equal(t, tt.expected, actual)
+
+			if tt.expectError {
+				assert.Equal(t, tt.expectedErrorMessage, err.Error())
+			}
+		})
+	}
+}
+
+func TestSelectEvents(t *testing.T) {
+	from := time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC)
+
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+				{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+				{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 3},
+			},
+			"uuid2": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+			},
+		},
+		Name: "roof",
+	}
+
+	tests := []struct {
+		name          string
+		selection     *domain.EventSelection
+		expected      *domain.SolarPanelData
+		expectError   bool
+		expectedError error
+	}{
+		{
+			name:      "without selection",
+			selection: &domain.EventSelection{},
+			expected:  solarPanelData,
+		},
+		{
+			name:      "from",
+			selection: &domain.EventSelection{From: &from},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+						{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 3},
+					},
+					"uuid2": []domain.Event{},
+				},
+				Name: "roof",
+			},
+		},
+		{
+			name:      "to",
+			selection: &domain.EventSelection{To: &to},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+					},
+					"uuid2": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+					},
+				},
+				Name: "roof",
+			},
+		},
+		{
+			name:      "from and to",
+			selection: &domain.EventSelection{From: &from, To: &to},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+					},
+					"uuid2": []domain.Event{},
+				},
+				Name: "roof",
+			},
+		},
+		{
+			name:      "parameters",
+			selection: &domain.EventSelection{Source: domain.EventSourceSolar, Parameters: []string{"uuid2"}},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid2": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+					},
+				},
+				Name: "roof",
+			},
+		},
+		{
+			name:      "parameters and time window",
+			selection: &domain.EventSelection{Source: domain.EventSourceAll, Parameters: []string{"uuid1"}, From: &from},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+						{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 3},
+					},
+				},
+				Name: "roof",
+			},
+		},
+		{
+			name:        "unknown parameters",
+			selection:   &domain.EventSelection{Source: domain.EventSourceSolar, Parameters: []string{"uuid3", "uuid1", "uuid4"}},
+			expected:    nil,
+			expectError: true,
+			expectedError: apierrors.UnknownParametersError{
+				ReturnedStatusCode: http.StatusNotFound,
+				ParameterIds:       []string{"uuid3", "uuid4"},
+			},
+		},
+		{
+			name:        "parameters of another source",
+			selection:   &domain.EventSelection{Source: domain.EventSourceWind, Parameters: []string{"uuid1"}},
+			expected:    nil,
+			expectError: true,
+			expectedError: apierrors.UnknownParametersError{
+				ReturnedStatusCode: http.StatusNotFound,
+				ParameterIds:       []string{"uuid1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, actualError := selectEvents(solarPanelData, tt.selection)
+			if (actualError != nil) != tt.expectError {
+				t.Errorf("selectEvents() error = %v, expectError %v", actualError, tt.expectError)
+				return
+			}
+
+			assert.Equal(t, tt.expected, actual)
+
+			if tt.expectError {
+				assert.Equal(t, tt.expectedError, actualError)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package helper
+
+import (
+	"errors"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"net/url"
+	"strconv"
+)
+
+const defaultQualityFlatlineReadings = 4
+
+// QualityOptions controls which series of a dataset are checked for data
+// quality issues and how.
+type QualityOptions struct {
+	domain.EventSelection
+	// FlatlineReadings is the number of consecutive readings of the same value
+	// from which a series counts as flatlined.
+	FlatlineReadings int
+}
+
+// ParseQualityOptions reads the quality options from the query of a request,
+// falling back to every solar event, flatlined from 4 readings of the same
+// value.
+func ParseQualityOptions(query url.Values) (*QualityOptions, error) {
+	options := &QualityOptions{
+		FlatlineReadings: defaultQualityFlatlineReadings,
+	}
+
+	var err error
+
+	options.EventSelection, err = parseEventSelection(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if flatline := query.Get("flatline"); flatline != "" {
+		options.FlatlineReadings, err = strconv.Atoi(flatline)
+		if err != nil || options.FlatlineReadings < 2 {
+			return nil, errors.New("flatline must be a number of readings of at least 2")
+		}
+	}
+
+	return options, nil
+}
@@ -0,0 +1,80 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseQualityOptions(t *testing.T) {
+	from := time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		query                string
+		expected             *QualityOptions
+		expectError          bool
+		expectedErrorMessage string
+	}{
+		{
+			name:  "valid defaults",
+			query: "",
+			expected: &QualityOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				FlatlineReadings: 4,
+			},
+		},
+		{
+			name:  "valid source, window, parameters and flatline",
+			query: "source=all&from=20211231T221500Z&parameters=uuid1&flatline=6",
+			expected: &QualityOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceAll,
+					From:       &from,
+					Parameters: []string{"uuid1"},
+				},
+				FlatlineReadings: 6,
+			},
+		},
+		{
+			name:                 "invalid flatline",
+			query:                "flatline=1",
+			expectError:          true,
+			expectedErrorMessage: "flatline must be a number of readings of at least 2",
+		},
+		{
+			name:                 "not numeric flatline",
+			query:                "flatline=many",
+			expectError:          true,
+			expectedErrorMessage: "flatline must be a number of readings of at least 2",
+		},
+		{
+			name:                 "invalid source",
+			query:                "source=hydro",
+			expectError:          true,
+			expectedErrorMessage: "source must be one of solar, wind, all",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			assert.NoError(t, err)
+
+			actual, err := ParseQualityOptions(query)
+
+			if tt.expectError {
+				assert.EqualError(t, err, tt.expectedErrorMessage)
+				assert.Nil(t, actual)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
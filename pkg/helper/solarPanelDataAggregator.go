@@ -0,0 +1,217 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"sort"
+	"time"
+)
+
+// filledEvents are the events an aggregation filled in for the buckets without
+// events, by series and bucket start. They are kept apart from the events, as
+// only the exports tell them from the read ones.
+type filledEvents map[filledEvent]bool
+
+type filledEvent struct {
+	source      domain.EventSource
+	parameterId string
+	// start is the bucket start in nanoseconds, as time.Time values of the same
+	// instant may not be equal map keys
+	start int64
+}
+
+// contains reports whether the event of the series was filled in.
+func (filled filledEvents) contains(source domain.EventSource, parameterId string, event domain.Event) bool {
+	return filled[filledEvent{source: source, parameterId: parameterId, start: event.Timestamp.UnixNano()}]
+}
+
+// aggregateEvents replaces the events of each series with one event per time
+// bucket of the options interval, timestamped at the start of the bucket and
+// valued by the options aggregation of the events in it, also returning the
+// events it filled in. Buckets without events are left out, unless the options
+// fill them, and the ones at the edges of the data only aggregate the events
+// they have. Without an interval the solar panel data is returned as it is.
+func aggregateEvents(
+	solarPanelData *domain.SolarPanelData,
+	options *ExportOptions,
+) (*domain.SolarPanelData, filledEvents) {
+	if options.Interval == 0 {
+		return solarPanelData, nil
+	}
+
+	filled := filledEvents{}
+
+	aggregated := *solarPanelData
+	aggregated.Solar = aggregateSeries(domain.EventSourceSolar, solarPanelData.Solar, options, filled)
+	aggregated.Wind = aggregateSeries(domain.EventSourceWind, solarPanelData.Wind, options, filled)
+
+	return &aggregated, filled
+}
+
+func aggregateSeries(
+	source domain.EventSource,
+	events map[string][]domain.Event,
+	options *ExportOptions,
+	filled filledEvents,
+) map[string][]domain.Event {
+	if events == nil {
+		return nil
+	}
+
+	aggregated := make(map[string][]domain.Event, len(events))
+
+	for parameterId, parameterIdEvents := range events {
+		sortedEvents := make([]domain.Event, len(parameterIdEvents))
+		copy(sortedEvents, parameterIdEvents)
+		sort.SliceStable(sortedEvents, func(i, j int) bool {
+			return sortedEvents[i].Timestamp.Before(sortedEvents[j].Timestamp)
+		})
+
+		aggregated[parameterId] = []domain.Event{}
+
+		// events are in timestamp order, so the events of a bucket are next to
+		// each other
+		var bucket []float64
+		var start time.Time
+
+		for _, event := range sortedEvents {
+			eventStart := bucketStart(event.Timestamp, options.Interval, options.Location)
+
+			if len(bucket) > 0 && !eventStart.Equal(start) {
+				aggregated[parameterId] = append(aggregated[parameterId], domain.Event{
+					Timestamp: start,
+					Value:     aggregate(bucket, options.Aggregation),
+				})
+				bucket = nil
+			}
+
+			start = eventStart
+			bucket = append(bucket, event.Value)
+		}
+
+		if len(bucket) > 0 {
+			aggregated[parameterId] = append(aggregated[parameterId], domain.Event{
+				Timestamp: start,
+				Value:     aggregate(bucket, options.Aggregation),
+			})
+		}
+
+		if fillsBuckets(options) {
+			aggregated[parameterId] = fillBuckets(aggregated[parameterId], options, func(start time.Time) {
+				filled[filledEvent{source: source, parameterId: parameterId, start: start.UnixNano()}] = true
+			})
+		}
+	}
+
+	return aggregated
+}
+
+// fillBuckets adds an event for every bucket without events between the first
+// and the last bucket of a series, whose events are one per bucket and in
+// timestamp order, passing the start of each to markFilled. The buckets before
+// the first and after the last are left out, as there is nothing to fill them
+// from.
+func fillBuckets(events []domain.Event, options *ExportOptions, markFilled func(time.Time)) []domain.Event {
+	if len(events) == 0 {
+		return events
+	}
+
+	filled := []domain.Event{events[0]}
+
+	for _, event := range events[1:] {
+		previous := filled[len(filled)-1]
+
+		start := nextBucketStart(previous.Timestamp, options)
+		for start.Before(event.Timestamp) {
+			filledEvent := domain.Event{Timestamp: start}
+
+			switch options.Fill {
+			case ExportFillPrevious:
+				filledEvent.Value = previous.Value
+			case ExportFillLinear:
+				// by the time elapsed rather than the buckets, which are not
+				// all as long when daylight saving time changes
+				elapsed := float64(start.Sub(previous.Timestamp)) / float64(event.Timestamp.Sub(previous.Timestamp))
+				filledEvent.Value = previous.Value + (event.Value-previous.Value)*elapsed
+			}
+
+			filled = append(filled, filledEvent)
+			markFilled(start)
+			start = nextBucketStart(start, options)
+		}
+
+		filled = append(filled, event)
+	}
+
+	return filled
+}
+
+// nextBucketStart returns the start of the bucket after the one starting at the
+// given time.
+func nextBucketStart(start time.Time, options *ExportOptions) time.Time {
+	// a day later may still be the same local day when daylight saving time
+	// ends
+	if options.Interval == 24*time.Hour {
+		local := start.In(options.Location)
+
+		return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, options.Location).UTC()
+	}
+
+	return bucketStart(start.Add(options.Interval), options.Interval, options.Location)
+}
+
+// bucketStart returns the start of the bucket a timestamp falls in. Buckets
+// are counted from midnight in the location, so that they line up with the
+// days there. Daily buckets follow the local days even when daylight saving
+// time makes them shorter or longer than 24 hours.
+func bucketStart(timestamp time.Time, interval time.Duration, location *time.Location) time.Time {
+	local := timestamp.In(location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+
+	if interval == 24*time.Hour {
+		return midnight.UTC()
+	}
+
+	return midnight.Add(timestamp.Sub(midnight) / interval * interval).UTC()
+}
+
+// aggregate returns the aggregation of the values of a bucket, which are in
+// timestamp order and never empty.
+func aggregate(values []float64, aggregation string) float64 {
+	switch aggregation {
+	case ExportAggregationSum:
+		return sum(values)
+	case ExportAggregationMin:
+		minimum := values[0]
+		for _, value := range values {
+			if value < minimum {
+				minimum = value
+			}
+		}
+
+		return minimum
+	case ExportAggregationMax:
+		maximum := values[0]
+		for _, value := range values {
+			if value > maximum {
+				maximum = value
+			}
+		}
+
+		return maximum
+	case ExportAggregationLast:
+		return values[len(values)-1]
+	case ExportAggregationCount:
+		return float64(len(values))
+	default:
+		return sum(values) / float64(len(values))
+	}
+}
+
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+
+	return total
+}
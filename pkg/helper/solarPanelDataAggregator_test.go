@@ -0,0 +1,395 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAggregateEvents(t *testing.T) {
+	athens, err := time.LoadLocation("Europe/Athens")
+	assert.NoError(t, err)
+
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	assert.NoError(t, err)
+
+	quarterHourly := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 10, 20, 0, 0, time.UTC), Value: 4},
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 1},
+				{Timestamp: time.Date(2022, 1, 1, 10, 10, 0, 0, time.UTC), Value: 3},
+				{Timestamp: time.Date(2022, 1, 1, 10, 5, 0, 0, time.UTC), Value: 2},
+				{Timestamp: time.Date(2022, 1, 1, 10, 45, 0, 0, time.UTC), Value: 8},
+			},
+			"uuid2": []domain.Event{},
+		},
+		Name: "roof",
+	}
+
+	tests := []struct {
+		name           string
+		solarPanelData *domain.SolarPanelData
+		options        *ExportOptions
+		expected       *domain.SolarPanelData
+	}{
+		{
+			name:           "without interval",
+			solarPanelData: quarterHourly,
+			options:        &ExportOptions{},
+			expected:       quarterHourly,
+		},
+		{
+			name:           "mean",
+			solarPanelData: quarterHourly,
+			options:        &ExportOptions{Interval: 15 * time.Minute, Aggregation: ExportAggregationMean, Location: time.UTC},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 2},
+						{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 4},
+						{Timestamp: time.Date(2022, 1, 1, 10, 45, 0, 0, time.UTC), Value: 8},
+					},
+					"uuid2": []domain.Event{},
+				},
+				Name: "roof",
+			},
+		},
+		{
+			name:           "sum",
+			solarPanelData: quarterHourly,
+			options:        &ExportOptions{Interval: time.Hour, Aggregation: ExportAggregationSum, Location: time.UTC},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 18},
+					},
+					"uuid2": []domain.Event{},
+				},
+				Name: "roof",
+			},
+		},
+		{
+			name:           "min",
+			solarPanelData: quarterHourly,
+			options:        &ExportOptions{Interval: 15 * time.Minute, Aggregation: ExportAggregationMin, Location: time.UTC},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 1},
+						{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 4},
+						{Timestamp: time.Date(2022, 1, 1, 10, 45, 0, 0, time.UTC), Value: 8},
+					},
+					"uuid2": []domain.Event{},
+				},
+				Name: "roof",
+			},
+		},
+		{
+			name:           "max",
+			solarPanelData: quarterHourly,
+			options:        &ExportOptions{Interval: 15 * time.Minute, Aggregation: ExportAggregationMax, Location: time.UTC},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 3},
+						{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 4},
+						{Timestamp: time.Date(2022, 1, 1, 10, 45, 0, 0, time.UTC), Value: 8},
+					},
+					"uuid2": []domain.Event{},
+				},
+				Name: "roof",
+			},
+		},
+		{
+			name:           "last by timestamp",
+			solarPanelData: quarterHourly,
+			options:        &ExportOptions{Interval: 15 * time.Minute, Aggregation: ExportAggregationLast, Location: time.UTC},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 3},
+						{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 4},
+						{Timestamp: time.Date(2022, 1, 1, 10, 45, 0, 0, time.UTC), Value: 8},
+					},
+					"uuid2": []domain.Event{},
+				},
+				Name: "roof",
+			},
+		},
+		{
+			name:           "count",
+			solarPanelData: quarterHourly,
+			options:        &ExportOptions{Interval: 15 * time.Minute, Aggregation: ExportAggregationCount, Location: time.UTC},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 3},
+						{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 1},
+						{Timestamp: time.Date(2022, 1, 1, 10, 45, 0, 0, time.UTC), Value: 1},
+					},
+					"uuid2": []domain.Event{},
+				},
+				Name: "roof",
+			},
+		},
+		{
+			name:           "zero fill",
+			solarPanelData: quarterHourly,
+			options: &ExportOptions{
+				Interval:    15 * time.Minute,
+				Aggregation: ExportAggregationMean,
+				Location:    time.UTC,
+				Fill:        ExportFillZero,
+			},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 2},
+						{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 4},
+						{Timestamp: time.Date(2022, 1, 1, 10, 30, 0, 0, time.UTC), Value: 0},
+						{Timestamp: time.Date(2022, 1, 1, 10, 45, 0, 0, time.UTC), Value: 8},
+					},
+					"uuid2": []domain.Event{},
+				},
+				Name: "roof",
+			},
+		},
+		{
+			name:           "previous fill",
+			solarPanelData: quarterHourly,
+			options: &ExportOptions{
+				Interval:    15 * time.Minute,
+				Aggregation: ExportAggregationMean,
+				Location:    time.UTC,
+				Fill:        ExportFillPrevious,
+			},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 2},
+						{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 4},
+						{Timestamp: time.Date(2022, 1, 1, 10, 30, 0, 0, time.UTC), Value: 4},
+						{Timestamp: time.Date(2022, 1, 1, 10, 45, 0, 0, time.UTC), Value: 8},
+					},
+					"uuid2": []domain.Event{},
+				},
+				Name: "roof",
+			},
+		},
+		{
+			name:           "linear fill",
+			solarPanelData: quarterHourly,
+			options: &ExportOptions{
+				Interval:    5 * time.Minute,
+				Aggregation: ExportAggregationMean,
+				Location:    time.UTC,
+				Fill:        ExportFillLinear,
+			},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 1},
+						{Timestamp: time.Date(2022, 1, 1, 10, 5, 0, 0, time.UTC), Value: 2},
+						{Timestamp: time.Date(2022, 1, 1, 10, 10, 0, 0, time.UTC), Value: 3},
+						{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 3.5},
+						{Timestamp: time.Date(2022, 1, 1, 10, 20, 0, 0, time.UTC), Value: 4},
+						{Timestamp: time.Date(2022, 1, 1, 10, 25, 0, 0, time.UTC), Value: 4.8},
+						{Timestamp: time.Date(2022, 1, 1, 10, 30, 0, 0, time.UTC), Value: 5.6},
+						{Timestamp: time.Date(2022, 1, 1, 10, 35, 0, 0, time.UTC), Value: 6.4},
+						{Timestamp: time.Date(2022, 1, 1, 10, 40, 0, 0, time.UTC), Value: 7.2},
+						{Timestamp: time.Date(2022, 1, 1, 10, 45, 0, 0, time.UTC), Value: 8},
+					},
+					"uuid2": []domain.Event{},
+				},
+				Name: "roof",
+			},
+		},
+		{
+			name: "linear fill of daily buckets over a daylight saving time change",
+			solarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						// 2022-10-29 00:30 in Athens
+						{Timestamp: time.Date(2022, 10, 28, 21, 30, 0, 0, time.UTC), Value: 1},
+						// 2022-10-31 00:30 in Athens, after a 25 hour day
+						{Timestamp: time.Date(2022, 10, 30, 22, 30, 0, 0, time.UTC), Value: 4},
+					},
+				},
+			},
+			options: &ExportOptions{
+				Interval:    24 * time.Hour,
+				Aggregation: ExportAggregationSum,
+				Location:    athens,
+				Fill:        ExportFillLinear,
+			},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 10, 28, 21, 0, 0, 0, time.UTC), Value: 1},
+						{Timestamp: time.Date(2022, 10, 29, 21, 0, 0, 0, time.UTC), Value: 1 + 3*(24.0/49)},
+						{Timestamp: time.Date(2022, 10, 30, 22, 0, 0, 0, time.UTC), Value: 4},
+					},
+				},
+			},
+		},
+		{
+			name: "daily buckets follow the timezone",
+			solarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						// 2022-01-01 23:30 in Athens
+						{Timestamp: time.Date(2022, 1, 1, 21, 30, 0, 0, time.UTC), Value: 1},
+						// 2022-01-02 00:30 in Athens
+						{Timestamp: time.Date(2022, 1, 1, 22, 30, 0, 0, time.UTC), Value: 2},
+						{Timestamp: time.Date(2022, 1, 2, 12, 0, 0, 0, time.UTC), Value: 3},
+					},
+				},
+				Wind: map[string][]domain.Event{
+					"turbine1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 21, 30, 0, 0, time.UTC), Value: 10},
+					},
+				},
+			},
+			options: &ExportOptions{Interval: 24 * time.Hour, Aggregation: ExportAggregationSum, Location: athens},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 0, 0, 0, time.UTC), Value: 1},
+						{Timestamp: time.Date(2022, 1, 1, 22, 0, 0, 0, time.UTC), Value: 5},
+					},
+				},
+				Wind: map[string][]domain.Event{
+					"turbine1": []domain.Event{
+						{Timestamp: time.Date(2021, 12, 31, 22, 0, 0, 0, time.UTC), Value: 10},
+					},
+				},
+			},
+		},
+		{
+			name: "daily buckets over a daylight saving time change",
+			solarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						// 2022-03-27 00:30 in Athens, on a 23 hour day
+						{Timestamp: time.Date(2022, 3, 26, 22, 30, 0, 0, time.UTC), Value: 1},
+						// 2022-03-27 23:30 in Athens
+						{Timestamp: time.Date(2022, 3, 27, 20, 30, 0, 0, time.UTC), Value: 2},
+						// 2022-03-28 00:30 in Athens
+						{Timestamp: time.Date(2022, 3, 27, 21, 30, 0, 0, time.UTC), Value: 4},
+					},
+				},
+			},
+			options: &ExportOptions{Interval: 24 * time.Hour, Aggregation: ExportAggregationSum, Location: athens},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 3, 26, 22, 0, 0, 0, time.UTC), Value: 3},
+						{Timestamp: time.Date(2022, 3, 27, 21, 0, 0, 0, time.UTC), Value: 4},
+					},
+				},
+			},
+		},
+		{
+			name: "hourly buckets in a half hour offset timezone",
+			solarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 1},
+						{Timestamp: time.Date(2022, 1, 1, 10, 45, 0, 0, time.UTC), Value: 2},
+					},
+				},
+			},
+			options: &ExportOptions{Interval: time.Hour, Aggregation: ExportAggregationSum, Location: kolkata},
+			expected: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 9, 30, 0, 0, time.UTC), Value: 1},
+						{Timestamp: time.Date(2022, 1, 1, 10, 30, 0, 0, time.UTC), Value: 2},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, _ := aggregateEvents(tt.solarPanelData, tt.options)
+
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestExportedData_PartialBuckets(t *testing.T) {
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 1},
+				{Timestamp: time.Date(2022, 1, 1, 10, 5, 0, 0, time.UTC), Value: 2},
+				{Timestamp: time.Date(2022, 1, 1, 10, 10, 0, 0, time.UTC), Value: 3},
+				{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 4},
+				{Timestamp: time.Date(2022, 1, 1, 10, 20, 0, 0, time.UTC), Value: 5},
+			},
+		},
+	}
+
+	// the window cuts both buckets short, which only aggregate the events
+	// inside it
+	actual, _, err := exportedData(solarPanelData, &ExportOptions{
+		EventSelection: domain.EventSelection{
+			Source: domain.EventSourceSolar,
+			From:   timePointer(time.Date(2022, 1, 1, 10, 5, 0, 0, time.UTC)),
+			To:     timePointer(time.Date(2022, 1, 1, 10, 20, 0, 0, time.UTC)),
+		},
+		Interval:    15 * time.Minute,
+		Aggregation: ExportAggregationCount,
+		Location:    time.UTC,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]domain.Event{
+		"uuid1": []domain.Event{
+			{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 2},
+			{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 1},
+		},
+	}, actual.Solar)
+}
+
+func TestAggregateEvents_FilledEvents(t *testing.T) {
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 1},
+				{Timestamp: time.Date(2022, 1, 1, 10, 45, 0, 0, time.UTC), Value: 4},
+			},
+		},
+		Wind: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 1},
+				{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 2},
+			},
+		},
+	}
+
+	// filled events are told apart by source, as the wind turbine with the same
+	// id had readings in the buckets the solar panel did not
+	_, filled := aggregateEvents(solarPanelData, &ExportOptions{
+		Interval:    15 * time.Minute,
+		Aggregation: ExportAggregationMean,
+		Location:    time.UTC,
+		Fill:        ExportFillLinear,
+	})
+
+	assert.Equal(t, filledEvents{
+		{source: domain.EventSourceSolar, parameterId: "uuid1", start: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC).UnixNano()}: true,
+		{source: domain.EventSourceSolar, parameterId: "uuid1", start: time.Date(2022, 1, 1, 10, 30, 0, 0, time.UTC).UnixNano()}: true,
+	}, filled)
+
+	assert.True(t, filled.contains(domain.EventSourceSolar, "uuid1", domain.Event{
+		Timestamp: time.Date(2022, 1, 1, 12, 15, 0, 0, time.FixedZone("UTC+2", 2*60*60)),
+	}))
+	assert.False(t, filled.contains(domain.EventSourceWind, "uuid1", domain.Event{
+		Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC),
+	}))
+}
@@ -0,0 +1,174 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"sort"
+	"time"
+)
+
+// anomalyMinimumParameters is the number of series that must have a reading at
+// a timestamp for it to be compared, as the median of two readings is just
+// their mean, which both deviate from alike.
+const anomalyMinimumParameters = 3
+
+type SolarPanelDataAnomalyDetectorInterface interface {
+	DetectAnomalies(*domain.SolarPanelData, *AnomalyOptions) ([]ParameterAnomalies, error)
+}
+
+// ParameterAnomalies are the intervals a series read far below the other
+// series of its source.
+type ParameterAnomalies struct {
+	Source      domain.EventSource
+	ParameterId string
+	// ComparedReadings is the number of readings of the series that were
+	// compared with the median.
+	ComparedReadings int
+	Anomalies        []Anomaly
+}
+
+// Anomaly is a run of consecutive compared readings of a series that were all
+// flagged.
+type Anomaly struct {
+	From     time.Time
+	To       time.Time
+	Readings int
+	// Score is the mean shortfall of the readings from the median, as a
+	// fraction of the median, so 0.5 is half of what the other series read.
+	Score float64
+}
+
+type SolarPanelDataAnomalyDetector struct{}
+
+func NewSolarPanelDataAnomalyDetector() *SolarPanelDataAnomalyDetector {
+	return &SolarPanelDataAnomalyDetector{}
+}
+
+// DetectAnomalies compares the readings of each series of the chosen source at
+// every timestamp with the median of the readings of all of them, flagging the
+// ones more than the options threshold below it. Timestamps with readings from
+// less than three series, or a median of 0 or less, as at night, are not
+// compared, and split the flagged intervals they fall in, as does a timestamp a
+// series has no reading at.
+func (detector SolarPanelDataAnomalyDetector) DetectAnomalies(
+	solarPanelData *domain.SolarPanelData,
+	options *AnomalyOptions,
+) ([]ParameterAnomalies, error) {
+	solarPanelData, err := selectEvents(solarPanelData, &options.EventSelection)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Interval > 0 {
+		solarPanelData, _ = aggregateEvents(solarPanelData, &ExportOptions{
+			Interval:    options.Interval,
+			Aggregation: ExportAggregationMean,
+			Location:    time.UTC,
+		})
+	}
+
+	events := solarPanelData.EventsOf(options.Source)
+
+	parameterIds := make([]string, 0, len(events))
+	for parameterId := range events {
+		parameterIds = append(parameterIds, parameterId)
+	}
+	sort.Strings(parameterIds)
+
+	// the reading of each series at each timestamp, the last one when a series
+	// has more than one
+	readings := map[time.Time]map[string]float64{}
+	var timestamps []time.Time
+
+	for _, parameterId := range parameterIds {
+		for _, event := range events[parameterId] {
+			timestamp := event.Timestamp.UTC()
+
+			if _, ok := readings[timestamp]; !ok {
+				readings[timestamp] = map[string]float64{}
+				timestamps = append(timestamps, timestamp)
+			}
+
+			readings[timestamp][parameterId] = event.Value
+		}
+	}
+
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i].Before(timestamps[j])
+	})
+
+	anomalies := make([]ParameterAnomalies, 0, len(parameterIds))
+	for _, parameterId := range parameterIds {
+		anomalies = append(anomalies, ParameterAnomalies{
+			Source:      options.Source,
+			ParameterId: parameterId,
+			Anomalies:   []Anomaly{},
+		})
+	}
+
+	// whether the last anomaly of each series is still open to the next
+	// flagged reading
+	open := make([]bool, len(parameterIds))
+
+	for _, timestamp := range timestamps {
+		values := make([]float64, 0, len(readings[timestamp]))
+		for _, value := range readings[timestamp] {
+			values = append(values, value)
+		}
+
+		median := medianValue(values)
+		compared := len(values) >= anomalyMinimumParameters && median > 0
+
+		for index, parameterId := range parameterIds {
+			value, ok := readings[timestamp][parameterId]
+			if !compared || !ok {
+				open[index] = false
+				continue
+			}
+
+			anomalies[index].ComparedReadings++
+
+			shortfall := (median - value) / median
+			if shortfall <= options.Threshold {
+				open[index] = false
+				continue
+			}
+
+			if !open[index] {
+				anomalies[index].Anomalies = append(anomalies[index].Anomalies, Anomaly{From: timestamp})
+				open[index] = true
+			}
+
+			// summed up here and averaged once the anomaly is complete
+			anomaly := &anomalies[index].Anomalies[len(anomalies[index].Anomalies)-1]
+			anomaly.To = timestamp
+			anomaly.Readings++
+			anomaly.Score += shortfall
+		}
+	}
+
+	for index := range anomalies {
+		for anomalyIndex := range anomalies[index].Anomalies {
+			anomaly := &anomalies[index].Anomalies[anomalyIndex]
+			anomaly.Score /= float64(anomaly.Readings)
+		}
+	}
+
+	return anomalies, nil
+}
+
+func medianValue(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
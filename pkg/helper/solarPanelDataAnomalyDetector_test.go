@@ -0,0 +1,153 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSolarPanelDataAnomalyDetector_DetectAnomalies(t *testing.T) {
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Value: 0},
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 100},
+				{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 100},
+				{Timestamp: time.Date(2022, 1, 1, 10, 30, 0, 0, time.UTC), Value: 100},
+				{Timestamp: time.Date(2022, 1, 1, 10, 45, 0, 0, time.UTC), Value: 100},
+				{Timestamp: time.Date(2022, 1, 1, 11, 0, 0, 0, time.UTC), Value: 100},
+			},
+			"uuid2": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Value: 0},
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 100},
+				{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 100},
+				{Timestamp: time.Date(2022, 1, 1, 10, 30, 0, 0, time.UTC), Value: 100},
+				{Timestamp: time.Date(2022, 1, 1, 10, 45, 0, 0, time.UTC), Value: 100},
+				{Timestamp: time.Date(2022, 1, 1, 11, 0, 0, 0, time.UTC), Value: 100},
+			},
+			"uuid3": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Value: 0},
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 100},
+				{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: 40},
+				{Timestamp: time.Date(2022, 1, 1, 10, 30, 0, 0, time.UTC), Value: 50},
+				{Timestamp: time.Date(2022, 1, 1, 11, 0, 0, 0, time.UTC), Value: 80},
+			},
+		},
+		Wind: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Value: 12.5},
+			},
+			"uuid2": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Value: 1},
+			},
+		},
+	}
+
+	soiledAnomaly := Anomaly{
+		From:     time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC),
+		To:       time.Date(2022, 1, 1, 10, 30, 0, 0, time.UTC),
+		Readings: 2,
+		Score:    (0.6 + 0.5) / 2,
+	}
+
+	tests := []struct {
+		name                 string
+		options              *AnomalyOptions
+		expected             []ParameterAnomalies
+		expectError          bool
+		expectedErrorMessage string
+	}{
+		{
+			name:    "solar",
+			options: &AnomalyOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Threshold: 0.3},
+			expected: []ParameterAnomalies{
+				{Source: domain.EventSourceSolar, ParameterId: "uuid1", ComparedReadings: 4, Anomalies: []Anomaly{}},
+				{Source: domain.EventSourceSolar, ParameterId: "uuid2", ComparedReadings: 4, Anomalies: []Anomaly{}},
+				{Source: domain.EventSourceSolar, ParameterId: "uuid3", ComparedReadings: 4, Anomalies: []Anomaly{soiledAnomaly}},
+			},
+		},
+		{
+			name:    "lower threshold",
+			options: &AnomalyOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Threshold: 0.1},
+			expected: []ParameterAnomalies{
+				{Source: domain.EventSourceSolar, ParameterId: "uuid1", ComparedReadings: 4, Anomalies: []Anomaly{}},
+				{Source: domain.EventSourceSolar, ParameterId: "uuid2", ComparedReadings: 4, Anomalies: []Anomaly{}},
+				{
+					Source:           domain.EventSourceSolar,
+					ParameterId:      "uuid3",
+					ComparedReadings: 4,
+					Anomalies: []Anomaly{
+						soiledAnomaly,
+						{
+							From:     time.Date(2022, 1, 1, 11, 0, 0, 0, time.UTC),
+							To:       time.Date(2022, 1, 1, 11, 0, 0, 0, time.UTC),
+							Readings: 1,
+							Score:    0.2,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "hourly means",
+			options: &AnomalyOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Interval: time.Hour, Threshold: 0.3},
+			expected: []ParameterAnomalies{
+				{Source: domain.EventSourceSolar, ParameterId: "uuid1", ComparedReadings: 2, Anomalies: []Anomaly{}},
+				{Source: domain.EventSourceSolar, ParameterId: "uuid2", ComparedReadings: 2, Anomalies: []Anomaly{}},
+				{
+					Source:           domain.EventSourceSolar,
+					ParameterId:      "uuid3",
+					ComparedReadings: 2,
+					Anomalies: []Anomaly{
+						{
+							From:     time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC),
+							To:       time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC),
+							Readings: 1,
+							Score:    (100 - 190.0/3) / 100,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "too few series to compare",
+			options: &AnomalyOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar, Parameters: []string{"uuid1", "uuid3"}}, Threshold: 0.3},
+			expected: []ParameterAnomalies{
+				{Source: domain.EventSourceSolar, ParameterId: "uuid1", Anomalies: []Anomaly{}},
+				{Source: domain.EventSourceSolar, ParameterId: "uuid3", Anomalies: []Anomaly{}},
+			},
+		},
+		{
+			name:    "wind",
+			options: &AnomalyOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceWind}, Threshold: 0.3},
+			expected: []ParameterAnomalies{
+				{Source: domain.EventSourceWind, ParameterId: "uuid1", Anomalies: []Anomaly{}},
+				{Source: domain.EventSourceWind, ParameterId: "uuid2", Anomalies: []Anomaly{}},
+			},
+		},
+		{
+			name:                 "unknown parameters",
+			options:              &AnomalyOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceWind, Parameters: []string{"uuid3"}}, Threshold: 0.3},
+			expectError:          true,
+			expectedErrorMessage: "unknown parameters uuid3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewSolarPanelDataAnomalyDetector()
+
+			actual, err := detector.DetectAnomalies(solarPanelData, tt.options)
+
+			if tt.expectError {
+				assert.EqualError(t, err, tt.expectedErrorMessage)
+				assert.Nil(t, actual)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
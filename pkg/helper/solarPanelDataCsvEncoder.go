@@ -0,0 +1,37 @@
+package helper
+
+import (
+	"encoding/csv"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"io"
+)
+
+// SolarPanelDataCsvEncoder writes the events as laid out by the event
+// extractor.
+type SolarPanelDataCsvEncoder struct {
+	SolarPanelDataEventExtractor SolarPanelDataEventExtractorInterface
+}
+
+func NewSolarPanelDataCsvEncoder(extractor SolarPanelDataEventExtractorInterface) *SolarPanelDataCsvEncoder {
+	return &SolarPanelDataCsvEncoder{
+		SolarPanelDataEventExtractor: extractor,
+	}
+}
+
+func (encoder *SolarPanelDataCsvEncoder) EncodeSolarPanelData(
+	w io.Writer,
+	solarPanelData *domain.SolarPanelData,
+	options *ExportOptions,
+) error {
+	rows, err := encoder.SolarPanelDataEventExtractor.ExtractEventsPerParameterIdToCsvForm(solarPanelData, options)
+	if err != nil {
+		return err
+	}
+
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// EncodeError writes the message as the only cell of the csv.
+func (encoder *SolarPanelDataCsvEncoder) EncodeError(w io.Writer, message string) error {
+	return csv.NewWriter(w).WriteAll([][]string{{message}})
+}
@@ -0,0 +1,45 @@
+package helper
+
+import (
+	"bytes"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSolarPanelDataCsvEncoder_EncodeSolarPanelData(t *testing.T) {
+	encoder := NewSolarPanelDataCsvEncoder(NewSolarPanelDataEventExtractor())
+	actual := &bytes.Buffer{}
+
+	err := encoder.EncodeSolarPanelData(
+		actual,
+		&domain.SolarPanelData{
+			Solar: map[string][]domain.Event{
+				"uuid1": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1.5},
+				},
+			},
+		},
+		&ExportOptions{
+			EventSelection: domain.EventSelection{
+				Source: domain.EventSourceSolar,
+			},
+			Layout:  ExportLayoutLong,
+			Columns: []string{ExportColumnParameterId, ExportColumnValue},
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "parameterId,value\nuuid1,1.5\n", actual.String())
+}
+
+func TestSolarPanelDataCsvEncoder_EncodeError(t *testing.T) {
+	encoder := NewSolarPanelDataCsvEncoder(NewSolarPanelDataEventExtractor())
+	actual := &bytes.Buffer{}
+
+	err := encoder.EncodeError(actual, "missing solarPanelData id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "missing solarPanelData id\n", actual.String())
+}
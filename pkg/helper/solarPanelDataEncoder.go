@@ -0,0 +1,148 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	MediaTypeCsv    = "text/csv"
+	MediaTypeJson   = "application/json"
+	MediaTypeNdjson = "application/x-ndjson"
+)
+
+// SolarPanelDataEncoderInterface writes solar panel data in a single media type.
+// Errors are written by the encoder as well, so that the client always receives
+// the media type it asked for.
+type SolarPanelDataEncoderInterface interface {
+	EncodeSolarPanelData(io.Writer, *domain.SolarPanelData, *ExportOptions) error
+	EncodeError(io.Writer, string) error
+}
+
+// SolarPanelDataEncoders holds the encoders a solar panel data export can be
+// requested in, keyed by their media type.
+type SolarPanelDataEncoders struct {
+	encoders   map[string]SolarPanelDataEncoderInterface
+	formats    map[string]string
+	mediaTypes []string
+}
+
+func NewSolarPanelDataEncoders() *SolarPanelDataEncoders {
+	return &SolarPanelDataEncoders{
+		encoders: map[string]SolarPanelDataEncoderInterface{},
+		formats:  map[string]string{},
+	}
+}
+
+// Register adds an encoder for a media type, which can also be asked for by its
+// format name. The first encoder registered is the default one.
+func (encoders *SolarPanelDataEncoders) Register(
+	format string,
+	mediaType string,
+	encoder SolarPanelDataEncoderInterface,
+) {
+	if _, ok := encoders.encoders[mediaType]; !ok {
+		encoders.mediaTypes = append(encoders.mediaTypes, mediaType)
+	}
+
+	encoders.encoders[mediaType] = encoder
+	encoders.formats[format] = mediaType
+}
+
+// Negotiate picks the encoder for a request. A format name overrides the Accept
+// header, which is otherwise honoured by quality and then by order. No Accept
+// header at all falls back to the default encoder.
+func (encoders *SolarPanelDataEncoders) Negotiate(
+	accept string,
+	format string,
+) (string, SolarPanelDataEncoderInterface, error) {
+	if format != "" {
+		mediaType, ok := encoders.formats[format]
+		if !ok {
+			return "", nil, encoders.notAcceptableError()
+		}
+
+		return mediaType, encoders.encoders[mediaType], nil
+	}
+
+	if strings.TrimSpace(accept) == "" && len(encoders.mediaTypes) > 0 {
+		return encoders.mediaTypes[0], encoders.encoders[encoders.mediaTypes[0]], nil
+	}
+
+	for _, mediaRange := range parseAccept(accept) {
+		for _, mediaType := range encoders.mediaTypes {
+			if matchesMediaRange(mediaRange, mediaType) {
+				return mediaType, encoders.encoders[mediaType], nil
+			}
+		}
+	}
+
+	return "", nil, encoders.notAcceptableError()
+}
+
+func (encoders *SolarPanelDataEncoders) notAcceptableError() error {
+	return apierrors.NotAcceptableError{
+		ReturnedStatusCode:  http.StatusNotAcceptable,
+		SupportedMediaTypes: encoders.mediaTypes,
+	}
+}
+
+// parseAccept returns the acceptable media ranges of an Accept header, the most
+// preferred first. Ranges that cannot be parsed or have a zero quality are left
+// out.
+func parseAccept(accept string) []string {
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+
+	var mediaRanges []mediaRange
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		if quality <= 0 {
+			continue
+		}
+
+		mediaRanges = append(mediaRanges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(mediaRanges, func(i, j int) bool {
+		return mediaRanges[i].quality > mediaRanges[j].quality
+	})
+
+	result := make([]string, 0, len(mediaRanges))
+	for _, mediaRange := range mediaRanges {
+		result = append(result, mediaRange.mediaType)
+	}
+
+	return result
+}
+
+func matchesMediaRange(mediaRange string, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+
+	rangeType, rangeSubtype, ok := strings.Cut(mediaRange, "/")
+
+	return ok && rangeSubtype == "*" && strings.HasPrefix(mediaType, rangeType+"/")
+}
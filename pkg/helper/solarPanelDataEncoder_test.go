@@ -0,0 +1,117 @@
+package helper
+
+import (
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestSolarPanelDataEncoders_Negotiate(t *testing.T) {
+	csvEncoder := NewSolarPanelDataCsvEncoder(NewSolarPanelDataEventExtractor())
+	jsonEncoder := NewSolarPanelDataJsonEncoder()
+	ndjsonEncoder := NewSolarPanelDataNdjsonEncoder()
+
+	encoders := NewSolarPanelDataEncoders()
+	encoders.Register("csv", MediaTypeCsv, csvEncoder)
+	encoders.Register("json", MediaTypeJson, jsonEncoder)
+	encoders.Register("ndjson", MediaTypeNdjson, ndjsonEncoder)
+
+	notAcceptableError := apierrors.NotAcceptableError{
+		ReturnedStatusCode:  http.StatusNotAcceptable,
+		SupportedMediaTypes: []string{MediaTypeCsv, MediaTypeJson, MediaTypeNdjson},
+	}
+
+	tests := []struct {
+		name              string
+		accept            string
+		format            string
+		expectedMediaType string
+		expectedEncoder   SolarPanelDataEncoderInterface
+		expectError       bool
+		expectedError     error
+	}{
+		{
+			name:              "valid default without accept",
+			expectedMediaType: MediaTypeCsv,
+			expectedEncoder:   csvEncoder,
+		},
+		{
+			name:              "valid exact accept",
+			accept:            "application/json",
+			expectedMediaType: MediaTypeJson,
+			expectedEncoder:   jsonEncoder,
+		},
+		{
+			name:              "valid accept with parameters",
+			accept:            "application/x-ndjson; charset=utf-8",
+			expectedMediaType: MediaTypeNdjson,
+			expectedEncoder:   ndjsonEncoder,
+		},
+		{
+			name:              "valid accept by quality",
+			accept:            "text/csv;q=0.5, application/json;q=0.9",
+			expectedMediaType: MediaTypeJson,
+			expectedEncoder:   jsonEncoder,
+		},
+		{
+			name:              "valid accept by order on equal quality",
+			accept:            "application/x-ndjson, application/json",
+			expectedMediaType: MediaTypeNdjson,
+			expectedEncoder:   ndjsonEncoder,
+		},
+		{
+			name:              "valid subtype wildcard",
+			accept:            "application/*",
+			expectedMediaType: MediaTypeJson,
+			expectedEncoder:   jsonEncoder,
+		},
+		{
+			name:              "valid full wildcard",
+			accept:            "*/*",
+			expectedMediaType: MediaTypeCsv,
+			expectedEncoder:   csvEncoder,
+		},
+		{
+			name:              "valid format over accept",
+			accept:            "application/json",
+			format:            "csv",
+			expectedMediaType: MediaTypeCsv,
+			expectedEncoder:   csvEncoder,
+		},
+		{
+			name:          "invalid accept",
+			accept:        "application/xml",
+			expectError:   true,
+			expectedError: notAcceptableError,
+		},
+		{
+			name:          "invalid accept with zero quality",
+			accept:        "text/csv;q=0",
+			expectError:   true,
+			expectedError: notAcceptableError,
+		},
+		{
+			name:          "invalid format",
+			format:        "xml",
+			expectError:   true,
+			expectedError: notAcceptableError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actualMediaType, actualEncoder, actualError := encoders.Negotiate(tt.accept, tt.format)
+			if (actualError != nil) != tt.expectError {
+				t.Errorf("Negotiate() error = %v, expectError %v", actualError, tt.expectError)
+				return
+			}
+
+			assert.Equal(t, tt.expectedMediaType, actualMediaType)
+			assert.Equal(t, tt.expectedEncoder, actualEncoder)
+
+			if tt.expectError {
+				assert.Equal(t, tt.expectedError, actualError)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"sort"
+	"time"
+)
+
+const energyDateLayout = "2006-01-02"
+
+type SolarPanelDataEnergyCalculatorInterface interface {
+	CalculateEnergy(*domain.SolarPanelData, *EnergyOptions) (*SolarPanelDataEnergy, error)
+}
+
+// SolarPanelDataEnergy is the energy in kWh the power series of a dataset add
+// up to, per day and per parameter id.
+type SolarPanelDataEnergy struct {
+	Parameters []ParameterEnergy
+	// Days adds up the energy of every parameter id per day.
+	Days  []DailyEnergy
+	Total float64
+}
+
+type ParameterEnergy struct {
+	Source      domain.EventSource
+	ParameterId string
+	Days        []DailyEnergy
+	Total       float64
+	// SkippedGaps is the number of intervals between readings left out for
+	// being longer than the options MaxGap.
+	SkippedGaps int
+}
+
+type DailyEnergy struct {
+	// Date is the day in the options timezone, in the 2006-01-02 format.
+	Date   string
+	Energy float64
+}
+
+type SolarPanelDataEnergyCalculator struct{}
+
+func NewSolarPanelDataEnergyCalculator() *SolarPanelDataEnergyCalculator {
+	return &SolarPanelDataEnergyCalculator{}
+}
+
+// CalculateEnergy integrates the power readings of each series with the
+// trapezoidal rule, taking the power to change linearly between two readings.
+// An interval that crosses midnight is split at it, so that each day gets the
+// energy produced in it. A series needs at least two readings to add up to any
+// energy, and days it has no interval in are left out.
+func (calculator SolarPanelDataEnergyCalculator) CalculateEnergy(
+	solarPanelData *domain.SolarPanelData,
+	options *EnergyOptions,
+) (*SolarPanelDataEnergy, error) {
+	solarPanelData, err := selectEvents(solarPanelData, &options.EventSelection)
+	if err != nil {
+		return nil, err
+	}
+
+	energy := &SolarPanelDataEnergy{
+		Parameters: []ParameterEnergy{},
+		Days:       []DailyEnergy{},
+	}
+	totalDailyEnergy := map[string]float64{}
+
+	for _, source := range options.Source.Sources() {
+		events := solarPanelData.EventsOf(source)
+
+		parameterIds := make([]string, 0, len(events))
+		for parameterId := range events {
+			parameterIds = append(parameterIds, parameterId)
+		}
+		sort.Strings(parameterIds)
+
+		for _, parameterId := range parameterIds {
+			dailyEnergy, skippedGaps := integrateSeries(events[parameterId], options)
+
+			parameterEnergy := ParameterEnergy{
+				Source:      source,
+				ParameterId: parameterId,
+				Days:        sortedDailyEnergy(dailyEnergy),
+				SkippedGaps: skippedGaps,
+			}
+
+			for date, dayEnergy := range dailyEnergy {
+				parameterEnergy.Total += dayEnergy
+				totalDailyEnergy[date] += dayEnergy
+			}
+
+			energy.Parameters = append(energy.Parameters, parameterEnergy)
+			energy.Total += parameterEnergy.Total
+		}
+	}
+
+	energy.Days = sortedDailyEnergy(totalDailyEnergy)
+
+	return energy, nil
+}
+
+// integrateSeries returns the energy in kWh of a power series per day, along
+// with the number of intervals it skipped.
+func integrateSeries(events []domain.Event, options *EnergyOptions) (map[string]float64, int) {
+	sortedEvents := make([]domain.Event, len(events))
+	copy(sortedEvents, events)
+	sort.SliceStable(sortedEvents, func(i, j int) bool {
+		return sortedEvents[i].Timestamp.Before(sortedEvents[j].Timestamp)
+	})
+
+	dailyEnergy := map[string]float64{}
+	skippedGaps := 0
+	toKilowatts := kilowattsPer(options.Unit)
+
+	for index := 1; index < len(sortedEvents); index++ {
+		previous, current := sortedEvents[index-1], sortedEvents[index]
+
+		// readings at the same timestamp span no time, so they add no energy
+		if !previous.Timestamp.Before(current.Timestamp) {
+			continue
+		}
+
+		if options.Gaps == EnergyGapsSkip && current.Timestamp.Sub(previous.Timestamp) > options.MaxGap {
+			skippedGaps++
+			continue
+		}
+
+		start, startValue := previous.Timestamp.In(options.Location), previous.Value
+
+		for {
+			year, month, day := start.Date()
+			midnight := time.Date(year, month, day+1, 0, 0, 0, 0, options.Location)
+
+			if !midnight.Before(current.Timestamp) {
+				break
+			}
+
+			midnightValue := interpolateValue(previous, current, midnight)
+			dailyEnergy[start.Format(energyDateLayout)] += trapezoid(start, startValue, midnight, midnightValue) * toKilowatts
+
+			start, startValue = midnight, midnightValue
+		}
+
+		dailyEnergy[start.Format(energyDateLayout)] += trapezoid(start, startValue, current.Timestamp, current.Value) * toKilowatts
+	}
+
+	return dailyEnergy, skippedGaps
+}
+
+// trapezoid returns the area under a straight line between two readings, in
+// unit hours.
+func trapezoid(start time.Time, startValue float64, end time.Time, endValue float64) float64 {
+	return (startValue + endValue) / 2 * end.Sub(start).Hours()
+}
+
+// interpolateValue returns the value the line between two readings has at a
+// time between them.
+func interpolateValue(previous domain.Event, current domain.Event, at time.Time) float64 {
+	fraction := float64(at.Sub(previous.Timestamp)) / float64(current.Timestamp.Sub(previous.Timestamp))
+
+	return previous.Value + (current.Value-previous.Value)*fraction
+}
+
+func kilowattsPer(unit string) float64 {
+	switch unit {
+	case EnergyUnitKilowatt:
+		return 1
+	case EnergyUnitMegawatt:
+		return 1000
+	default:
+		return 0.001
+	}
+}
+
+func sortedDailyEnergy(dailyEnergy map[string]float64) []DailyEnergy {
+	days := make([]DailyEnergy, 0, len(dailyEnergy))
+	for date, energy := range dailyEnergy {
+		days = append(days, DailyEnergy{Date: date, Energy: energy})
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date < days[j].Date
+	})
+
+	return days
+}
@@ -0,0 +1,287 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSolarPanelDataEnergyCalculator_CalculateEnergy(t *testing.T) {
+	athens, err := time.LoadLocation("Europe/Athens")
+	assert.NoError(t, err)
+
+	from := time.Date(2022, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	irregular := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 13, 0, 0, 0, time.UTC), Value: 1000},
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 1000},
+				{Timestamp: time.Date(2022, 1, 1, 11, 0, 0, 0, time.UTC), Value: 3000},
+			},
+			"uuid2": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 500},
+			},
+		},
+		Wind: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 2000},
+				{Timestamp: time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC), Value: 2000},
+			},
+		},
+	}
+
+	overMidnight := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 21, 0, 0, 0, time.UTC), Value: 2},
+				{Timestamp: time.Date(2022, 1, 1, 23, 0, 0, 0, time.UTC), Value: 4},
+			},
+		},
+	}
+
+	withGap := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 1},
+				{Timestamp: time.Date(2022, 1, 1, 10, 30, 0, 0, time.UTC), Value: 1},
+				{Timestamp: time.Date(2022, 1, 1, 14, 0, 0, 0, time.UTC), Value: 1},
+				{Timestamp: time.Date(2022, 1, 1, 14, 30, 0, 0, time.UTC), Value: 1},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                 string
+		solarPanelData       *domain.SolarPanelData
+		options              *EnergyOptions
+		expected             *SolarPanelDataEnergy
+		expectError          bool
+		expectedErrorMessage string
+	}{
+		{
+			name:           "watts of unordered readings",
+			solarPanelData: irregular,
+			options: &EnergyOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Unit:     EnergyUnitWatt,
+				Gaps:     EnergyGapsInterpolate,
+				Location: time.UTC,
+			},
+			expected: &SolarPanelDataEnergy{
+				Parameters: []ParameterEnergy{
+					{
+						Source:      domain.EventSourceSolar,
+						ParameterId: "uuid1",
+						Days:        []DailyEnergy{{Date: "2022-01-01", Energy: 6}},
+						Total:       6,
+					},
+					{
+						Source:      domain.EventSourceSolar,
+						ParameterId: "uuid2",
+						Days:        []DailyEnergy{},
+					},
+				},
+				Days:  []DailyEnergy{{Date: "2022-01-01", Energy: 6}},
+				Total: 6,
+			},
+		},
+		{
+			name:           "both sources in a time window",
+			solarPanelData: irregular,
+			options: &EnergyOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceAll,
+					From:       &from,
+					Parameters: []string{"uuid1"},
+				},
+				Unit:     EnergyUnitWatt,
+				Gaps:     EnergyGapsInterpolate,
+				Location: time.UTC,
+			},
+			expected: &SolarPanelDataEnergy{
+				Parameters: []ParameterEnergy{
+					{
+						Source:      domain.EventSourceSolar,
+						ParameterId: "uuid1",
+						Days:        []DailyEnergy{{Date: "2022-01-01", Energy: 4}},
+						Total:       4,
+					},
+					{
+						Source:      domain.EventSourceWind,
+						ParameterId: "uuid1",
+						Days:        []DailyEnergy{},
+					},
+				},
+				Days:  []DailyEnergy{{Date: "2022-01-01", Energy: 4}},
+				Total: 4,
+			},
+		},
+		{
+			name:           "megawatts",
+			solarPanelData: irregular,
+			options: &EnergyOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceWind,
+				},
+				Unit:     EnergyUnitMegawatt,
+				Gaps:     EnergyGapsInterpolate,
+				Location: time.UTC,
+			},
+			expected: &SolarPanelDataEnergy{
+				Parameters: []ParameterEnergy{
+					{
+						Source:      domain.EventSourceWind,
+						ParameterId: "uuid1",
+						Days:        []DailyEnergy{{Date: "2022-01-01", Energy: 4000000}},
+						Total:       4000000,
+					},
+				},
+				Days:  []DailyEnergy{{Date: "2022-01-01", Energy: 4000000}},
+				Total: 4000000,
+			},
+		},
+		{
+			name:           "interval within a day in utc",
+			solarPanelData: overMidnight,
+			options: &EnergyOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Unit:     EnergyUnitKilowatt,
+				Gaps:     EnergyGapsInterpolate,
+				Location: time.UTC,
+			},
+			expected: &SolarPanelDataEnergy{
+				Parameters: []ParameterEnergy{
+					{
+						Source:      domain.EventSourceSolar,
+						ParameterId: "uuid1",
+						Days:        []DailyEnergy{{Date: "2022-01-01", Energy: 6}},
+						Total:       6,
+					},
+				},
+				Days:  []DailyEnergy{{Date: "2022-01-01", Energy: 6}},
+				Total: 6,
+			},
+		},
+		{
+			name:           "interval split at midnight in athens",
+			solarPanelData: overMidnight,
+			options: &EnergyOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Unit:     EnergyUnitKilowatt,
+				Gaps:     EnergyGapsInterpolate,
+				Location: athens,
+			},
+			expected: &SolarPanelDataEnergy{
+				Parameters: []ParameterEnergy{
+					{
+						Source:      domain.EventSourceSolar,
+						ParameterId: "uuid1",
+						Days: []DailyEnergy{
+							{Date: "2022-01-01", Energy: 2.5},
+							{Date: "2022-01-02", Energy: 3.5},
+						},
+						Total: 6,
+					},
+				},
+				Days: []DailyEnergy{
+					{Date: "2022-01-01", Energy: 2.5},
+					{Date: "2022-01-02", Energy: 3.5},
+				},
+				Total: 6,
+			},
+		},
+		{
+			name:           "interpolated gap",
+			solarPanelData: withGap,
+			options: &EnergyOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Unit:     EnergyUnitKilowatt,
+				Gaps:     EnergyGapsInterpolate,
+				Location: time.UTC,
+			},
+			expected: &SolarPanelDataEnergy{
+				Parameters: []ParameterEnergy{
+					{
+						Source:      domain.EventSourceSolar,
+						ParameterId: "uuid1",
+						Days:        []DailyEnergy{{Date: "2022-01-01", Energy: 4.5}},
+						Total:       4.5,
+					},
+				},
+				Days:  []DailyEnergy{{Date: "2022-01-01", Energy: 4.5}},
+				Total: 4.5,
+			},
+		},
+		{
+			name:           "skipped gap",
+			solarPanelData: withGap,
+			options: &EnergyOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Unit:     EnergyUnitKilowatt,
+				Gaps:     EnergyGapsSkip,
+				MaxGap:   time.Hour,
+				Location: time.UTC,
+			},
+			expected: &SolarPanelDataEnergy{
+				Parameters: []ParameterEnergy{
+					{
+						Source:      domain.EventSourceSolar,
+						ParameterId: "uuid1",
+						Days:        []DailyEnergy{{Date: "2022-01-01", Energy: 1}},
+						Total:       1,
+						SkippedGaps: 1,
+					},
+				},
+				Days:  []DailyEnergy{{Date: "2022-01-01", Energy: 1}},
+				Total: 1,
+			},
+		},
+		{
+			name:           "unknown parameters",
+			solarPanelData: withGap,
+			options: &EnergyOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceSolar,
+					Parameters: []string{"uuid2"},
+				},
+				Unit:     EnergyUnitKilowatt,
+				Gaps:     EnergyGapsInterpolate,
+				Location: time.UTC,
+			},
+			expectError:          true,
+			expectedErrorMessage: "unknown parameters uuid2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := NewSolarPanelDataEnergyCalculator()
+
+			actual, err := calculator.CalculateEnergy(tt.solarPanelData, tt.options)
+
+			if tt.expectError {
+				assert.EqualError(t, err, tt.expectedErrorMessage)
+				assert.Equal(t, http.StatusNotFound, err.(apierrors.UnknownParametersError).ReturnedStatusCode)
+				assert.Nil(t, actual)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -1,14 +1,14 @@
 package helper
 
 import (
-	"errors"
 	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
-	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
-	"net/http"
+	"sort"
+	"strconv"
+	"time"
 )
 
 type SolarPanelDataEventExtractorInterface interface {
-	ExtractEventsPerParameterIdToCsvForm(*domain.SolarPanelData) ([][]string, error)
+	ExtractEventsPerParameterIdToCsvForm(*domain.SolarPanelData, *ExportOptions) ([][]string, error)
 }
 
 type SolarPanelDataEventExtractor struct{}
@@ -17,50 +17,237 @@ func NewSolarPanelDataEventExtractor() *SolarPanelDataEventExtractor {
 	return &SolarPanelDataEventExtractor{}
 }
 
+// sourcedEvent is an event along with the series it belongs to.
+type sourcedEvent struct {
+	source      domain.EventSource
+	parameterId string
+	event       domain.Event
+	// filled is set on the events filled in for a bucket without events.
+	filled bool
+}
+
 // ExtractEventsPerParameterId creates a 2-dimensional array of string that holds
-// an event string in every row of the array. It's meant to be used for creating a
-// CSV
+// a header row followed by the events of the chosen source, laid out and ordered
+// as the options ask. It's meant to be used for creating a CSV. Events are
+// validated when they are submitted, so there is nothing left to reject here.
 func (extractor SolarPanelDataEventExtractor) ExtractEventsPerParameterIdToCsvForm(
 	solarPanelData *domain.SolarPanelData,
+	options *ExportOptions,
 ) ([][]string, error) {
-	const EventArrayElementNormalSize = 2
+	solarPanelData, filled, err := exportedData(solarPanelData, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Layout == ExportLayoutWide {
+		return extractWideForm(solarPanelData, filled, options), nil
+	}
+
+	events := sortedEvents(solarPanelData, filled, options)
+
+	if options.Layout == ExportLayoutLong {
+		return extractLongForm(events, options), nil
+	}
+
+	return extractEventsForm(events, options), nil
+}
+
+// sortedEvents flattens the series of the chosen source into a single list in
+// the order of the options, flagging the filled events. Events are collected
+// from maps, so they are always sorted to keep the output the same from request
+// to request.
+func sortedEvents(
+	solarPanelData *domain.SolarPanelData,
+	filled filledEvents,
+	options *ExportOptions,
+) []sourcedEvent {
+	var events []sourcedEvent
+
+	for _, source := range options.Source.Sources() {
+		for parameterId, parameterIdEvents := range solarPanelData.EventsOf(source) {
+			for _, event := range parameterIdEvents {
+				events = append(events, sourcedEvent{
+					source:      source,
+					parameterId: parameterId,
+					event:       event,
+					filled:      filled.contains(source, parameterId, event),
+				})
+			}
+		}
+	}
+
+	sourceOrder := map[domain.EventSource]int{}
+	for order, source := range options.Source.Sources() {
+		sourceOrder[source] = order
+	}
+
+	// stable, so that events of a series with the same timestamp keep the order
+	// they were submitted in
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+
+		if options.Sort == ExportSortTimestamp && !a.event.Timestamp.Equal(b.event.Timestamp) {
+			return a.event.Timestamp.Before(b.event.Timestamp)
+		}
+
+		if a.source != b.source {
+			return sourceOrder[a.source] < sourceOrder[b.source]
+		}
+
+		if a.parameterId != b.parameterId {
+			return a.parameterId < b.parameterId
+		}
+
+		return a.event.Timestamp.Before(b.event.Timestamp)
+	})
 
+	return events
+}
+
+// extractEventsForm writes the event values, followed by whether each was
+// filled in when the options fill the buckets without events.
+func extractEventsForm(events []sourcedEvent, options *ExportOptions) [][]string {
 	var SolarPanelDataEvents [][]string
-	var eventValue string
 
 	csvHeaderRow := []string{"Events"}
+	if fillsBuckets(options) {
+		csvHeaderRow = append(csvHeaderRow, "Filled")
+	}
 
 	SolarPanelDataEvents = append(
 		SolarPanelDataEvents,
 		csvHeaderRow,
 	)
 
-	for parameterId, parameterIdEvents := range solarPanelData.Solar {
-		for _, event := range parameterIdEvents {
-			if len(event) < EventArrayElementNormalSize {
-				return [][]string{}, apierrors.MalformedEventDataError{
-					ReturnedStatusCode:   http.StatusInternalServerError,
-					MalformedParameterId: parameterId,
-					OriginalError:        errors.New("parameterId " + parameterId + " contains events with no values"),
-				}
+	for _, event := range events {
+		row := []string{event.event.FormatValue()}
+		if fillsBuckets(options) {
+			row = append(row, strconv.FormatBool(event.filled))
+		}
+
+		SolarPanelDataEvents = append(
+			SolarPanelDataEvents,
+			row,
+		)
+	}
+
+	return SolarPanelDataEvents
+}
+
+// extractLongForm writes one row per event, with the columns in the order the
+// options list them.
+func extractLongForm(events []sourcedEvent, options *ExportOptions) [][]string {
+	SolarPanelDataEvents := [][]string{options.Columns}
+
+	for _, event := range events {
+		row := make([]string, 0, len(options.Columns))
+
+		for _, column := range options.Columns {
+			switch column {
+			case ExportColumnSource:
+				row = append(row, string(event.source))
+			case ExportColumnParameterId:
+				row = append(row, event.parameterId)
+			case ExportColumnTimestamp:
+				row = append(row, event.event.FormatTimestamp())
+			case ExportColumnValue:
+				row = append(row, event.event.FormatValue())
+			case ExportColumnFilled:
+				row = append(row, strconv.FormatBool(event.filled))
 			}
+		}
 
-			eventValue = event[1]
+		SolarPanelDataEvents = append(SolarPanelDataEvents, row)
+	}
+
+	return SolarPanelDataEvents
+}
+
+// extractWideForm pivots the series on a shared time index, writing one row per
+// timestamp any series has an event at and one column per parameter id, left
+// blank where the series has no event at that timestamp. Rows are always in
+// timestamp order and columns in parameter id order, whatever the sort.
+// Parameter ids are prefixed with their source when both sources are exported,
+// as a solar panel and a wind turbine may share an id. When the options fill
+// the buckets without events, each parameter id column is followed by a
+// "<parameter id>:filled" column flagging the values that were filled in.
+func extractWideForm(
+	solarPanelData *domain.SolarPanelData,
+	filled filledEvents,
+	options *ExportOptions,
+) [][]string {
+	header := []string{ExportColumnTimestamp}
 
-			if eventValue == "" {
-				return [][]string{}, apierrors.MalformedEventDataError{
-					ReturnedStatusCode:   http.StatusInternalServerError,
-					MalformedParameterId: parameterId,
-					OriginalError:        errors.New("parameterId " + parameterId + " contains events with no values"),
-				}
+	type series struct {
+		source      domain.EventSource
+		parameterId string
+	}
+
+	var columns []series
+
+	for _, source := range options.Source.Sources() {
+		events := solarPanelData.EventsOf(source)
+
+		parameterIds := make([]string, 0, len(events))
+		for parameterId := range events {
+			parameterIds = append(parameterIds, parameterId)
+		}
+		sort.Strings(parameterIds)
+
+		for _, parameterId := range parameterIds {
+			columns = append(columns, series{source: source, parameterId: parameterId})
+
+			column := parameterId
+			if options.Source == domain.EventSourceAll {
+				column = string(source) + ":" + parameterId
 			}
 
-			SolarPanelDataEvents = append(
-				SolarPanelDataEvents,
-				[]string{eventValue},
-			)
+			header = append(header, column)
+
+			if fillsBuckets(options) {
+				header = append(header, column+":"+ExportColumnFilled)
+			}
+		}
+	}
+
+	// the number of header columns each series takes
+	columnsPerSeries := 1
+	if fillsBuckets(options) {
+		columnsPerSeries = 2
+	}
+
+	rows := map[time.Time][]string{}
+	var timestamps []time.Time
+
+	for column, series := range columns {
+		for _, event := range solarPanelData.EventsOf(series.source)[series.parameterId] {
+			timestamp := event.Timestamp.UTC()
+
+			row, ok := rows[timestamp]
+			if !ok {
+				row = make([]string, len(header))
+				row[0] = event.FormatTimestamp()
+				rows[timestamp] = row
+				timestamps = append(timestamps, timestamp)
+			}
+
+			row[column*columnsPerSeries+1] = event.FormatValue()
+
+			if fillsBuckets(options) {
+				row[column*columnsPerSeries+2] = strconv.FormatBool(filled.contains(series.source, series.parameterId, event))
+			}
 		}
 	}
 
-	return SolarPanelDataEvents, nil
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i].Before(timestamps[j])
+	})
+
+	SolarPanelDataEvents := [][]string{header}
+
+	for _, timestamp := range timestamps {
+		SolarPanelDataEvents = append(SolarPanelDataEvents, rows[timestamp])
+	}
+
+	return SolarPanelDataEvents
 }
@@ -1,17 +1,16 @@
 package helper
 
 import (
-	"errors"
 	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
-	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
 	"github.com/stretchr/testify/assert"
-	"net/http"
 	"testing"
+	"time"
 )
 
 func TestSolarPanelDataEventExtractor_ExtractEventsPerParameterIdToCsvForm(t *testing.T) {
 	type args struct {
 		solarPanelData *domain.SolarPanelData
+		options        *ExportOptions
 	}
 	tests := []struct {
 		name          string
@@ -24,17 +23,18 @@ func TestSolarPanelDataEventExtractor_ExtractEventsPerParameterIdToCsvForm(t *te
 			name: "valid one parameter one event",
 			args: args{
 				solarPanelData: &domain.SolarPanelData{
-					Solar: map[string][][]string{
-						"uuid1": [][]string{
-							{"timestamp1", "event1"},
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 						},
 					},
 					Wind: nil,
 				},
+				options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Layout: ExportLayoutEvents},
 			},
 			expected: [][]string{
 				{"Events"},
-				{"event1"},
+				{"1"},
 			},
 			expectError: false,
 		},
@@ -42,23 +42,24 @@ func TestSolarPanelDataEventExtractor_ExtractEventsPerParameterIdToCsvForm(t *te
 			name: "valid one parameter multiple events",
 			args: args{
 				solarPanelData: &domain.SolarPanelData{
-					Solar: map[string][][]string{
-						"uuid1": [][]string{
-							{"timestamp1", "event1"},
-							{"timestamp2", "event2"},
-							{"timestamp3", "event3"},
-							{"timestamp4", "event4"},
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+							{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 3},
+							{Timestamp: time.Date(2022, 1, 1, 4, 0, 0, 0, time.UTC), Value: 4},
 						},
 					},
 					Wind: nil,
 				},
+				options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Layout: ExportLayoutEvents},
 			},
 			expected: [][]string{
 				{"Events"},
-				{"event1"},
-				{"event2"},
-				{"event3"},
-				{"event4"},
+				{"1"},
+				{"2"},
+				{"3"},
+				{"4"},
 			},
 			expectError: false,
 		},
@@ -66,29 +67,30 @@ func TestSolarPanelDataEventExtractor_ExtractEventsPerParameterIdToCsvForm(t *te
 			name: "valid multiple parameter one event",
 			args: args{
 				solarPanelData: &domain.SolarPanelData{
-					Solar: map[string][][]string{
-						"uuid1": [][]string{
-							{"timestamp1", "event1"},
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 						},
-						"uuid2": [][]string{
-							{"timestamp1", "event1"},
+						"uuid2": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 						},
-						"uuid3": [][]string{
-							{"timestamp1", "event1"},
+						"uuid3": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 						},
-						"uuid4": [][]string{
-							{"timestamp1", "event1"},
+						"uuid4": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
 						},
 					},
 					Wind: nil,
 				},
+				options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Layout: ExportLayoutEvents},
 			},
 			expected: [][]string{
 				{"Events"},
-				{"event1"},
-				{"event1"},
-				{"event1"},
-				{"event1"},
+				{"1"},
+				{"1"},
+				{"1"},
+				{"1"},
 			},
 			expectError: false,
 		},
@@ -96,110 +98,388 @@ func TestSolarPanelDataEventExtractor_ExtractEventsPerParameterIdToCsvForm(t *te
 			name: "valid multiple parameters multiple events",
 			args: args{
 				solarPanelData: &domain.SolarPanelData{
-					Solar: map[string][][]string{
-						"uuid1": [][]string{
-							{"timestamp1", "event1"},
-							{"timestamp2", "event2"},
-							{"timestamp3", "event3"},
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+							{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 3},
 						},
-						"uuid2": [][]string{
-							{"timestamp1", "event1"},
-							{"timestamp2", "event2"},
-							{"timestamp3", "event3"},
+						"uuid2": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+							{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 3},
 						},
-						"uuid3": [][]string{
-							{"timestamp1", "event1"},
-							{"timestamp2", "event2"},
-							{"timestamp3", "event3"},
+						"uuid3": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+							{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 3},
 						},
 					},
 					Wind: nil,
 				},
+				options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Layout: ExportLayoutEvents},
 			},
 			expected: [][]string{
 				{"Events"},
-				{"event1"},
-				{"event2"},
-				{"event3"},
-				{"event1"},
-				{"event2"},
-				{"event3"},
-				{"event1"},
-				{"event2"},
-				{"event3"},
+				{"1"},
+				{"2"},
+				{"3"},
+				{"1"},
+				{"2"},
+				{"3"},
+				{"1"},
+				{"2"},
+				{"3"},
 			},
 			expectError: false,
 		},
 		{
-			name: "invalid missing event",
+			name: "valid fractional and zero values",
 			args: args{
 				solarPanelData: &domain.SolarPanelData{
-					Solar: map[string][][]string{
-						"uuid1": [][]string{
-							{"timestamp1", "event1"},
-							{"timestamp2", "event2"},
-							{"timestamp3", "event3"},
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 81.9354839},
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 0},
+							{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: -1.5},
 						},
-						"uuid2": [][]string{
-							{"timestamp1", "event1"},
-							{"timestamp2"},
-							{"timestamp3", "event3"},
+					},
+					Wind: nil,
+				},
+				options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Layout: ExportLayoutEvents},
+			},
+			expected: [][]string{
+				{"Events"},
+				{"81.9354839"},
+				{"0"},
+				{"-1.5"},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid wind source",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+						},
+					},
+					Wind: map[string][]domain.Event{
+						"turbine1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 12.5},
+						},
+					},
+				},
+				options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceWind}, Layout: ExportLayoutEvents},
+			},
+			expected: [][]string{
+				{"Events"},
+				{"12.5"},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid all sources solar first",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+						},
+					},
+					Wind: map[string][]domain.Event{
+						"turbine1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 12.5},
 						},
-						"uuid3": [][]string{
-							{"timestamp1", "event1"},
-							{"timestamp2", "event2"},
-							{"timestamp3", "event3"},
+					},
+				},
+				options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceAll}, Layout: ExportLayoutEvents},
+			},
+			expected: [][]string{
+				{"Events"},
+				{"1"},
+				{"12.5"},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid wind source without wind",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+						},
+					},
+					Wind: nil,
+				},
+				options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceWind}, Layout: ExportLayoutEvents},
+			},
+			expected: [][]string{
+				{"Events"},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid long layout",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+							{Timestamp: time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC), Value: 81.9354839},
 						},
 					},
 					Wind: nil,
 				},
+				options: &ExportOptions{
+					EventSelection: domain.EventSelection{
+						Source: domain.EventSourceSolar,
+					},
+					Layout:  ExportLayoutLong,
+					Columns: []string{ExportColumnParameterId, ExportColumnTimestamp, ExportColumnValue},
+				},
 			},
-			expected:    [][]string{},
-			expectError: true,
-			expectedError: apierrors.MalformedEventDataError{
-				ReturnedStatusCode:   http.StatusInternalServerError,
-				MalformedParameterId: "uuid2",
-				OriginalError:        errors.New("parameterId uuid2 contains events with no values"),
+			expected: [][]string{
+				{"parameterId", "timestamp", "value"},
+				{"uuid1", "20211231T221500Z", "0"},
+				{"uuid1", "20220101T060000Z", "81.9354839"},
 			},
+			expectError: false,
 		},
 		{
-			name: "invalid empty string event",
+			name: "valid long layout with chosen columns",
 			args: args{
 				solarPanelData: &domain.SolarPanelData{
-					Solar: map[string][][]string{
-						"uuid1": [][]string{
-							{"timestamp1", "event1"},
-							{"timestamp2", "event2"},
-							{"timestamp3", "event3"},
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
 						},
-						"uuid2": [][]string{
-							{"timestamp1", "event1"},
-							{"timestamp2", ""},
-							{"timestamp3", "event3"},
+					},
+					Wind: map[string][]domain.Event{
+						"turbine1": []domain.Event{
+							{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 12.5},
+						},
+					},
+				},
+				options: &ExportOptions{
+					EventSelection: domain.EventSelection{
+						Source: domain.EventSourceAll,
+					},
+					Layout:  ExportLayoutLong,
+					Columns: []string{ExportColumnValue, ExportColumnSource, ExportColumnParameterId},
+				},
+			},
+			expected: [][]string{
+				{"value", "source", "parameterId"},
+				{"0", "solar", "uuid1"},
+				{"12.5", "wind", "turbine1"},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid long layout with time window",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2021, 12, 31, 22, 15, 0, 0, time.UTC), Value: 0},
+							{Timestamp: time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC), Value: 81.9354839},
+							{Timestamp: time.Date(2022, 1, 1, 7, 0, 0, 0, time.UTC), Value: 90},
+						},
+					},
+					Wind: nil,
+				},
+				options: &ExportOptions{
+					EventSelection: domain.EventSelection{
+						Source: domain.EventSourceSolar,
+						From:   timePointer(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)),
+						To:     timePointer(time.Date(2022, 1, 1, 7, 0, 0, 0, time.UTC)),
+					},
+					Layout:  ExportLayoutLong,
+					Columns: []string{ExportColumnTimestamp, ExportColumnValue},
+				},
+			},
+			expected: [][]string{
+				{"timestamp", "value"},
+				{"20220101T060000Z", "81.9354839"},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid wide layout",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid2": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 22},
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 21},
 						},
-						"uuid3": [][]string{
-							{"timestamp1", "event1"},
-							{"timestamp2", "event2"},
-							{"timestamp3", "event3"},
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 11},
+							{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 13},
 						},
+						"uuid3": []domain.Event{},
 					},
 					Wind: nil,
 				},
+				options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Layout: ExportLayoutWide},
+			},
+			expected: [][]string{
+				{"timestamp", "uuid1", "uuid2", "uuid3"},
+				{"20220101T010000Z", "11", "21", ""},
+				{"20220101T020000Z", "", "22", ""},
+				{"20220101T030000Z", "13", "", ""},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid wide layout with all sources",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 0},
+						},
+					},
+					Wind: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 12.5},
+						},
+					},
+				},
+				options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceAll}, Layout: ExportLayoutWide},
+			},
+			expected: [][]string{
+				{"timestamp", "solar:uuid1", "wind:uuid1"},
+				{"20220101T010000Z", "0", ""},
+				{"20220101T020000Z", "", "12.5"},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid events layout with fill",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 11},
+							{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 13},
+						},
+						"uuid2": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 22},
+						},
+					},
+				},
+				options: &ExportOptions{
+					EventSelection: domain.EventSelection{
+						Source: domain.EventSourceSolar,
+					},
+					Layout:      ExportLayoutEvents,
+					Interval:    time.Hour,
+					Aggregation: ExportAggregationMean,
+					Location:    time.UTC,
+					Fill:        ExportFillPrevious,
+				},
+			},
+			expected: [][]string{
+				{"Events", "Filled"},
+				{"11", "false"},
+				{"11", "true"},
+				{"13", "false"},
+				{"22", "false"},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid long layout with fill",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 11},
+							{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 13},
+						},
+						"uuid2": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 22},
+						},
+					},
+				},
+				options: &ExportOptions{
+					EventSelection: domain.EventSelection{
+						Source: domain.EventSourceSolar,
+					},
+					Layout:      ExportLayoutLong,
+					Columns:     []string{ExportColumnParameterId, ExportColumnTimestamp, ExportColumnValue, ExportColumnFilled},
+					Interval:    time.Hour,
+					Aggregation: ExportAggregationMean,
+					Location:    time.UTC,
+					Fill:        ExportFillPrevious,
+				},
 			},
-			expected:    [][]string{},
-			expectError: true,
-			expectedError: apierrors.MalformedEventDataError{
-				ReturnedStatusCode:   http.StatusInternalServerError,
-				MalformedParameterId: "uuid2",
-				OriginalError:        errors.New("parameterId uuid2 contains events with no values"),
+			expected: [][]string{
+				{"parameterId", "timestamp", "value", "filled"},
+				{"uuid1", "20220101T010000Z", "11", "false"},
+				{"uuid1", "20220101T020000Z", "11", "true"},
+				{"uuid1", "20220101T030000Z", "13", "false"},
+				{"uuid2", "20220101T020000Z", "22", "false"},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid wide layout with fill",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{
+						"uuid1": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 11},
+							{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 13},
+						},
+						"uuid2": []domain.Event{
+							{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 22},
+						},
+					},
+				},
+				options: &ExportOptions{
+					EventSelection: domain.EventSelection{
+						Source: domain.EventSourceSolar,
+					},
+					Layout:      ExportLayoutWide,
+					Interval:    time.Hour,
+					Aggregation: ExportAggregationMean,
+					Location:    time.UTC,
+					Fill:        ExportFillPrevious,
+				},
 			},
+			expected: [][]string{
+				{"timestamp", "uuid1", "uuid1:filled", "uuid2", "uuid2:filled"},
+				{"20220101T010000Z", "11", "false", "", ""},
+				{"20220101T020000Z", "11", "true", "22", "false"},
+				{"20220101T030000Z", "13", "false", "", ""},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid wide layout without events",
+			args: args{
+				solarPanelData: &domain.SolarPanelData{
+					Solar: map[string][]domain.Event{},
+					Wind:  nil,
+				},
+				options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Layout: ExportLayoutWide},
+			},
+			expected: [][]string{
+				{"timestamp"},
+			},
+			expectError: false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			extractor := SolarPanelDataEventExtractor{}
-			actual, actualError := extractor.ExtractEventsPerParameterIdToCsvForm(tt.args.solarPanelData)
+			actual, actualError := extractor.ExtractEventsPerParameterIdToCsvForm(tt.args.solarPanelData, tt.args.options)
 			if (actualError != nil) != tt.expectError {
 				t.Errorf("ExtractEventsPerParameterIdToCsvForm() error = %v, expectedError %v", actualError, tt.expectedError)
 				return
@@ -212,3 +492,126 @@ func TestSolarPanelDataEventExtractor_ExtractEventsPerParameterIdToCsvForm(t *te
 		})
 	}
 }
+
+func TestSolarPanelDataEventExtractor_ExtractEventsPerParameterIdToCsvFormOrdering(t *testing.T) {
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid3": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 32},
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 31},
+			},
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 13},
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 11},
+			},
+			"uuid2": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 22},
+			},
+		},
+		Wind: map[string][]domain.Event{
+			"turbine1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		options  *ExportOptions
+		expected [][]string
+	}{
+		{
+			name:    "parameter id first",
+			options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Layout: ExportLayoutEvents, Sort: ExportSortParameterId},
+			expected: [][]string{
+				{"Events"},
+				{"11"},
+				{"13"},
+				{"22"},
+				{"31"},
+				{"32"},
+			},
+		},
+		{
+			name:    "timestamp first",
+			options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Layout: ExportLayoutEvents, Sort: ExportSortTimestamp},
+			expected: [][]string{
+				{"Events"},
+				{"11"},
+				{"31"},
+				{"22"},
+				{"32"},
+				{"13"},
+			},
+		},
+		{
+			name: "parameter id first for all sources",
+			options: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceAll,
+				},
+				Layout:  ExportLayoutLong,
+				Columns: []string{ExportColumnSource, ExportColumnParameterId, ExportColumnTimestamp},
+				Sort:    ExportSortParameterId,
+			},
+			expected: [][]string{
+				{"source", "parameterId", "timestamp"},
+				{"solar", "uuid1", "20220101T010000Z"},
+				{"solar", "uuid1", "20220101T030000Z"},
+				{"solar", "uuid2", "20220101T020000Z"},
+				{"solar", "uuid3", "20220101T010000Z"},
+				{"solar", "uuid3", "20220101T020000Z"},
+				{"wind", "turbine1", "20220101T010000Z"},
+			},
+		},
+		{
+			name: "timestamp first for all sources",
+			options: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceAll,
+				},
+				Layout:  ExportLayoutLong,
+				Columns: []string{ExportColumnSource, ExportColumnParameterId, ExportColumnTimestamp},
+				Sort:    ExportSortTimestamp,
+			},
+			expected: [][]string{
+				{"source", "parameterId", "timestamp"},
+				{"solar", "uuid1", "20220101T010000Z"},
+				{"solar", "uuid3", "20220101T010000Z"},
+				{"wind", "turbine1", "20220101T010000Z"},
+				{"solar", "uuid2", "20220101T020000Z"},
+				{"solar", "uuid3", "20220101T020000Z"},
+				{"solar", "uuid1", "20220101T030000Z"},
+			},
+		},
+		{
+			name:    "wide layout",
+			options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceAll}, Layout: ExportLayoutWide, Sort: ExportSortTimestamp},
+			expected: [][]string{
+				{"timestamp", "solar:uuid1", "solar:uuid2", "solar:uuid3", "wind:turbine1"},
+				{"20220101T010000Z", "11", "", "31", "1"},
+				{"20220101T020000Z", "", "22", "32", ""},
+				{"20220101T030000Z", "13", "", "", ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extractor := SolarPanelDataEventExtractor{}
+
+			// map iteration order changes between runs, so a single call could
+			// pass by chance
+			for i := 0; i < 50; i++ {
+				actual, actualError := extractor.ExtractEventsPerParameterIdToCsvForm(solarPanelData, tt.options)
+
+				assert.NoError(t, actualError)
+				assert.EqualValues(t, tt.expected, actual)
+			}
+		})
+	}
+}
+
+func timePointer(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,69 @@
+package helper
+
+import (
+	"bufio"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MediaTypeInfluxLineProtocol is what InfluxDB accepts line protocol writes as.
+const MediaTypeInfluxLineProtocol = "text/plain"
+
+// SolarPanelDataInfluxEncoder writes one InfluxDB line protocol point per
+// event, measured by its source and tagged with its parameter id, in the source
+// and order of the export options:
+//
+//	solar,parameter=38d503e5-dc1c-4549-8172-09d9c29070f7 value=81.9354839 1640995200000000000
+//
+// The events filled in for the buckets without events have a filled=true field
+// as well.
+type SolarPanelDataInfluxEncoder struct{}
+
+func NewSolarPanelDataInfluxEncoder() *SolarPanelDataInfluxEncoder {
+	return &SolarPanelDataInfluxEncoder{}
+}
+
+func (encoder *SolarPanelDataInfluxEncoder) EncodeSolarPanelData(
+	w io.Writer,
+	solarPanelData *domain.SolarPanelData,
+	options *ExportOptions,
+) error {
+	solarPanelData, filled, err := exportedData(solarPanelData, options)
+	if err != nil {
+		return err
+	}
+
+	bufferedWriter := bufio.NewWriter(w)
+
+	measurementEscaper := strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper := strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+	for _, event := range sortedEvents(solarPanelData, filled, options) {
+		fields := "value=" + event.event.FormatValue()
+		if event.filled {
+			fields += ",filled=true"
+		}
+
+		_, err := bufferedWriter.WriteString(
+			measurementEscaper.Replace(string(event.source)) +
+				",parameter=" + tagEscaper.Replace(event.parameterId) +
+				" " + fields +
+				" " + strconv.FormatInt(event.event.Timestamp.UnixNano(), 10) + "\n",
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return bufferedWriter.Flush()
+}
+
+// EncodeError writes the message as plain text, as line protocol has no way of
+// carrying one.
+func (encoder *SolarPanelDataInfluxEncoder) EncodeError(w io.Writer, message string) error {
+	_, err := io.WriteString(w, message+"\n")
+
+	return err
+}
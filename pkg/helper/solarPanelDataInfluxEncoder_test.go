@@ -0,0 +1,85 @@
+package helper
+
+import (
+	"bytes"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSolarPanelDataInfluxEncoder_EncodeSolarPanelData(t *testing.T) {
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 81.9354839},
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 0},
+			},
+		},
+		Wind: map[string][]domain.Event{
+			"turbine 1,a=b": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: -12.5},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		options  *ExportOptions
+		expected string
+	}{
+		{
+			name:    "valid solar",
+			options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Sort: ExportSortParameterId},
+			expected: `solar,parameter=38d503e5-dc1c-4549-8172-09d9c29070f7 value=0 1640998800000000000
+solar,parameter=38d503e5-dc1c-4549-8172-09d9c29070f7 value=81.9354839 1641002400000000000
+`,
+		},
+		{
+			name:    "valid all sources with escaped tag",
+			options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceAll}, Sort: ExportSortTimestamp},
+			expected: `solar,parameter=38d503e5-dc1c-4549-8172-09d9c29070f7 value=0 1640998800000000000
+wind,parameter=turbine\ 1\,a\=b value=-12.5 1640998800000000000
+solar,parameter=38d503e5-dc1c-4549-8172-09d9c29070f7 value=81.9354839 1641002400000000000
+`,
+		},
+		{
+			name: "valid solar with fill",
+			options: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Sort:        ExportSortParameterId,
+				Interval:    30 * time.Minute,
+				Aggregation: ExportAggregationMean,
+				Location:    time.UTC,
+				Fill:        ExportFillPrevious,
+			},
+			expected: `solar,parameter=38d503e5-dc1c-4549-8172-09d9c29070f7 value=0 1640998800000000000
+solar,parameter=38d503e5-dc1c-4549-8172-09d9c29070f7 value=0,filled=true 1641000600000000000
+solar,parameter=38d503e5-dc1c-4549-8172-09d9c29070f7 value=81.9354839 1641002400000000000
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := SolarPanelDataInfluxEncoder{}
+			actual := &bytes.Buffer{}
+
+			err := encoder.EncodeSolarPanelData(actual, solarPanelData, tt.options)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual.String())
+		})
+	}
+}
+
+func TestSolarPanelDataInfluxEncoder_EncodeError(t *testing.T) {
+	encoder := SolarPanelDataInfluxEncoder{}
+	actual := &bytes.Buffer{}
+
+	err := encoder.EncodeError(actual, "missing solarPanelData id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "missing solarPanelData id\n", actual.String())
+}
@@ -0,0 +1,138 @@
+package helper
+
+import (
+	"encoding/json"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"io"
+)
+
+// solarPanelDataDocument has the shape of a submitted solar panel data request,
+// so that an export can be submitted again as it is. Solar is left empty rather
+// than out when only wind is exported, as it is required on submission, and
+// Filled is ignored on submission.
+type solarPanelDataDocument struct {
+	Solar  map[string][][]string `json:"solar"`
+	Wind   map[string][][]string `json:"wind,omitempty"`
+	Name   string                `json:"name,omitempty"`
+	Site   string                `json:"site,omitempty"`
+	Tags   []string              `json:"tags,omitempty"`
+	Filled *filledDocument       `json:"filled,omitempty"`
+}
+
+// filledDocument lists the timestamps of the events that were filled in for
+// the buckets without events, per series, as the [timestamp, value] pairs have
+// no room for a flag.
+type filledDocument struct {
+	Solar map[string][]string `json:"solar"`
+	Wind  map[string][]string `json:"wind,omitempty"`
+}
+
+type jsonError struct {
+	ErrorMessage string `json:"errorMessage"`
+}
+
+// SolarPanelDataJsonEncoder writes the solar panel data document with the
+// exported events of the chosen source, aggregated and filled as the options
+// ask. Layout, columns and sort are about laying out rows, which the document
+// has none of, so they do not apply to it.
+type SolarPanelDataJsonEncoder struct{}
+
+func NewSolarPanelDataJsonEncoder() *SolarPanelDataJsonEncoder {
+	return &SolarPanelDataJsonEncoder{}
+}
+
+func (encoder *SolarPanelDataJsonEncoder) EncodeSolarPanelData(
+	w io.Writer,
+	solarPanelData *domain.SolarPanelData,
+	options *ExportOptions,
+) error {
+	solarPanelData, filled, err := exportedData(solarPanelData, options)
+	if err != nil {
+		return err
+	}
+
+	document := solarPanelDataDocument{
+		Solar: map[string][][]string{},
+		Name:  solarPanelData.Name,
+		Site:  solarPanelData.Site,
+		Tags:  solarPanelData.Tags,
+	}
+
+	fills := fillsBuckets(options)
+	if fills {
+		document.Filled = &filledDocument{
+			Solar: map[string][]string{},
+		}
+	}
+
+	for _, source := range options.Source.Sources() {
+		events := solarPanelData.EventsOf(source)
+		if events == nil {
+			continue
+		}
+
+		switch source {
+		case domain.EventSourceSolar:
+			document.Solar = eventPairs(events)
+			if fills {
+				document.Filled.Solar = filledTimestamps(source, events, filled)
+			}
+		case domain.EventSourceWind:
+			document.Wind = eventPairs(events)
+			if fills {
+				document.Filled.Wind = filledTimestamps(source, events, filled)
+			}
+		}
+	}
+
+	return json.NewEncoder(w).Encode(document)
+}
+
+func (encoder *SolarPanelDataJsonEncoder) EncodeError(w io.Writer, message string) error {
+	return json.NewEncoder(w).Encode(jsonError{ErrorMessage: message})
+}
+
+// eventPairs turns the events back into the [timestamp, value] pairs they were
+// submitted as.
+func eventPairs(events map[string][]domain.Event) map[string][][]string {
+	if events == nil {
+		return nil
+	}
+
+	pairs := make(map[string][][]string, len(events))
+
+	for parameterId, parameterIdEvents := range events {
+		pairs[parameterId] = make([][]string, 0, len(parameterIdEvents))
+
+		for _, event := range parameterIdEvents {
+			pairs[parameterId] = append(pairs[parameterId], event.Pair())
+		}
+	}
+
+	return pairs
+}
+
+// filledTimestamps lists the timestamps of the filled events of each series.
+func filledTimestamps(
+	source domain.EventSource,
+	events map[string][]domain.Event,
+	filled filledEvents,
+) map[string][]string {
+	if events == nil {
+		return nil
+	}
+
+	timestamps := make(map[string][]string, len(events))
+
+	for parameterId, parameterIdEvents := range events {
+		timestamps[parameterId] = []string{}
+
+		for _, event := range parameterIdEvents {
+			if filled.contains(source, parameterId, event) {
+				timestamps[parameterId] = append(timestamps[parameterId], event.FormatTimestamp())
+			}
+		}
+	}
+
+	return timestamps
+}
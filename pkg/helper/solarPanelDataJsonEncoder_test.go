@@ -0,0 +1,136 @@
+package helper
+
+import (
+	"bytes"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSolarPanelDataJsonEncoder_EncodeSolarPanelData(t *testing.T) {
+	from := time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		solarPanelData *domain.SolarPanelData
+		options        *ExportOptions
+		expected       string
+	}{
+		{
+			name: "valid solar only",
+			solarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid2": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 81.9354839},
+					},
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 0},
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: -1.5},
+					},
+				},
+			},
+			options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}},
+			expected: `{"solar":{"uuid1":[["20220101T020000Z","0"],["20220101T010000Z","-1.5"]],"uuid2":[["20220101T020000Z","81.9354839"]]}}
+`,
+		},
+		{
+			name: "valid with time window",
+			solarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 0},
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: -1.5},
+					},
+				},
+			},
+			options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar, From: &from}},
+			expected: `{"solar":{"uuid1":[["20220101T020000Z","0"]]}}
+`,
+		},
+		{
+			name: "valid with wind and metadata",
+			solarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{},
+				},
+				Wind: map[string][]domain.Event{
+					"turbine1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 12.5},
+					},
+				},
+				Name:    "roof",
+				Site:    "athens",
+				Tags:    []string{"south"},
+				Version: 2,
+			},
+			options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceAll}},
+			expected: `{"solar":{"uuid1":[]},"wind":{"turbine1":[["20220101T010000Z","12.5"]]},"name":"roof","site":"athens","tags":["south"]}
+`,
+		},
+		{
+			name: "valid wind only",
+			solarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+					},
+				},
+				Wind: map[string][]domain.Event{
+					"turbine1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 12.5},
+					},
+				},
+			},
+			options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceWind}},
+			expected: `{"solar":{},"wind":{"turbine1":[["20220101T010000Z","12.5"]]}}
+`,
+		},
+		{
+			name: "valid with fill",
+			solarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+						{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 3},
+					},
+					"uuid2": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 81.9354839},
+					},
+				},
+			},
+			options: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Interval:    time.Hour,
+				Aggregation: ExportAggregationMean,
+				Location:    time.UTC,
+				Fill:        ExportFillLinear,
+			},
+			expected: `{"solar":{"uuid1":[["20220101T010000Z","1"],["20220101T020000Z","2"],["20220101T030000Z","3"]],"uuid2":[["20220101T010000Z","81.9354839"]]},"filled":{"solar":{"uuid1":["20220101T020000Z"],"uuid2":[]}}}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := SolarPanelDataJsonEncoder{}
+			actual := &bytes.Buffer{}
+
+			err := encoder.EncodeSolarPanelData(actual, tt.solarPanelData, tt.options)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual.String())
+		})
+	}
+}
+
+func TestSolarPanelDataJsonEncoder_EncodeError(t *testing.T) {
+	encoder := SolarPanelDataJsonEncoder{}
+	actual := &bytes.Buffer{}
+
+	err := encoder.EncodeError(actual, "missing solarPanelData id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"errorMessage\":\"missing solarPanelData id\"}\n", actual.String())
+}
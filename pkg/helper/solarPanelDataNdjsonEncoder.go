@@ -0,0 +1,63 @@
+package helper
+
+import (
+	"encoding/json"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"io"
+)
+
+// ndjsonEvent is a line of the export. Filled is only set when the export
+// fills the buckets without events.
+type ndjsonEvent struct {
+	Source      domain.EventSource `json:"source"`
+	ParameterId string             `json:"parameterId"`
+	Timestamp   string             `json:"timestamp"`
+	Value       float64            `json:"value"`
+	Filled      *bool              `json:"filled,omitempty"`
+}
+
+// SolarPanelDataNdjsonEncoder writes one JSON object per event and line, in the
+// source and order of the export options.
+type SolarPanelDataNdjsonEncoder struct{}
+
+func NewSolarPanelDataNdjsonEncoder() *SolarPanelDataNdjsonEncoder {
+	return &SolarPanelDataNdjsonEncoder{}
+}
+
+func (encoder *SolarPanelDataNdjsonEncoder) EncodeSolarPanelData(
+	w io.Writer,
+	solarPanelData *domain.SolarPanelData,
+	options *ExportOptions,
+) error {
+	solarPanelData, filled, err := exportedData(solarPanelData, options)
+	if err != nil {
+		return err
+	}
+
+	jsonEncoder := json.NewEncoder(w)
+
+	for _, event := range sortedEvents(solarPanelData, filled, options) {
+		line := ndjsonEvent{
+			Source:      event.source,
+			ParameterId: event.parameterId,
+			Timestamp:   event.event.FormatTimestamp(),
+			Value:       event.event.Value,
+		}
+
+		if fillsBuckets(options) {
+			filled := event.filled
+			line.Filled = &filled
+		}
+
+		err := jsonEncoder.Encode(line)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (encoder *SolarPanelDataNdjsonEncoder) EncodeError(w io.Writer, message string) error {
+	return json.NewEncoder(w).Encode(jsonError{ErrorMessage: message})
+}
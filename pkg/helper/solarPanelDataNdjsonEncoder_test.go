@@ -0,0 +1,94 @@
+package helper
+
+import (
+	"bytes"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSolarPanelDataNdjsonEncoder_EncodeSolarPanelData(t *testing.T) {
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid2": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 81.9354839},
+			},
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 0},
+			},
+		},
+		Wind: map[string][]domain.Event{
+			"turbine1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 12.5},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		solarPanelData *domain.SolarPanelData
+		options        *ExportOptions
+		expected       string
+	}{
+		{
+			name:           "valid solar by parameter id",
+			solarPanelData: solarPanelData,
+			options:        &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Sort: ExportSortParameterId},
+			expected: `{"source":"solar","parameterId":"uuid1","timestamp":"20220101T020000Z","value":0}
+{"source":"solar","parameterId":"uuid2","timestamp":"20220101T010000Z","value":81.9354839}
+`,
+		},
+		{
+			name:           "valid all sources by timestamp",
+			solarPanelData: solarPanelData,
+			options:        &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceAll}, Sort: ExportSortTimestamp},
+			expected: `{"source":"solar","parameterId":"uuid2","timestamp":"20220101T010000Z","value":81.9354839}
+{"source":"wind","parameterId":"turbine1","timestamp":"20220101T010000Z","value":12.5}
+{"source":"solar","parameterId":"uuid1","timestamp":"20220101T020000Z","value":0}
+`,
+		},
+		{
+			name:           "valid without events",
+			solarPanelData: &domain.SolarPanelData{Solar: map[string][]domain.Event{}},
+			options:        &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceWind}, Sort: ExportSortParameterId},
+			expected:       ``,
+		},
+		{
+			name: "valid with fill",
+			solarPanelData: &domain.SolarPanelData{
+				Solar: map[string][]domain.Event{
+					"uuid1": []domain.Event{
+						{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 81.9354839},
+						{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 4},
+					},
+				},
+			},
+			options: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Sort:        ExportSortParameterId,
+				Interval:    30 * time.Minute,
+				Aggregation: ExportAggregationMean,
+				Location:    time.UTC,
+				Fill:        ExportFillZero,
+			},
+			expected: `{"source":"solar","parameterId":"uuid1","timestamp":"20220101T010000Z","value":81.9354839,"filled":false}
+{"source":"solar","parameterId":"uuid1","timestamp":"20220101T013000Z","value":0,"filled":true}
+{"source":"solar","parameterId":"uuid1","timestamp":"20220101T020000Z","value":4,"filled":false}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := SolarPanelDataNdjsonEncoder{}
+			actual := &bytes.Buffer{}
+
+			err := encoder.EncodeSolarPanelData(actual, tt.solarPanelData, tt.options)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual.String())
+		})
+	}
+}
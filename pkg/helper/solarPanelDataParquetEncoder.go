@@ -0,0 +1,100 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/xitongsys/parquet-go/writer"
+	"io"
+)
+
+const MediaTypeParquet = "application/vnd.apache.parquet"
+
+// parquetEvent is a row of the parquet export. The timestamp is stored as
+// milliseconds since the epoch, which is what the TIMESTAMP logical type with
+// a MILLIS unit expects.
+type parquetEvent struct {
+	Source      string  `parquet:"name=source, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ParameterId string  `parquet:"name=parameterId, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Timestamp   int64   `parquet:"name=timestamp, type=INT64, convertedtype=TIMESTAMP_MILLIS, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=true, logicaltype.unit=MILLIS"`
+	Value       float64 `parquet:"name=value, type=DOUBLE"`
+}
+
+// parquetFilledEvent is a row of the parquet export when it fills the buckets
+// without events, flagging the events that were filled in.
+type parquetFilledEvent struct {
+	Source      string  `parquet:"name=source, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ParameterId string  `parquet:"name=parameterId, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Timestamp   int64   `parquet:"name=timestamp, type=INT64, convertedtype=TIMESTAMP_MILLIS, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=true, logicaltype.unit=MILLIS"`
+	Value       float64 `parquet:"name=value, type=DOUBLE"`
+	Filled      bool    `parquet:"name=filled, type=BOOLEAN"`
+}
+
+type parquetError struct {
+	ErrorMessage string `parquet:"name=errorMessage, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// SolarPanelDataParquetEncoder writes one row per event with typed columns, in
+// the source and order of the export options.
+type SolarPanelDataParquetEncoder struct{}
+
+func NewSolarPanelDataParquetEncoder() *SolarPanelDataParquetEncoder {
+	return &SolarPanelDataParquetEncoder{}
+}
+
+func (encoder *SolarPanelDataParquetEncoder) EncodeSolarPanelData(
+	w io.Writer,
+	solarPanelData *domain.SolarPanelData,
+	options *ExportOptions,
+) error {
+	solarPanelData, filled, err := exportedData(solarPanelData, options)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]interface{}, 0)
+
+	if fillsBuckets(options) {
+		for _, event := range sortedEvents(solarPanelData, filled, options) {
+			rows = append(rows, &parquetFilledEvent{
+				Source:      string(event.source),
+				ParameterId: event.parameterId,
+				Timestamp:   event.event.Timestamp.UnixMilli(),
+				Value:       event.event.Value,
+				Filled:      event.filled,
+			})
+		}
+
+		return writeParquet(w, new(parquetFilledEvent), rows)
+	}
+
+	for _, event := range sortedEvents(solarPanelData, filled, options) {
+		rows = append(rows, &parquetEvent{
+			Source:      string(event.source),
+			ParameterId: event.parameterId,
+			Timestamp:   event.event.Timestamp.UnixMilli(),
+			Value:       event.event.Value,
+		})
+	}
+
+	return writeParquet(w, new(parquetEvent), rows)
+}
+
+// EncodeError writes the message as the only row of a parquet file.
+func (encoder *SolarPanelDataParquetEncoder) EncodeError(w io.Writer, message string) error {
+	return writeParquet(w, new(parquetError), []interface{}{&parquetError{ErrorMessage: message}})
+}
+
+func writeParquet(w io.Writer, schema interface{}, rows []interface{}) error {
+	parquetWriter, err := writer.NewParquetWriterFromWriter(w, schema, 1)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		err = parquetWriter.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return parquetWriter.WriteStop()
+}
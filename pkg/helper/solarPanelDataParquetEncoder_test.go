@@ -0,0 +1,129 @@
+package helper
+
+import (
+	"bytes"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"testing"
+	"time"
+)
+
+func TestSolarPanelDataParquetEncoder_EncodeSolarPanelData(t *testing.T) {
+	encoder := NewSolarPanelDataParquetEncoder()
+	actual := &bytes.Buffer{}
+
+	err := encoder.EncodeSolarPanelData(
+		actual,
+		&domain.SolarPanelData{
+			Solar: map[string][]domain.Event{
+				"uuid2": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 81.9354839},
+				},
+				"uuid1": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 0},
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: -1.5},
+				},
+			},
+			Wind: map[string][]domain.Event{
+				"turbine1": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 12.5},
+				},
+			},
+		},
+		&ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceAll}, Sort: ExportSortParameterId},
+	)
+	assert.NoError(t, err)
+
+	file, err := buffer.NewBufferFile(actual.Bytes())
+	assert.NoError(t, err)
+
+	parquetReader, err := reader.NewParquetReader(file, new(parquetEvent), 1)
+	assert.NoError(t, err)
+	defer parquetReader.ReadStop()
+
+	timestampSchema := parquetReader.SchemaHandler.SchemaElements[3]
+	assert.Equal(t, "Timestamp", timestampSchema.GetName())
+	assert.Equal(t, parquet.Type_INT64, timestampSchema.GetType())
+	assert.True(t, timestampSchema.GetLogicalType().GetTIMESTAMP().GetIsAdjustedToUTC())
+	assert.True(t, timestampSchema.GetLogicalType().GetTIMESTAMP().GetUnit().IsSetMILLIS())
+
+	rows := make([]parquetEvent, parquetReader.GetNumRows())
+	err = parquetReader.Read(&rows)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []parquetEvent{
+		{Source: "solar", ParameterId: "uuid1", Timestamp: 1640998800000, Value: -1.5},
+		{Source: "solar", ParameterId: "uuid1", Timestamp: 1641002400000, Value: 0},
+		{Source: "solar", ParameterId: "uuid2", Timestamp: 1640998800000, Value: 81.9354839},
+		{Source: "wind", ParameterId: "turbine1", Timestamp: 1640998800000, Value: 12.5},
+	}, rows)
+}
+
+func TestSolarPanelDataParquetEncoder_EncodeSolarPanelDataWithFill(t *testing.T) {
+	encoder := NewSolarPanelDataParquetEncoder()
+	actual := &bytes.Buffer{}
+
+	err := encoder.EncodeSolarPanelData(
+		actual,
+		&domain.SolarPanelData{
+			Solar: map[string][]domain.Event{
+				"uuid1": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: -1.5},
+					{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 0},
+				},
+			},
+		},
+		&ExportOptions{
+			EventSelection: domain.EventSelection{
+				Source: domain.EventSourceSolar,
+			},
+			Sort:        ExportSortParameterId,
+			Interval:    time.Hour,
+			Aggregation: ExportAggregationMean,
+			Location:    time.UTC,
+			Fill:        ExportFillPrevious,
+		},
+	)
+	assert.NoError(t, err)
+
+	file, err := buffer.NewBufferFile(actual.Bytes())
+	assert.NoError(t, err)
+
+	parquetReader, err := reader.NewParquetReader(file, new(parquetFilledEvent), 1)
+	assert.NoError(t, err)
+	defer parquetReader.ReadStop()
+
+	rows := make([]parquetFilledEvent, parquetReader.GetNumRows())
+	err = parquetReader.Read(&rows)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []parquetFilledEvent{
+		{Source: "solar", ParameterId: "uuid1", Timestamp: 1640998800000, Value: -1.5, Filled: false},
+		{Source: "solar", ParameterId: "uuid1", Timestamp: 1641002400000, Value: -1.5, Filled: true},
+		{Source: "solar", ParameterId: "uuid1", Timestamp: 1641006000000, Value: 0, Filled: false},
+	}, rows)
+}
+
+func TestSolarPanelDataParquetEncoder_EncodeError(t *testing.T) {
+	encoder := NewSolarPanelDataParquetEncoder()
+	actual := &bytes.Buffer{}
+
+	err := encoder.EncodeError(actual, "missing solarPanelData id")
+	assert.NoError(t, err)
+
+	file, err := buffer.NewBufferFile(actual.Bytes())
+	assert.NoError(t, err)
+
+	parquetReader, err := reader.NewParquetReader(file, new(parquetError), 1)
+	assert.NoError(t, err)
+	defer parquetReader.ReadStop()
+
+	rows := make([]parquetError, parquetReader.GetNumRows())
+	err = parquetReader.Read(&rows)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []parquetError{{ErrorMessage: "missing solarPanelData id"}}, rows)
+}
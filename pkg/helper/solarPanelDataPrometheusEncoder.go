@@ -0,0 +1,162 @@
+package helper
+
+import (
+	"encoding/binary"
+	"github.com/golang/snappy"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"io"
+	"math"
+)
+
+const (
+	// MediaTypePrometheusRemoteWrite is what Prometheus accepts remote-write
+	// requests as, with their body compressed by ContentEncodingSnappy.
+	MediaTypePrometheusRemoteWrite = "application/x-protobuf"
+	ContentEncodingSnappy          = "snappy"
+)
+
+// field numbers of the Prometheus remote-write protobuf messages
+const (
+	writeRequestTimeseriesField = 1
+	timeSeriesLabelsField       = 1
+	timeSeriesSamplesField      = 2
+	labelNameField              = 1
+	labelValueField             = 2
+	sampleValueField            = 1
+	sampleTimestampField        = 2
+)
+
+// protobuf wire types
+const (
+	protobufVarint  = 0
+	protobufFixed64 = 1
+	protobufBytes   = 2
+)
+
+// SolarPanelDataPrometheusEncoder writes a Prometheus remote-write request, a
+// snappy compressed WriteRequest protobuf message, with a time series per
+// parameter named by its source and labelled with its parameter id:
+//
+//	solar{parameter="38d503e5-dc1c-4549-8172-09d9c29070f7"}
+//
+// Samples have no fields to flag the events filled in for the buckets without
+// events with, so those are put in series with a filled="true" label instead.
+type SolarPanelDataPrometheusEncoder struct{}
+
+func NewSolarPanelDataPrometheusEncoder() *SolarPanelDataPrometheusEncoder {
+	return &SolarPanelDataPrometheusEncoder{}
+}
+
+// prometheusSeriesKey tells the time series of the remote-write request apart.
+type prometheusSeriesKey struct {
+	source      domain.EventSource
+	parameterId string
+	filled      bool
+}
+
+// prometheusSeries is a time series of the remote-write request, whose samples
+// are the events of a parameter.
+type prometheusSeries struct {
+	prometheusSeriesKey
+	events []domain.Event
+}
+
+func (encoder *SolarPanelDataPrometheusEncoder) EncodeSolarPanelData(
+	w io.Writer,
+	solarPanelData *domain.SolarPanelData,
+	options *ExportOptions,
+) error {
+	solarPanelData, filled, err := exportedData(solarPanelData, options)
+	if err != nil {
+		return err
+	}
+
+	// remote-write expects the samples of a series in time order, whatever the
+	// order asked for
+	seriesOptions := *options
+	seriesOptions.Sort = ExportSortParameterId
+
+	var series []*prometheusSeries
+	seriesByKey := map[prometheusSeriesKey]*prometheusSeries{}
+
+	for _, event := range sortedEvents(solarPanelData, filled, &seriesOptions) {
+		key := prometheusSeriesKey{source: event.source, parameterId: event.parameterId, filled: event.filled}
+
+		eventSeries, ok := seriesByKey[key]
+		if !ok {
+			eventSeries = &prometheusSeries{prometheusSeriesKey: key}
+			seriesByKey[key] = eventSeries
+			series = append(series, eventSeries)
+		}
+
+		eventSeries.events = append(eventSeries.events, event.event)
+	}
+
+	var writeRequest []byte
+	for _, eventSeries := range series {
+		writeRequest = appendProtobufMessage(
+			writeRequest,
+			writeRequestTimeseriesField,
+			marshalPrometheusSeries(eventSeries),
+		)
+	}
+
+	_, err = w.Write(snappy.Encode(nil, writeRequest))
+
+	return err
+}
+
+// EncodeError writes the message as plain text, as a remote-write request has
+// no way of carrying one.
+func (encoder *SolarPanelDataPrometheusEncoder) EncodeError(w io.Writer, message string) error {
+	_, err := io.WriteString(w, message+"\n")
+
+	return err
+}
+
+// marshalPrometheusSeries writes a TimeSeries message, with its labels sorted by
+// name as remote-write requires.
+func marshalPrometheusSeries(series *prometheusSeries) []byte {
+	labels := [][2]string{
+		{"__name__", string(series.source)},
+	}
+	if series.filled {
+		labels = append(labels, [2]string{"filled", "true"})
+	}
+	labels = append(labels, [2]string{"parameter", series.parameterId})
+
+	var message []byte
+
+	for _, label := range labels {
+		var labelMessage []byte
+		labelMessage = appendProtobufMessage(labelMessage, labelNameField, []byte(label[0]))
+		labelMessage = appendProtobufMessage(labelMessage, labelValueField, []byte(label[1]))
+
+		message = appendProtobufMessage(message, timeSeriesLabelsField, labelMessage)
+	}
+
+	for _, event := range series.events {
+		var sampleMessage []byte
+		sampleMessage = appendProtobufTag(sampleMessage, sampleValueField, protobufFixed64)
+		sampleMessage = binary.LittleEndian.AppendUint64(sampleMessage, math.Float64bits(event.Value))
+		sampleMessage = appendProtobufTag(sampleMessage, sampleTimestampField, protobufVarint)
+		sampleMessage = binary.AppendUvarint(sampleMessage, uint64(event.Timestamp.UnixMilli()))
+
+		message = appendProtobufMessage(message, timeSeriesSamplesField, sampleMessage)
+	}
+
+	return message
+}
+
+func appendProtobufTag(b []byte, field int, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wireType))
+}
+
+// appendProtobufMessage appends a length-delimited field, which strings and
+// embedded messages both are.
+func appendProtobufMessage(b []byte, field int, message []byte) []byte {
+	b = appendProtobufTag(b, field, protobufBytes)
+	b = binary.AppendUvarint(b, uint64(len(message)))
+
+	return append(b, message...)
+}
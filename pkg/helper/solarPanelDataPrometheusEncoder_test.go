@@ -0,0 +1,79 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/golang/snappy"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSolarPanelDataPrometheusEncoder_EncodeSolarPanelData(t *testing.T) {
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"p1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 2},
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1.5},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		options *ExportOptions
+		// expected is the WriteRequest message before it is compressed
+		expected string
+	}{
+		{
+			name:    "valid solar in time order",
+			options: &ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, Sort: ExportSortTimestamp},
+			// solar{parameter="p1"} 1.5 @1640998800000, 2 @1641002400000
+			expected: "0a480a110a085f5f6e616d655f5f1205736f6c61720a0f0a09706172616d6574657212027031" +
+				"121009000000000000f83f1080959a99e12f12100900000000000000401080f2f59ae12f",
+		},
+		{
+			name: "valid solar with fill",
+			options: &ExportOptions{
+				EventSelection: domain.EventSelection{
+					Source: domain.EventSourceSolar,
+				},
+				Sort:        ExportSortParameterId,
+				Interval:    30 * time.Minute,
+				Aggregation: ExportAggregationMean,
+				Location:    time.UTC,
+				Fill:        ExportFillPrevious,
+			},
+			// the above, and solar{filled="true",parameter="p1"} 1.5 @1641000600000
+			expected: "0a480a110a085f5f6e616d655f5f1205736f6c61720a0f0a09706172616d6574657212027031" +
+				"121009000000000000f83f1080959a99e12f12100900000000000000401080f2f59ae12f" +
+				"0a460a110a085f5f6e616d655f5f1205736f6c61720a0e0a0666696c6c65641204747275650a0f0a09706172616d6574657212027031" +
+				"121009000000000000f83f10c083889ae12f",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := SolarPanelDataPrometheusEncoder{}
+			actual := &bytes.Buffer{}
+
+			err := encoder.EncodeSolarPanelData(actual, solarPanelData, tt.options)
+			assert.NoError(t, err)
+
+			writeRequest, err := snappy.Decode(nil, actual.Bytes())
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, hex.EncodeToString(writeRequest))
+		})
+	}
+}
+
+func TestSolarPanelDataPrometheusEncoder_EncodeError(t *testing.T) {
+	encoder := SolarPanelDataPrometheusEncoder{}
+	actual := &bytes.Buffer{}
+
+	err := encoder.EncodeError(actual, "missing solarPanelData id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "missing solarPanelData id\n", actual.String())
+}
@@ -0,0 +1,255 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"math"
+	"sort"
+	"time"
+)
+
+// qualityGapTolerance is how many cadences apart two readings may be before
+// the readings in between count as missing, so that a logger running a little
+// late is not reported.
+const qualityGapTolerance = 1.5
+
+type SolarPanelDataQualityAnalyzerInterface interface {
+	AnalyzeQuality(*domain.SolarPanelData, *QualityOptions) ([]ParameterQuality, error)
+}
+
+// ParameterQuality is the data quality report of a series.
+type ParameterQuality struct {
+	Source      domain.EventSource
+	ParameterId string
+	Count       int
+	// Cadence is the interval the series is sampled at, inferred as the median
+	// interval between its readings, or 0 when it has less than two distinct
+	// timestamps.
+	Cadence          time.Duration
+	MissingIntervals []MissingInterval
+	// MissingReadings adds up the readings missing in MissingIntervals.
+	MissingReadings int
+	// DuplicateTimestamps lists once each timestamp more than one event has.
+	DuplicateTimestamps []time.Time
+	// OutOfOrderEvents is the number of events submitted after an event with a
+	// later timestamp.
+	OutOfOrderEvents int
+	Flatlines        []Flatline
+	NegativeReadings int
+	// Score is the percentage, from 0 to 100, of the expected readings, the
+	// events along with the missing ones, that are events with no issue. A
+	// series without events scores 0.
+	Score float64
+}
+
+// MissingInterval is an interval between two readings longer than the cadence
+// allows for.
+type MissingInterval struct {
+	From            time.Time
+	To              time.Time
+	MissingReadings int
+}
+
+// Flatline is a run of consecutive readings of the same value.
+type Flatline struct {
+	From     time.Time
+	To       time.Time
+	Value    float64
+	Readings int
+}
+
+type SolarPanelDataQualityAnalyzer struct{}
+
+func NewSolarPanelDataQualityAnalyzer() *SolarPanelDataQualityAnalyzer {
+	return &SolarPanelDataQualityAnalyzer{}
+}
+
+// AnalyzeQuality reports the data quality issues of each series of the chosen
+// source, in source and parameter id order.
+func (analyzer SolarPanelDataQualityAnalyzer) AnalyzeQuality(
+	solarPanelData *domain.SolarPanelData,
+	options *QualityOptions,
+) ([]ParameterQuality, error) {
+	solarPanelData, err := selectEvents(solarPanelData, &options.EventSelection)
+	if err != nil {
+		return nil, err
+	}
+
+	quality := []ParameterQuality{}
+
+	for _, source := range options.Source.Sources() {
+		events := solarPanelData.EventsOf(source)
+
+		parameterIds := make([]string, 0, len(events))
+		for parameterId := range events {
+			parameterIds = append(parameterIds, parameterId)
+		}
+		sort.Strings(parameterIds)
+
+		for _, parameterId := range parameterIds {
+			parameterQuality := seriesQuality(events[parameterId], options)
+			parameterQuality.Source = source
+			parameterQuality.ParameterId = parameterId
+
+			quality = append(quality, parameterQuality)
+		}
+	}
+
+	return quality, nil
+}
+
+// seriesQuality checks the events of a series, leaving its source and
+// parameter id to the caller. An event with more than one issue is only
+// counted once against the score.
+func seriesQuality(events []domain.Event, options *QualityOptions) ParameterQuality {
+	quality := ParameterQuality{
+		Count:               len(events),
+		MissingIntervals:    []MissingInterval{},
+		DuplicateTimestamps: []time.Time{},
+		Flatlines:           []Flatline{},
+	}
+
+	if len(events) == 0 {
+		return quality
+	}
+
+	flagged := make([]bool, len(events))
+
+	// events are stored in the order they were submitted in
+	latest := events[0].Timestamp
+	for index, event := range events {
+		if event.Timestamp.Before(latest) {
+			quality.OutOfOrderEvents++
+			flagged[index] = true
+		}
+
+		if event.Timestamp.After(latest) {
+			latest = event.Timestamp
+		}
+
+		if event.Value < 0 {
+			quality.NegativeReadings++
+			flagged[index] = true
+		}
+	}
+
+	order := make([]int, len(events))
+	for index := range order {
+		order[index] = index
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return events[order[i]].Timestamp.Before(events[order[j]].Timestamp)
+	})
+
+	var intervals []time.Duration
+
+	for position := 1; position < len(order); position++ {
+		previous, current := events[order[position-1]], events[order[position]]
+
+		if current.Timestamp.Equal(previous.Timestamp) {
+			duplicates := len(quality.DuplicateTimestamps)
+			if duplicates == 0 || !quality.DuplicateTimestamps[duplicates-1].Equal(current.Timestamp) {
+				quality.DuplicateTimestamps = append(quality.DuplicateTimestamps, current.Timestamp)
+			}
+
+			flagged[order[position]] = true
+
+			continue
+		}
+
+		intervals = append(intervals, current.Timestamp.Sub(previous.Timestamp))
+	}
+
+	quality.Cadence = medianInterval(intervals)
+
+	if quality.Cadence > 0 {
+		start := events[order[0]].Timestamp
+
+		for _, interval := range intervals {
+			end := start.Add(interval)
+
+			if float64(interval) > float64(quality.Cadence)*qualityGapTolerance {
+				missingReadings := int(math.Round(float64(interval)/float64(quality.Cadence))) - 1
+
+				quality.MissingIntervals = append(quality.MissingIntervals, MissingInterval{
+					From:            start,
+					To:              end,
+					MissingReadings: missingReadings,
+				})
+				quality.MissingReadings += missingReadings
+			}
+
+			start = end
+		}
+	}
+
+	quality.Flatlines = flatlines(events, order, flagged, options.FlatlineReadings)
+
+	unflagged := 0
+	for _, isFlagged := range flagged {
+		if !isFlagged {
+			unflagged++
+		}
+	}
+
+	quality.Score = float64(unflagged) * 100 / float64(len(events)+quality.MissingReadings)
+
+	return quality
+}
+
+// flatlines finds the runs of at least the given number of readings of the
+// same value, in timestamp order, flagging every reading of a run but the
+// first. Runs of zeros are left out, as a solar panel reads 0 every night.
+func flatlines(events []domain.Event, order []int, flagged []bool, minimumReadings int) []Flatline {
+	found := []Flatline{}
+
+	runStart := 0
+
+	for position := 1; position <= len(order); position++ {
+		if position < len(order) && events[order[position]].Value == events[order[runStart]].Value {
+			continue
+		}
+
+		readings := position - runStart
+		value := events[order[runStart]].Value
+
+		if readings >= minimumReadings && value != 0 {
+			found = append(found, Flatline{
+				From:     events[order[runStart]].Timestamp,
+				To:       events[order[position-1]].Timestamp,
+				Value:    value,
+				Readings: readings,
+			})
+
+			for flatlined := runStart + 1; flatlined < position; flatlined++ {
+				flagged[order[flatlined]] = true
+			}
+		}
+
+		runStart = position
+	}
+
+	return found
+}
+
+// medianInterval returns the median of the intervals, or 0 when there are
+// none. The median rather than the mean, so that a few long gaps do not stretch
+// the cadence they are measured against.
+func medianInterval(intervals []time.Duration) time.Duration {
+	if len(intervals) == 0 {
+		return 0
+	}
+
+	sorted := make([]time.Duration, len(intervals))
+	copy(sorted, intervals)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
@@ -0,0 +1,147 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSolarPanelDataQualityAnalyzer_AnalyzeQuality(t *testing.T) {
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 100},
+				{Timestamp: time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC), Value: -5},
+				{Timestamp: time.Date(2022, 1, 1, 11, 15, 0, 0, time.UTC), Value: 130},
+				{Timestamp: time.Date(2022, 1, 1, 11, 0, 0, 0, time.UTC), Value: 120},
+				{Timestamp: time.Date(2022, 1, 1, 11, 15, 0, 0, time.UTC), Value: 130},
+				{Timestamp: time.Date(2022, 1, 1, 11, 30, 0, 0, time.UTC), Value: 50},
+				{Timestamp: time.Date(2022, 1, 1, 11, 45, 0, 0, time.UTC), Value: 50},
+				{Timestamp: time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC), Value: 50},
+				{Timestamp: time.Date(2022, 1, 1, 12, 15, 0, 0, time.UTC), Value: 50},
+			},
+			"uuid2": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Value: 0},
+				{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 0},
+				{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 0},
+				{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 0},
+			},
+		},
+		Wind: map[string][]domain.Event{
+			"uuid1": []domain.Event{},
+			"uuid3": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Value: 12.5},
+			},
+		},
+	}
+
+	uuid1Quality := ParameterQuality{
+		Source:      domain.EventSourceSolar,
+		ParameterId: "uuid1",
+		Count:       9,
+		Cadence:     15 * time.Minute,
+		MissingIntervals: []MissingInterval{
+			{
+				From:            time.Date(2022, 1, 1, 10, 15, 0, 0, time.UTC),
+				To:              time.Date(2022, 1, 1, 11, 0, 0, 0, time.UTC),
+				MissingReadings: 2,
+			},
+		},
+		MissingReadings:     2,
+		DuplicateTimestamps: []time.Time{time.Date(2022, 1, 1, 11, 15, 0, 0, time.UTC)},
+		OutOfOrderEvents:    1,
+		Flatlines: []Flatline{
+			{
+				From:     time.Date(2022, 1, 1, 11, 30, 0, 0, time.UTC),
+				To:       time.Date(2022, 1, 1, 12, 15, 0, 0, time.UTC),
+				Value:    50,
+				Readings: 4,
+			},
+		},
+		NegativeReadings: 1,
+		Score:            300.0 / 11,
+	}
+
+	withoutFlatlines := uuid1Quality
+	withoutFlatlines.Flatlines = []Flatline{}
+	withoutFlatlines.Score = 600.0 / 11
+
+	tests := []struct {
+		name                 string
+		options              *QualityOptions
+		expected             []ParameterQuality
+		expectError          bool
+		expectedErrorMessage string
+	}{
+		{
+			name:    "solar",
+			options: &QualityOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}, FlatlineReadings: 4},
+			expected: []ParameterQuality{
+				uuid1Quality,
+				{
+					Source:              domain.EventSourceSolar,
+					ParameterId:         "uuid2",
+					Count:               4,
+					Cadence:             time.Hour,
+					MissingIntervals:    []MissingInterval{},
+					DuplicateTimestamps: []time.Time{},
+					Flatlines:           []Flatline{},
+					Score:               100,
+				},
+			},
+		},
+		{
+			name:    "longer flatlines",
+			options: &QualityOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar, Parameters: []string{"uuid1"}}, FlatlineReadings: 5},
+			expected: []ParameterQuality{
+				withoutFlatlines,
+			},
+		},
+		{
+			name:    "wind without enough events",
+			options: &QualityOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceWind}, FlatlineReadings: 4},
+			expected: []ParameterQuality{
+				{
+					Source:              domain.EventSourceWind,
+					ParameterId:         "uuid1",
+					MissingIntervals:    []MissingInterval{},
+					DuplicateTimestamps: []time.Time{},
+					Flatlines:           []Flatline{},
+				},
+				{
+					Source:              domain.EventSourceWind,
+					ParameterId:         "uuid3",
+					Count:               1,
+					MissingIntervals:    []MissingInterval{},
+					DuplicateTimestamps: []time.Time{},
+					Flatlines:           []Flatline{},
+					Score:               100,
+				},
+			},
+		},
+		{
+			name:                 "unknown parameters",
+			options:              &QualityOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceWind, Parameters: []string{"uuid2"}}, FlatlineReadings: 4},
+			expectError:          true,
+			expectedErrorMessage: "unknown parameters uuid2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewSolarPanelDataQualityAnalyzer()
+
+			actual, err := analyzer.AnalyzeQuality(solarPanelData, tt.options)
+
+			if tt.expectError {
+				assert.EqualError(t, err, tt.expectedErrorMessage)
+				assert.Nil(t, actual)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"math"
+	"sort"
+	"time"
+)
+
+type SolarPanelDataStatisticsCalculatorInterface interface {
+	CalculateStatistics(*domain.SolarPanelData, *StatisticsOptions) ([]ParameterStatistics, error)
+}
+
+// ParameterStatistics summarises the events of a series. Everything but the
+// count is left zero for a series without events.
+type ParameterStatistics struct {
+	Source      domain.EventSource
+	ParameterId string
+	Count       int
+	First       time.Time
+	Last        time.Time
+	Minimum     float64
+	Maximum     float64
+	Mean        float64
+	// StandardDeviation is the population standard deviation, as the events
+	// are all the readings of the series rather than a sample of them.
+	StandardDeviation float64
+	// ZeroPercentage is the percentage, from 0 to 100, of the events valued 0.
+	ZeroPercentage float64
+}
+
+type SolarPanelDataStatisticsCalculator struct{}
+
+func NewSolarPanelDataStatisticsCalculator() *SolarPanelDataStatisticsCalculator {
+	return &SolarPanelDataStatisticsCalculator{}
+}
+
+// CalculateStatistics summarises each series of the chosen source, in source
+// and parameter id order.
+func (calculator SolarPanelDataStatisticsCalculator) CalculateStatistics(
+	solarPanelData *domain.SolarPanelData,
+	options *StatisticsOptions,
+) ([]ParameterStatistics, error) {
+	solarPanelData, err := selectEvents(solarPanelData, &options.EventSelection)
+	if err != nil {
+		return nil, err
+	}
+
+	statistics := []ParameterStatistics{}
+
+	for _, source := range options.Source.Sources() {
+		events := solarPanelData.EventsOf(source)
+
+		parameterIds := make([]string, 0, len(events))
+		for parameterId := range events {
+			parameterIds = append(parameterIds, parameterId)
+		}
+		sort.Strings(parameterIds)
+
+		for _, parameterId := range parameterIds {
+			parameterStatistics := seriesStatistics(events[parameterId])
+			parameterStatistics.Source = source
+			parameterStatistics.ParameterId = parameterId
+
+			statistics = append(statistics, parameterStatistics)
+		}
+	}
+
+	return statistics, nil
+}
+
+// seriesStatistics summarises the events of a series, leaving its source and
+// parameter id to the caller.
+func seriesStatistics(events []domain.Event) ParameterStatistics {
+	statistics := ParameterStatistics{Count: len(events)}
+	if len(events) == 0 {
+		return statistics
+	}
+
+	statistics.First, statistics.Last = events[0].Timestamp, events[0].Timestamp
+	statistics.Minimum, statistics.Maximum = events[0].Value, events[0].Value
+
+	sum, zeros := 0.0, 0
+
+	for _, event := range events {
+		if event.Timestamp.Before(statistics.First) {
+			statistics.First = event.Timestamp
+		}
+
+		if event.Timestamp.After(statistics.Last) {
+			statistics.Last = event.Timestamp
+		}
+
+		if event.Value < statistics.Minimum {
+			statistics.Minimum = event.Value
+		}
+
+		if event.Value > statistics.Maximum {
+			statistics.Maximum = event.Value
+		}
+
+		if event.Value == 0 {
+			zeros++
+		}
+
+		sum += event.Value
+	}
+
+	statistics.Mean = sum / float64(len(events))
+
+	// from the deviations of the mean rather than the sum of squares, which
+	// loses precision on large readings
+	squaredDeviations := 0.0
+	for _, event := range events {
+		squaredDeviations += (event.Value - statistics.Mean) * (event.Value - statistics.Mean)
+	}
+
+	statistics.StandardDeviation = math.Sqrt(squaredDeviations / float64(len(events)))
+	statistics.ZeroPercentage = float64(zeros) * 100 / float64(len(events))
+
+	return statistics
+}
@@ -0,0 +1,127 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSolarPanelDataStatisticsCalculator_CalculateStatistics(t *testing.T) {
+	from := time.Date(2022, 1, 1, 7, 0, 0, 0, time.UTC)
+
+	solarPanelData := &domain.SolarPanelData{
+		Solar: map[string][]domain.Event{
+			"uuid2": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 8, 0, 0, 0, time.UTC), Value: 2},
+				{Timestamp: time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC), Value: 4},
+				{Timestamp: time.Date(2022, 1, 1, 9, 0, 0, 0, time.UTC), Value: 4},
+				{Timestamp: time.Date(2022, 1, 1, 7, 0, 0, 0, time.UTC), Value: 4},
+				{Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC), Value: 5},
+				{Timestamp: time.Date(2022, 1, 1, 11, 0, 0, 0, time.UTC), Value: 5},
+				{Timestamp: time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC), Value: 7},
+				{Timestamp: time.Date(2022, 1, 1, 13, 0, 0, 0, time.UTC), Value: 9},
+			},
+			"uuid1": []domain.Event{
+				{Timestamp: time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC), Value: 0},
+				{Timestamp: time.Date(2022, 1, 1, 7, 0, 0, 0, time.UTC), Value: 4},
+				{Timestamp: time.Date(2022, 1, 1, 8, 0, 0, 0, time.UTC), Value: 0},
+				{Timestamp: time.Date(2022, 1, 1, 9, 0, 0, 0, time.UTC), Value: 4},
+			},
+		},
+		Wind: map[string][]domain.Event{
+			"uuid1": []domain.Event{},
+		},
+	}
+
+	tests := []struct {
+		name                 string
+		options              *StatisticsOptions
+		expected             []ParameterStatistics
+		expectError          bool
+		expectedErrorMessage string
+	}{
+		{
+			name:    "solar",
+			options: &StatisticsOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}},
+			expected: []ParameterStatistics{
+				{
+					Source:            domain.EventSourceSolar,
+					ParameterId:       "uuid1",
+					Count:             4,
+					First:             time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC),
+					Last:              time.Date(2022, 1, 1, 9, 0, 0, 0, time.UTC),
+					Minimum:           0,
+					Maximum:           4,
+					Mean:              2,
+					StandardDeviation: 2,
+					ZeroPercentage:    50,
+				},
+				{
+					Source:            domain.EventSourceSolar,
+					ParameterId:       "uuid2",
+					Count:             8,
+					First:             time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC),
+					Last:              time.Date(2022, 1, 1, 13, 0, 0, 0, time.UTC),
+					Minimum:           2,
+					Maximum:           9,
+					Mean:              5,
+					StandardDeviation: 2,
+					ZeroPercentage:    0,
+				},
+			},
+		},
+		{
+			name: "both sources in a time window",
+			options: &StatisticsOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceAll,
+					From:       &from,
+					Parameters: []string{"uuid1"},
+				},
+			},
+			expected: []ParameterStatistics{
+				{
+					Source:            domain.EventSourceSolar,
+					ParameterId:       "uuid1",
+					Count:             3,
+					First:             time.Date(2022, 1, 1, 7, 0, 0, 0, time.UTC),
+					Last:              time.Date(2022, 1, 1, 9, 0, 0, 0, time.UTC),
+					Minimum:           0,
+					Maximum:           4,
+					Mean:              8.0 / 3,
+					StandardDeviation: 1.8856180831641267,
+					ZeroPercentage:    100.0 / 3,
+				},
+				{
+					Source:      domain.EventSourceWind,
+					ParameterId: "uuid1",
+					Count:       0,
+				},
+			},
+		},
+		{
+			name:                 "unknown parameters",
+			options:              &StatisticsOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceWind, Parameters: []string{"uuid2"}}},
+			expectError:          true,
+			expectedErrorMessage: "unknown parameters uuid2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := NewSolarPanelDataStatisticsCalculator()
+
+			actual, err := calculator.CalculateStatistics(solarPanelData, tt.options)
+
+			if tt.expectError {
+				assert.EqualError(t, err, tt.expectedErrorMessage)
+				assert.Nil(t, actual)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package helper
+
+import (
+	"github.com/google/uuid"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type SolarPanelDataValidatorInterface interface {
+	ValidateSolarPanelData(map[string][][]string, map[string][][]string) error
+}
+
+type SolarPanelDataValidator struct {
+	now func() time.Time
+}
+
+func NewSolarPanelDataValidator() *SolarPanelDataValidator {
+	return &SolarPanelDataValidator{
+		now: time.Now,
+	}
+}
+
+// ValidateSolarPanelData checks the solar and wind events of a submitted
+// document before they are parsed. Solar events are required, wind events are
+// optional, and both follow the same rules: parameter and turbine ids must be
+// UUIDs and every event must be a [timestamp, value] pair, with a timestamp in
+// domain.EventTimestampLayout that is not in the future and not repeated within
+// the series, and a finite numeric value. All violations are collected into a
+// single apierrors.ValidationError.
+func (validator SolarPanelDataValidator) ValidateSolarPanelData(
+	solar map[string][][]string,
+	wind map[string][][]string,
+) error {
+	var violations []apierrors.Violation
+
+	if solar == nil {
+		violations = append(violations, apierrors.Violation{Path: "$.solar", Message: "is required"})
+	} else {
+		violations = append(violations, validator.validateEvents("solar", solar)...)
+	}
+
+	if wind != nil {
+		violations = append(violations, validator.validateEvents("wind", wind)...)
+	}
+
+	if len(violations) > 0 {
+		return apierrors.ValidationError{
+			ReturnedStatusCode: http.StatusBadRequest,
+			Violations:         violations,
+		}
+	}
+
+	return nil
+}
+
+func (validator SolarPanelDataValidator) validateEvents(
+	field string,
+	eventsPerParameterId map[string][][]string,
+) []apierrors.Violation {
+	const EventArrayElementNormalSize = 2
+
+	fieldPath := "$." + field
+
+	now := validator.now()
+
+	parameterIds := make([]string, 0, len(eventsPerParameterId))
+	for parameterId := range eventsPerParameterId {
+		parameterIds = append(parameterIds, parameterId)
+	}
+
+	// sorted so that the violations are always reported in the same order
+	sort.Strings(parameterIds)
+
+	var violations []apierrors.Violation
+
+	for _, parameterId := range parameterIds {
+		parameterPath := fieldPath + "[" + strconv.Quote(parameterId) + "]"
+
+		if !isUuid(parameterId) {
+			violations = append(violations, apierrors.Violation{Path: parameterPath, Message: "is not a UUID"})
+		}
+
+		eventIndexPerTimestamp := map[time.Time]int{}
+
+		for index, event := range eventsPerParameterId[parameterId] {
+			eventPath := parameterPath + "[" + strconv.Itoa(index) + "]"
+
+			if len(event) != EventArrayElementNormalSize {
+				violations = append(violations, apierrors.Violation{
+					Path:    eventPath,
+					Message: "must be a [timestamp, value] pair",
+				})
+
+				continue
+			}
+
+			timestampPath := eventPath + "[0]"
+
+			timestamp, err := time.Parse(domain.EventTimestampLayout, event[0])
+			switch {
+			case err != nil:
+				violations = append(violations, apierrors.Violation{
+					Path:    timestampPath,
+					Message: "must be a timestamp in the " + domain.EventTimestampLayout + " format",
+				})
+			case timestamp.After(now):
+				violations = append(violations, apierrors.Violation{
+					Path:    timestampPath,
+					Message: "must not be in the future",
+				})
+			default:
+				if firstIndex, exists := eventIndexPerTimestamp[timestamp]; exists {
+					violations = append(violations, apierrors.Violation{
+						Path:    timestampPath,
+						Message: "duplicates the timestamp of event " + strconv.Itoa(firstIndex),
+					})
+				} else {
+					eventIndexPerTimestamp[timestamp] = index
+				}
+			}
+
+			value, err := strconv.ParseFloat(event[1], 64)
+			if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+				violations = append(violations, apierrors.Violation{
+					Path:    eventPath + "[1]",
+					Message: "must be a finite number",
+				})
+			}
+		}
+	}
+
+	return violations
+}
+
+// isUuid accepts only the canonical 36 character form, which uuid.Parse does
+// not enforce on its own.
+func isUuid(value string) bool {
+	const CanonicalUuidLength = 36
+
+	if len(value) != CanonicalUuidLength {
+		return false
+	}
+
+	_, err := uuid.Parse(value)
+
+	return err == nil
+}
@@ -0,0 +1,169 @@
+package helper
+
+import (
+	apierrors "github.com/loukaspe/solar-panel-data-crud/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSolarPanelDataValidator_ValidateSolarPanelData(t *testing.T) {
+	type args struct {
+		solar map[string][][]string
+		wind  map[string][][]string
+	}
+
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		args          args
+		expectError   bool
+		expectedError error
+	}{
+		{
+			name: "valid without wind",
+			args: args{
+				solar: map[string][][]string{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": {
+						{"20211231T221500Z", "0.0"},
+						{"20211231T223000Z", "-1.5"},
+					},
+					"51df2e4c-2002-11ea-95a5-525400b2701a": {
+						{"20220101T120000Z", "81.9354839"},
+					},
+					"c078ff68-04fb-11e9-a615-42010afa015a": {},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid with wind",
+			args: args{
+				solar: map[string][][]string{},
+				wind: map[string][][]string{
+					"c078ff68-04fb-11e9-a615-42010afa015a": {
+						{"20211231T221500Z", "12.5"},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid missing solar",
+			args: args{
+				solar: nil,
+				wind: map[string][][]string{
+					"turbine1": {},
+				},
+			},
+			expectError: true,
+			expectedError: apierrors.ValidationError{
+				ReturnedStatusCode: http.StatusBadRequest,
+				Violations: []apierrors.Violation{
+					{Path: "$.solar", Message: "is required"},
+					{Path: `$.wind["turbine1"]`, Message: "is not a UUID"},
+				},
+			},
+		},
+		{
+			name: "invalid parameter ids",
+			args: args{
+				solar: map[string][][]string{
+					"uuid1": {
+						{"20211231T221500Z", "0.0"},
+					},
+					"{38d503e5-dc1c-4549-8172-09d9c29070f7}": {
+						{"20211231T221500Z", "0.0"},
+					},
+				},
+			},
+			expectError: true,
+			expectedError: apierrors.ValidationError{
+				ReturnedStatusCode: http.StatusBadRequest,
+				Violations: []apierrors.Violation{
+					{Path: `$.solar["uuid1"]`, Message: "is not a UUID"},
+					{Path: `$.solar["{38d503e5-dc1c-4549-8172-09d9c29070f7}"]`, Message: "is not a UUID"},
+				},
+			},
+		},
+		{
+			name: "invalid wind events",
+			args: args{
+				solar: map[string][][]string{},
+				wind: map[string][][]string{
+					"38d503e5-dc1c-4549-8172-09d9c29070f7": {
+						{"20211231T221500Z"},
+						{},
+						{"20211231T221500Z", "0.0", "1.0"},
+						{"2021-12-31T22:15:00Z", "0.0"},
+						{"20220101T120001Z", "0.0"},
+						{"20211231T223000Z", ""},
+						{"20211231T224500Z", "NaN"},
+						{"20211231T224500Z", "+Inf"},
+					},
+				},
+			},
+			expectError: true,
+			expectedError: apierrors.ValidationError{
+				ReturnedStatusCode: http.StatusBadRequest,
+				Violations: []apierrors.Violation{
+					{
+						Path:    `$.wind["38d503e5-dc1c-4549-8172-09d9c29070f7"][0]`,
+						Message: "must be a [timestamp, value] pair",
+					},
+					{
+						Path:    `$.wind["38d503e5-dc1c-4549-8172-09d9c29070f7"][1]`,
+						Message: "must be a [timestamp, value] pair",
+					},
+					{
+						Path:    `$.wind["38d503e5-dc1c-4549-8172-09d9c29070f7"][2]`,
+						Message: "must be a [timestamp, value] pair",
+					},
+					{
+						Path:    `$.wind["38d503e5-dc1c-4549-8172-09d9c29070f7"][3][0]`,
+						Message: "must be a timestamp in the 20060102T150405Z format",
+					},
+					{
+						Path:    `$.wind["38d503e5-dc1c-4549-8172-09d9c29070f7"][4][0]`,
+						Message: "must not be in the future",
+					},
+					{
+						Path:    `$.wind["38d503e5-dc1c-4549-8172-09d9c29070f7"][5][1]`,
+						Message: "must be a finite number",
+					},
+					{
+						Path:    `$.wind["38d503e5-dc1c-4549-8172-09d9c29070f7"][6][1]`,
+						Message: "must be a finite number",
+					},
+					{
+						Path:    `$.wind["38d503e5-dc1c-4549-8172-09d9c29070f7"][7][0]`,
+						Message: "duplicates the timestamp of event 6",
+					},
+					{
+						Path:    `$.wind["38d503e5-dc1c-4549-8172-09d9c29070f7"][7][1]`,
+						Message: "must be a finite number",
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := SolarPanelDataValidator{
+				now: func() time.Time { return now },
+			}
+
+			actualError := validator.ValidateSolarPanelData(tt.args.solar, tt.args.wind)
+			if (actualError != nil) != tt.expectError {
+				t.Errorf("ValidateSolarPanelData() error = %v, expectError %v", actualError, tt.expectError)
+				return
+			}
+
+			if tt.expectError {
+				assert.Equal(t, tt.expectedError, actualError)
+			}
+		})
+	}
+}
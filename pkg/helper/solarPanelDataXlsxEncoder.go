@@ -0,0 +1,215 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	MediaTypeXlsx = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	xlsxSummarySheet   = "Summary"
+	xlsxErrorSheet     = "Error"
+	xlsxTimestampStyle = "yyyy-mm-dd hh:mm:ss"
+	// xlsxMaxSheetNameLength is the limit Excel puts on worksheet names, which a
+	// UUID parameter id is over.
+	xlsxMaxSheetNameLength = 31
+)
+
+// SolarPanelDataXlsxEncoder writes a workbook with a summary worksheet followed
+// by one worksheet of timestamps and values per parameter id, in the source of
+// the export options.
+type SolarPanelDataXlsxEncoder struct{}
+
+func NewSolarPanelDataXlsxEncoder() *SolarPanelDataXlsxEncoder {
+	return &SolarPanelDataXlsxEncoder{}
+}
+
+func (encoder *SolarPanelDataXlsxEncoder) EncodeSolarPanelData(
+	w io.Writer,
+	solarPanelData *domain.SolarPanelData,
+	options *ExportOptions,
+) error {
+	solarPanelData, filled, err := exportedData(solarPanelData, options)
+	if err != nil {
+		return err
+	}
+
+	workbook := excelize.NewFile()
+	defer workbook.Close()
+
+	err = workbook.SetSheetName(workbook.GetSheetName(0), xlsxSummarySheet)
+	if err != nil {
+		return err
+	}
+
+	err = workbook.SetSheetRow(xlsxSummarySheet, "A1", &[]interface{}{
+		"Source", "Parameter Id", "Worksheet", "Count", "Min", "Max", "Mean",
+	})
+	if err != nil {
+		return err
+	}
+
+	timestampStyle := xlsxTimestampStyle
+	timestampStyleId, err := workbook.NewStyle(&excelize.Style{CustomNumFmt: &timestampStyle})
+	if err != nil {
+		return err
+	}
+
+	sheetNames := map[string]bool{xlsxSummarySheet: true}
+	summaryRow := 2
+
+	for _, source := range options.Source.Sources() {
+		events := solarPanelData.EventsOf(source)
+
+		parameterIds := make([]string, 0, len(events))
+		for parameterId := range events {
+			parameterIds = append(parameterIds, parameterId)
+		}
+		sort.Strings(parameterIds)
+
+		for _, parameterId := range parameterIds {
+			sheetName := xlsxSheetName(parameterId, sheetNames)
+			sheetNames[sheetName] = true
+
+			var flagFilled func(domain.Event) bool
+			if fillsBuckets(options) {
+				flagFilled = func(event domain.Event) bool {
+					return filled.contains(source, parameterId, event)
+				}
+			}
+
+			err = writeXlsxParameterSheet(workbook, sheetName, events[parameterId], flagFilled, timestampStyleId)
+			if err != nil {
+				return err
+			}
+
+			summary := []interface{}{string(source), parameterId, sheetName, len(events[parameterId])}
+			if len(events[parameterId]) > 0 {
+				statistics := seriesStatistics(events[parameterId])
+				summary = append(summary, statistics.Minimum, statistics.Maximum, statistics.Mean)
+			}
+
+			err = workbook.SetSheetRow(xlsxSummarySheet, "A"+strconv.Itoa(summaryRow), &summary)
+			if err != nil {
+				return err
+			}
+
+			summaryRow++
+		}
+	}
+
+	return workbook.Write(w)
+}
+
+// EncodeError writes the message as the only cell of a workbook.
+func (encoder *SolarPanelDataXlsxEncoder) EncodeError(w io.Writer, message string) error {
+	workbook := excelize.NewFile()
+	defer workbook.Close()
+
+	err := workbook.SetSheetName(workbook.GetSheetName(0), xlsxErrorSheet)
+	if err != nil {
+		return err
+	}
+
+	err = workbook.SetCellStr(xlsxErrorSheet, "A1", message)
+	if err != nil {
+		return err
+	}
+
+	return workbook.Write(w)
+}
+
+// writeXlsxParameterSheet writes the events of a parameter id in timestamp
+// order, with the timestamps as date cells so that they can be charted, and
+// whether each was filled in by flagFilled, when set.
+func writeXlsxParameterSheet(
+	workbook *excelize.File,
+	sheetName string,
+	events []domain.Event,
+	flagFilled func(domain.Event) bool,
+	timestampStyleId int,
+) error {
+	_, err := workbook.NewSheet(sheetName)
+	if err != nil {
+		return err
+	}
+
+	header := []interface{}{ExportColumnTimestamp, ExportColumnValue}
+	if flagFilled != nil {
+		header = append(header, ExportColumnFilled)
+	}
+
+	err = workbook.SetSheetRow(sheetName, "A1", &header)
+	if err != nil {
+		return err
+	}
+
+	err = workbook.SetColWidth(sheetName, "A", "A", 20)
+	if err != nil {
+		return err
+	}
+
+	sortedEvents := make([]domain.Event, len(events))
+	copy(sortedEvents, events)
+	sort.SliceStable(sortedEvents, func(i, j int) bool {
+		return sortedEvents[i].Timestamp.Before(sortedEvents[j].Timestamp)
+	})
+
+	for index, event := range sortedEvents {
+		cell := "A" + strconv.Itoa(index+2)
+
+		row := []interface{}{event.Timestamp.UTC(), event.Value}
+		if flagFilled != nil {
+			row = append(row, flagFilled(event))
+		}
+
+		err = workbook.SetSheetRow(sheetName, cell, &row)
+		if err != nil {
+			return err
+		}
+
+		err = workbook.SetCellStyle(sheetName, cell, cell, timestampStyleId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// xlsxSheetName shortens a parameter id to a worksheet name Excel accepts,
+// numbering it when the shortened name is already taken.
+func xlsxSheetName(parameterId string, taken map[string]bool) string {
+	sanitized := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '_'
+		}
+
+		return r
+	}, parameterId)
+
+	name := truncate(sanitized, xlsxMaxSheetNameLength)
+
+	for suffix := 2; taken[name] || name == ""; suffix++ {
+		number := "~" + strconv.Itoa(suffix)
+		name = truncate(sanitized, xlsxMaxSheetNameLength-len(number)) + number
+	}
+
+	return name
+}
+
+// truncate shortens the value to length characters, which is how Excel counts
+// the length of a worksheet name, so that no character is cut in half.
+func truncate(value string, length int) string {
+	characters := []rune(value)
+	if len(characters) > length {
+		return string(characters[:length])
+	}
+
+	return value
+}
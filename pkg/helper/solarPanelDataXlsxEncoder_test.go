@@ -0,0 +1,163 @@
+package helper
+
+import (
+	"bytes"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+	"testing"
+	"time"
+)
+
+func TestSolarPanelDataXlsxEncoder_EncodeSolarPanelData(t *testing.T) {
+	encoder := NewSolarPanelDataXlsxEncoder()
+	actual := &bytes.Buffer{}
+
+	err := encoder.EncodeSolarPanelData(
+		actual,
+		&domain.SolarPanelData{
+			Solar: map[string][]domain.Event{
+				"51df2e4c-2002-11ea-95a5-525400b2701a": []domain.Event{},
+				"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 2, 0, 0, 0, time.UTC), Value: 4},
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: -1.5},
+					{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 0.5},
+				},
+			},
+			Wind: map[string][]domain.Event{
+				"38d503e5-dc1c-4549-8172-09d9c29070f7": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 12.5},
+				},
+			},
+		},
+		&ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceAll}},
+	)
+	assert.NoError(t, err)
+
+	workbook, err := excelize.OpenReader(actual)
+	assert.NoError(t, err)
+	defer workbook.Close()
+
+	assert.Equal(t, []string{
+		"Summary",
+		"38d503e5-dc1c-4549-8172-09d9c29",
+		"51df2e4c-2002-11ea-95a5-525400b",
+		"38d503e5-dc1c-4549-8172-09d9c~2",
+	}, workbook.GetSheetList())
+
+	summary, err := workbook.GetRows("Summary")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Source", "Parameter Id", "Worksheet", "Count", "Min", "Max", "Mean"},
+		{"solar", "38d503e5-dc1c-4549-8172-09d9c29070f7", "38d503e5-dc1c-4549-8172-09d9c29", "3", "-1.5", "4", "1"},
+		{"solar", "51df2e4c-2002-11ea-95a5-525400b2701a", "51df2e4c-2002-11ea-95a5-525400b", "0"},
+		{"wind", "38d503e5-dc1c-4549-8172-09d9c29070f7", "38d503e5-dc1c-4549-8172-09d9c~2", "1", "12.5", "12.5", "12.5"},
+	}, summary)
+
+	parameterSheet, err := workbook.GetRows("38d503e5-dc1c-4549-8172-09d9c29")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"timestamp", "value"},
+		{"2022-01-01 01:00:00", "-1.5"},
+		{"2022-01-01 02:00:00", "4"},
+		{"2022-01-01 03:00:00", "0.5"},
+	}, parameterSheet)
+
+	emptyParameterSheet, err := workbook.GetRows("51df2e4c-2002-11ea-95a5-525400b")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"timestamp", "value"}}, emptyParameterSheet)
+}
+
+func TestSolarPanelDataXlsxEncoder_EncodeSolarPanelDataWithFill(t *testing.T) {
+	encoder := NewSolarPanelDataXlsxEncoder()
+	actual := &bytes.Buffer{}
+
+	err := encoder.EncodeSolarPanelData(
+		actual,
+		&domain.SolarPanelData{
+			Solar: map[string][]domain.Event{
+				"uuid1": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 4},
+					{Timestamp: time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC), Value: 0.5},
+				},
+			},
+		},
+		&ExportOptions{
+			EventSelection: domain.EventSelection{
+				Source: domain.EventSourceSolar,
+			},
+			Interval:    time.Hour,
+			Aggregation: ExportAggregationMean,
+			Location:    time.UTC,
+			Fill:        ExportFillZero,
+		},
+	)
+	assert.NoError(t, err)
+
+	workbook, err := excelize.OpenReader(actual)
+	assert.NoError(t, err)
+	defer workbook.Close()
+
+	parameterSheet, err := workbook.GetRows("uuid1")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"timestamp", "value", "filled"},
+		{"2022-01-01 01:00:00", "4", "FALSE"},
+		{"2022-01-01 02:00:00", "0", "TRUE"},
+		{"2022-01-01 03:00:00", "0.5", "FALSE"},
+	}, parameterSheet)
+}
+
+func TestSolarPanelDataXlsxEncoder_EncodeSolarPanelDataWithNonAsciiParameterIds(t *testing.T) {
+	encoder := NewSolarPanelDataXlsxEncoder()
+	actual := &bytes.Buffer{}
+
+	err := encoder.EncodeSolarPanelData(
+		actual,
+		&domain.SolarPanelData{
+			Solar: map[string][]domain.Event{
+				"Θερμοκρασία πάνελ νότιας στέγης 1": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 4},
+				},
+				"Θερμοκρασία πάνελ νότιας στέγης 2": []domain.Event{
+					{Timestamp: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Value: 5},
+				},
+			},
+		},
+		&ExportOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}},
+	)
+	assert.NoError(t, err)
+
+	workbook, err := excelize.OpenReader(actual)
+	assert.NoError(t, err)
+	defer workbook.Close()
+
+	assert.Equal(t, []string{
+		"Summary",
+		"Θερμοκρασία πάνελ νότιας στέγης",
+		"Θερμοκρασία πάνελ νότιας στέγ~2",
+	}, workbook.GetSheetList())
+
+	parameterSheet, err := workbook.GetRows("Θερμοκρασία πάνελ νότιας στέγ~2")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"timestamp", "value"},
+		{"2022-01-01 01:00:00", "5"},
+	}, parameterSheet)
+}
+
+func TestSolarPanelDataXlsxEncoder_EncodeError(t *testing.T) {
+	encoder := NewSolarPanelDataXlsxEncoder()
+	actual := &bytes.Buffer{}
+
+	err := encoder.EncodeError(actual, "missing solarPanelData id")
+	assert.NoError(t, err)
+
+	workbook, err := excelize.OpenReader(actual)
+	assert.NoError(t, err)
+	defer workbook.Close()
+
+	message, err := workbook.GetCellValue("Error", "A1")
+	assert.NoError(t, err)
+	assert.Equal(t, "missing solarPanelData id", message)
+}
@@ -0,0 +1,27 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"net/url"
+)
+
+// StatisticsOptions controls which series of a dataset statistics are
+// calculated for.
+type StatisticsOptions struct {
+	domain.EventSelection
+}
+
+// ParseStatisticsOptions reads the statistics options from the query of a
+// request, falling back to every solar event.
+func ParseStatisticsOptions(query url.Values) (*StatisticsOptions, error) {
+	options := &StatisticsOptions{}
+
+	var err error
+
+	options.EventSelection, err = parseEventSelection(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
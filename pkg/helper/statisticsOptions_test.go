@@ -0,0 +1,74 @@
+package helper
+
+import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseStatisticsOptions(t *testing.T) {
+	to := time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		query                string
+		expected             *StatisticsOptions
+		expectError          bool
+		expectedErrorMessage string
+	}{
+		{
+			name:     "valid defaults",
+			query:    "",
+			expected: &StatisticsOptions{EventSelection: domain.EventSelection{Source: domain.EventSourceSolar}},
+		},
+		{
+			name:  "valid source, window and parameters",
+			query: "source=wind&to=2022-01-01T06:00:00Z&parameter=uuid1&parameter=uuid1",
+			expected: &StatisticsOptions{
+				EventSelection: domain.EventSelection{
+					Source:     domain.EventSourceWind,
+					To:         &to,
+					Parameters: []string{"uuid1"},
+				},
+			},
+		},
+		{
+			name:                 "invalid source",
+			query:                "source=hydro",
+			expectError:          true,
+			expectedErrorMessage: "source must be one of solar, wind, all",
+		},
+		{
+			name:                 "invalid from",
+			query:                "from=yesterday",
+			expectError:          true,
+			expectedErrorMessage: "from must be a timestamp in the 20060102T150405Z or RFC 3339 format",
+		},
+		{
+			name:                 "invalid parameters",
+			query:                "parameters=uuid1,",
+			expectError:          true,
+			expectedErrorMessage: "parameters must be a comma separated list of parameter ids",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			assert.NoError(t, err)
+
+			actual, err := ParseStatisticsOptions(query)
+
+			if tt.expectError {
+				assert.EqualError(t, err, tt.expectedErrorMessage)
+				assert.Nil(t, actual)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
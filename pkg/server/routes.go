@@ -1,17 +1,17 @@
 package server
 
 import (
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/ports"
 	"github.com/loukaspe/solar-panel-data-crud/internal/core/services"
 	"github.com/loukaspe/solar-panel-data-crud/internal/handlers"
 	"github.com/loukaspe/solar-panel-data-crud/internal/handlers/solarPanelData"
-	"github.com/loukaspe/solar-panel-data-crud/internal/repositories"
 	"github.com/loukaspe/solar-panel-data-crud/pkg/helper"
 	log "github.com/sirupsen/logrus"
 	"net/http"
 )
 
 func (s *Server) initializeRoutes(
-	db repositories.SolarPanelDataDB,
+	solarPanelDataRepository ports.SolarPanelDataRepositoryInterface,
 	logger *log.Logger,
 ) {
 	// health check
@@ -19,17 +19,72 @@ func (s *Server) initializeRoutes(
 	s.router.HandleFunc("/health-check", healthCheckHandler.HealthCheckController).Methods("GET")
 
 	// solarPanelData
-	solarPanelDataRepository := repositories.NewSolarPanelDataRepository(db)
 	solarPanelDataService := services.NewSolarPanelDataService(solarPanelDataRepository)
 	solarPanelDataEventExtractor := helper.NewSolarPanelDataEventExtractor()
+	solarPanelDataValidator := helper.NewSolarPanelDataValidator()
+
+	solarPanelDataEnergyCalculator := helper.NewSolarPanelDataEnergyCalculator()
+	solarPanelDataStatisticsCalculator := helper.NewSolarPanelDataStatisticsCalculator()
+	solarPanelDataQualityAnalyzer := helper.NewSolarPanelDataQualityAnalyzer()
+	solarPanelDataAnomalyDetector := helper.NewSolarPanelDataAnomalyDetector()
+
+	solarPanelDataEncoders := helper.NewSolarPanelDataEncoders()
+	solarPanelDataEncoders.Register(
+		"csv",
+		helper.MediaTypeCsv,
+		helper.NewSolarPanelDataCsvEncoder(solarPanelDataEventExtractor),
+	)
+	solarPanelDataEncoders.Register("json", helper.MediaTypeJson, helper.NewSolarPanelDataJsonEncoder())
+	solarPanelDataEncoders.Register("ndjson", helper.MediaTypeNdjson, helper.NewSolarPanelDataNdjsonEncoder())
+	solarPanelDataEncoders.Register("parquet", helper.MediaTypeParquet, helper.NewSolarPanelDataParquetEncoder())
+	solarPanelDataEncoders.Register("xlsx", helper.MediaTypeXlsx, helper.NewSolarPanelDataXlsxEncoder())
 
 	getSolarPanelDataHandler := solarPanelData.NewGetSolarPanelDataHandler(
 		solarPanelDataService,
-		solarPanelDataEventExtractor,
+		solarPanelDataEncoders,
+		logger,
+	)
+	streamSolarPanelDataLineProtocolHandler := solarPanelData.NewStreamSolarPanelDataHandler(
+		solarPanelDataService,
+		helper.NewSolarPanelDataInfluxEncoder(),
+		helper.MediaTypeInfluxLineProtocol+"; charset=utf-8",
+		"",
+		logger,
+	)
+	streamSolarPanelDataRemoteWriteHandler := solarPanelData.NewStreamSolarPanelDataHandler(
+		solarPanelDataService,
+		helper.NewSolarPanelDataPrometheusEncoder(),
+		helper.MediaTypePrometheusRemoteWrite,
+		helper.ContentEncodingSnappy,
+		logger,
+	)
+	getSolarPanelDataEnergyHandler := solarPanelData.NewGetSolarPanelDataEnergyHandler(
+		solarPanelDataService,
+		solarPanelDataEnergyCalculator,
+		logger,
+	)
+	getSolarPanelDataStatisticsHandler := solarPanelData.NewGetSolarPanelDataStatisticsHandler(
+		solarPanelDataService,
+		solarPanelDataStatisticsCalculator,
+		logger,
+	)
+	getSolarPanelDataQualityHandler := solarPanelData.NewGetSolarPanelDataQualityHandler(
+		solarPanelDataService,
+		solarPanelDataQualityAnalyzer,
+		logger,
+	)
+	getSolarPanelDataAnomaliesHandler := solarPanelData.NewGetSolarPanelDataAnomaliesHandler(
+		solarPanelDataService,
+		solarPanelDataAnomalyDetector,
+		logger,
+	)
+	listSolarPanelDataHandler := solarPanelData.NewListSolarPanelDataHandler(
+		solarPanelDataService,
 		logger,
 	)
 	createSolarPanelDataHandler := solarPanelData.NewCreateSolarPanelDataHandler(
 		solarPanelDataService,
+		solarPanelDataValidator,
 		logger,
 	)
 	deleteSolarPanelDataHandler := solarPanelData.NewDeleteSolarPanelDataHandler(
@@ -38,6 +93,12 @@ func (s *Server) initializeRoutes(
 	)
 	updateSolarPanelDataHandler := solarPanelData.NewUpdateSolarPanelDataHandler(
 		solarPanelDataService,
+		solarPanelDataValidator,
+		logger,
+	)
+	patchSolarPanelDataHandler := solarPanelData.NewPatchSolarPanelDataHandler(
+		solarPanelDataService,
+		solarPanelDataValidator,
 		logger,
 	)
 
@@ -45,10 +106,38 @@ func (s *Server) initializeRoutes(
 		"/solar-panel-data",
 		createSolarPanelDataHandler.CreateSolarPanelDataController,
 	).Methods(http.MethodPost)
+	s.router.HandleFunc(
+		"/solar-panel-data",
+		listSolarPanelDataHandler.ListSolarPanelDataController,
+	).Methods(http.MethodGet)
 	s.router.HandleFunc(
 		"/solar-panel-data/{id}",
 		getSolarPanelDataHandler.GetSolarPanelDataController,
 	).Methods(http.MethodGet)
+	s.router.HandleFunc(
+		"/solar-panel-data/{id}/line-protocol",
+		streamSolarPanelDataLineProtocolHandler.StreamSolarPanelDataController,
+	).Methods(http.MethodGet)
+	s.router.HandleFunc(
+		"/solar-panel-data/{id}/remote-write",
+		streamSolarPanelDataRemoteWriteHandler.StreamSolarPanelDataController,
+	).Methods(http.MethodGet)
+	s.router.HandleFunc(
+		"/solar-panel-data/{id}/energy",
+		getSolarPanelDataEnergyHandler.GetSolarPanelDataEnergyController,
+	).Methods(http.MethodGet)
+	s.router.HandleFunc(
+		"/solar-panel-data/{id}/stats",
+		getSolarPanelDataStatisticsHandler.GetSolarPanelDataStatisticsController,
+	).Methods(http.MethodGet)
+	s.router.HandleFunc(
+		"/solar-panel-data/{id}/quality",
+		getSolarPanelDataQualityHandler.GetSolarPanelDataQualityController,
+	).Methods(http.MethodGet)
+	s.router.HandleFunc(
+		"/solar-panel-data/{id}/anomalies",
+		getSolarPanelDataAnomaliesHandler.GetSolarPanelDataAnomaliesController,
+	).Methods(http.MethodGet)
 	s.router.HandleFunc(
 		"/solar-panel-data/{id}",
 		deleteSolarPanelDataHandler.DeleteSolarPanelDataController,
@@ -57,4 +146,8 @@ func (s *Server) initializeRoutes(
 		"/solar-panel-data/{id}",
 		updateSolarPanelDataHandler.UpdateSolarPanelDataController,
 	).Methods(http.MethodPut)
+	s.router.HandleFunc(
+		"/solar-panel-data/{id}",
+		patchSolarPanelDataHandler.PatchSolarPanelDataController,
+	).Methods(http.MethodPatch)
 }
@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"github.com/gorilla/mux"
-	"github.com/loukaspe/solar-panel-data-crud/internal/repositories"
+	"github.com/loukaspe/solar-panel-data-crud/internal/core/ports"
 	log "github.com/sirupsen/logrus"
 	"net/http"
 	"os"
@@ -16,26 +16,26 @@ import (
 type Server struct {
 	httpServer *http.Server
 	router     *mux.Router
-	db         repositories.SolarPanelDataDB
+	repository ports.SolarPanelDataRepositoryInterface
 	logger     *log.Logger
 }
 
 func NewServer(
-	db repositories.SolarPanelDataDB,
+	repository ports.SolarPanelDataRepositoryInterface,
 	router *mux.Router,
 	httpServer *http.Server,
 	logger *log.Logger,
 ) *Server {
 	return &Server{
 		router:     router,
-		db:         db,
+		repository: repository,
 		httpServer: httpServer,
 		logger:     logger,
 	}
 }
 
 func (s *Server) Run() {
-	s.initializeRoutes(s.db, s.logger)
+	s.initializeRoutes(s.repository, s.logger)
 
 	go func() {
 		if err := s.httpServer.ListenAndServe(); err != nil &&